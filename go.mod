@@ -3,6 +3,14 @@ module github.com/zawhtetnaing10/Blog-Aggregator
 go 1.23.5
 
 require (
-	github.com/google/uuid v1.6.0 // indirect
-	github.com/lib/pq v1.10.9 // indirect
+	github.com/google/uuid v1.6.0
+	github.com/lib/pq v1.10.9
 )
+
+require (
+	golang.org/x/crypto v0.26.0
+	golang.org/x/net v0.28.0
+	golang.org/x/term v0.23.0
+)
+
+require golang.org/x/sys v0.23.0 // indirect
@@ -0,0 +1,483 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/secrets"
+	"golang.org/x/term"
+)
+
+// Directory in the home directory backups are written to, unless backup_dir is set
+const DEFAULT_BACKUP_DIR = ".gator/backups"
+
+// User Handler
+// user delete <name> [--yes] [--dry-run]
+// user role <name> admin|member
+// user rename <name> <new name>
+// user set-password <name>
+// Only admins can manage users, though users may rename themselves and
+// change their own password.
+func UserHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	dryRun := fs.Bool("dry-run", false, "print what would be deleted without deleting it")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("usage: user delete|role|rename|set-password")
+	}
+
+	switch args[0] {
+	case "delete":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: user delete <name> [--yes] [--dry-run]")
+		}
+		if err := requireAdmin(user); err != nil {
+			return err
+		}
+		return deleteUser(s, user, args[1], *yes, *dryRun)
+	case "role":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: user role <name> admin|member")
+		}
+		if err := requireAdmin(user); err != nil {
+			return err
+		}
+		return setUserRole(s, args[1], args[2])
+	case "rename":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: user rename <name> <new name>")
+		}
+		if args[1] != user.Name {
+			if err := requireAdmin(user); err != nil {
+				return err
+			}
+		}
+		return renameUser(s, args[1], args[2])
+	case "set-password":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: user set-password <name>")
+		}
+		if args[1] != user.Name {
+			if err := requireAdmin(user); err != nil {
+				return err
+			}
+		}
+		return setUserPassword(s, args[1])
+	default:
+		return fmt.Errorf("unknown subcommand %v, expected delete, role, rename or set-password", args[0])
+	}
+}
+
+// Time a bootstrap code printed by gator agg can be used for
+const BOOTSTRAP_CODE_TTL = time.Hour
+
+// Users from before passwords existed can't log in until an admin sets their
+// password, and right after upgrading no admin can log in either. While no
+// admin has a password, user set-password <admin> --bootstrap-code <code>
+// may be run without logging in, using a one-time code printed by gator agg.
+func AllowPasswordBootstrap(handler func(s *State, cmd Command) error) func(s *State, cmd Command) error {
+	return func(s *State, cmd Command) error {
+		fs := newFlagSet(cmd.Name)
+		code := fs.String("bootstrap-code", "", "one-time code printed by gator agg")
+		args, err := parseFlags(fs, cmd.Arguments)
+		if err != nil || *code == "" {
+			return handler(s, cmd)
+		}
+		if len(args) != 2 || args[0] != "set-password" {
+			return fmt.Errorf("usage: user set-password <admin> --bootstrap-code <code>")
+		}
+
+		withPassword, err := s.Db.CountAdminsWithPassword(context.Background())
+		if err != nil {
+			return fmt.Errorf("error counting admins: %w", err)
+		}
+		if withPassword > 0 {
+			return fmt.Errorf("an admin already has a password, log in as them to set passwords")
+		}
+
+		target, err := s.Db.GetUser(context.Background(), args[1])
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user %v not found", args[1])
+		}
+		if err != nil {
+			return fmt.Errorf("error fetching user: %w", err)
+		}
+		if target.Role != constants.ROLE_ADMIN {
+			return fmt.Errorf("no admin has a password yet, set one for an admin first")
+		}
+
+		used, err := s.Db.UseBootstrapCode(context.Background(), database.UseBootstrapCodeParams{
+			CodeHash:  secrets.HashToken(*code),
+			ExpiresAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("error checking bootstrap code: %w", err)
+		}
+		if used == 0 {
+			return fmt.Errorf("invalid or expired bootstrap code, restart gator agg for a new one")
+		}
+		return setUserPassword(s, target.Name)
+	}
+}
+
+// Print a one-time code for setting the first admin password when admins
+// exist but none of them can log in. Only its hash is stored and earlier
+// codes are replaced.
+func offerPasswordBootstrap(s *State) error {
+	admins, err := s.Db.CountAdmins(context.Background())
+	if err != nil {
+		return fmt.Errorf("error counting admins: %w", err)
+	}
+	withPassword, err := s.Db.CountAdminsWithPassword(context.Background())
+	if err != nil {
+		return fmt.Errorf("error counting admins: %w", err)
+	}
+	if admins == 0 || withPassword > 0 {
+		return nil
+	}
+
+	code, err := secrets.NewToken()
+	if err != nil {
+		return err
+	}
+	if err := s.Db.DeleteBootstrapCodes(context.Background()); err != nil {
+		return fmt.Errorf("error removing old bootstrap codes: %w", err)
+	}
+	now := time.Now()
+	if err := s.Db.CreateBootstrapCode(context.Background(), database.CreateBootstrapCodeParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		ExpiresAt: now.Add(BOOTSTRAP_CODE_TTL),
+		CodeHash:  secrets.HashToken(code),
+	}); err != nil {
+		return fmt.Errorf("error saving bootstrap code: %w", err)
+	}
+
+	fmt.Println("No admin has a password yet. Within the next hour, set one with")
+	fmt.Printf("  gator user set-password <admin> --bootstrap-code %v\n", code)
+	return nil
+}
+
+// Set a user's password and log out their sessions
+func setUserPassword(s *State, name string) error {
+	target, err := s.Db.GetUser(context.Background(), name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %v not found", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching user: %w", err)
+	}
+
+	fmt.Printf("New password for %v\n", target.Name)
+	password, err := promptNewPassword()
+	if err != nil {
+		return err
+	}
+	passwordHash, err := secrets.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := s.Db.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+		PasswordHash: sql.NullString{String: passwordHash, Valid: true},
+		UpdatedAt:    time.Now(),
+		ID:           target.ID,
+	}); err != nil {
+		return fmt.Errorf("error saving password: %w", err)
+	}
+
+	sessions, err := s.Db.DeleteSessionsForUser(context.Background(), target.ID)
+	if err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	fmt.Printf("Password of %v changed, %v session(s) logged out\n", target.Name, sessions)
+	if s.Config.CurrentUsername == target.Name && s.Config.SessionToken != "" {
+		s.Config.SessionToken = ""
+		if err := s.SaveConfig(); err != nil {
+			return fmt.Errorf("error saving config %w", err)
+		}
+		fmt.Printf("Log in again with gator login %v\n", target.Name)
+	}
+	return nil
+}
+
+// Delete a user and everything that belongs to them. Feeds they added stay for their followers.
+func deleteUser(s *State, admin database.User, name string, yes bool, dryRun bool) error {
+	target, err := s.Db.GetUser(context.Background(), name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %v not found", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching user: %w", err)
+	}
+
+	if err := checkNotLastAdmin(s, target); err != nil {
+		return err
+	}
+
+	counts, err := s.Db.GetUserRowCounts(context.Background(), target.ID)
+	if err != nil {
+		return fmt.Errorf("error counting rows: %w", err)
+	}
+	fmt.Printf("Deleting %v removes:\n", target.Name)
+	printRowCounts([]rowCount{
+		{"users", 1},
+		{"sessions", counts.Sessions},
+		{"api_tokens", counts.ApiTokens},
+		{"feed_follows", counts.FeedFollows},
+		{"folders", counts.Folders},
+		{"post_reads", counts.PostReads},
+		{"saved_posts", counts.SavedPosts},
+		{"saved_searches", counts.SavedSearches},
+		{"mute_filters", counts.MuteFilters},
+		{"shares", counts.Shares},
+		{"digest_subscriptions", counts.DigestSubscriptions},
+		{"digests", counts.Digests},
+	})
+	if counts.Feeds > 0 {
+		fmt.Printf("The %v feeds they added are kept without an owner\n", counts.Feeds)
+	}
+	if dryRun {
+		return nil
+	}
+
+	if err := confirmAction(fmt.Sprintf("Delete user %v?", target.Name), yes); err != nil {
+		return err
+	}
+	if err := backupDatabase(s, "user-delete-"+target.Name); err != nil {
+		return err
+	}
+
+	if err := s.Db.DeleteUser(context.Background(), target.ID); err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	fmt.Printf("Deleted user %v\n", target.Name)
+
+	// Their sessions went with them, forget the one in the config file like logout does
+	if s.Config.CurrentUsername == target.Name {
+		s.Config.SessionToken = ""
+		s.Config.CurrentUsername = ""
+		if err := s.SaveConfig(); err != nil {
+			return fmt.Errorf("error saving config %w", err)
+		}
+	}
+	if target.ID == admin.ID {
+		fmt.Println("You deleted yourself and have been logged out")
+	}
+	return nil
+}
+
+// Change a user's name. Everything refers to users by id, so only the name changes.
+func renameUser(s *State, name string, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return fmt.Errorf("the new name can't be empty")
+	}
+
+	target, err := s.Db.GetUser(context.Background(), name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %v not found", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching user: %w", err)
+	}
+
+	err = s.Db.RenameUser(context.Background(), database.RenameUserParams{
+		Name:      newName,
+		UpdatedAt: time.Now(),
+		ID:        target.ID,
+	})
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == constants.ERR_CODE_UNIQUE_CONSTRAINT_VIOLATION {
+		return fmt.Errorf("user %v already exists", newName)
+	}
+	if err != nil {
+		return fmt.Errorf("error renaming user: %w", err)
+	}
+
+	// Keep showing the right name for the current user
+	if s.Config.CurrentUsername == target.Name {
+		s.Config.CurrentUsername = newName
+		if err := s.SaveConfig(); err != nil {
+			return fmt.Errorf("error saving config %w", err)
+		}
+	}
+
+	fmt.Printf("Renamed %v to %v\n", target.Name, newName)
+	return nil
+}
+
+// Make a user an admin or a member
+func setUserRole(s *State, name string, role string) error {
+	if role != constants.ROLE_ADMIN && role != constants.ROLE_MEMBER {
+		return fmt.Errorf("unknown role %v, expected admin or member", role)
+	}
+
+	target, err := s.Db.GetUser(context.Background(), name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %v not found", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching user: %w", err)
+	}
+
+	if role == constants.ROLE_MEMBER {
+		if err := checkNotLastAdmin(s, target); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Db.SetUserRole(context.Background(), database.SetUserRoleParams{
+		Role:      role,
+		UpdatedAt: time.Now(),
+		ID:        target.ID,
+	}); err != nil {
+		return fmt.Errorf("error setting role: %w", err)
+	}
+
+	fmt.Printf("%v is now %v\n", target.Name, role)
+	return nil
+}
+
+// Only admins may run destructive commands
+func requireAdmin(user database.User) error {
+	if user.Role != constants.ROLE_ADMIN {
+		return fmt.Errorf("only admins can do this, %v is a %v", user.Name, user.Role)
+	}
+	return nil
+}
+
+// Refuse to remove the last admin, which would leave nobody able to manage users
+func checkNotLastAdmin(s *State, user database.User) error {
+	if user.Role != constants.ROLE_ADMIN {
+		return nil
+	}
+	admins, err := s.Db.CountAdmins(context.Background())
+	if err != nil {
+		return fmt.Errorf("error counting admins: %w", err)
+	}
+	if admins <= 1 {
+		return fmt.Errorf("%v is the only admin, make someone else an admin first", user.Name)
+	}
+	return nil
+}
+
+// Number of rows of a table an operation affects
+type rowCount struct {
+	Table string
+	Count int64
+}
+
+// Print row counts per table
+func printRowCounts(counts []rowCount) {
+	for _, count := range counts {
+		fmt.Printf("  * %v: %v\n", count.Table, count.Count)
+	}
+}
+
+// Ask before a destructive operation, unless --yes was given. Without a
+// terminal there is nobody to ask, so --yes is required.
+func confirmAction(question string, yes bool) error {
+	if yes {
+		return nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return fmt.Errorf("not running in a terminal, pass --yes to confirm")
+	}
+
+	fmt.Printf("%v [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("error reading answer: %w", err)
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("cancelled")
+	}
+}
+
+// Write every table as json to the backup directory before a destructive operation
+func backupDatabase(s *State, operation string) error {
+	dir := s.Config.BackupDir
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("error getting home directory %w", err)
+		}
+		dir = filepath.Join(homeDir, DEFAULT_BACKUP_DIR)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("error creating backup directory: %w", err)
+	}
+
+	snapshot, err := s.Db.GetDatabaseSnapshot(context.Background())
+	if err != nil {
+		return fmt.Errorf("error reading database for backup: %w", err)
+	}
+
+	// The snapshot holds password and token hashes, keep it private
+	name := fmt.Sprintf("gator-%v-%v.json", time.Now().Format("20060102-150405"), safeFileName(operation))
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(snapshot), 0600); err != nil {
+		return fmt.Errorf("error writing backup: %w", err)
+	}
+
+	fmt.Printf("Backed up the database to %v\n", path)
+	return nil
+}
+
+// Replace characters that don't belong in file names
+func safeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// Whoami Handler
+// Prints the current user with their follows, unread posts and last activity
+func WhoamiHandler(s *State, cmd Command, user database.User) error {
+	feedFollows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting feed follows: %w", err)
+	}
+	var unread int64
+	for _, feedFollow := range feedFollows {
+		unread += feedFollow.UnreadCount
+	}
+
+	lastActivity, err := s.Db.GetUserLastActivity(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching last activity: %w", err)
+	}
+
+	fmt.Printf("%v (%v)\n", user.Name, user.Role)
+	fmt.Printf("  * created %v\n", user.CreatedAt.Format(time.DateOnly))
+	fmt.Printf("  * following %v feeds\n", len(feedFollows))
+	fmt.Printf("  * %v unread posts\n", unread)
+	fmt.Printf("  * last active %v\n", relativeTime(lastActivity, time.Now()))
+	return nil
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/secrets"
+)
+
+func TestDeletingYourselfClearsTheSession(t *testing.T) {
+	s := newTestState(t)
+	t.Setenv("HOME", t.TempDir())
+	s.Config.BackupDir = t.TempDir()
+	admin := makeTestAdmin(t, s, createTestUser(t, s, "admin"))
+	makeTestAdmin(t, s, createTestUser(t, s, "other"))
+	if err := startSession(s, admin); err != nil {
+		t.Fatalf("error starting session: %v", err)
+	}
+
+	_, err := captureStdout(t, func() error {
+		return MiddlewareLoggedIn(UserHandler)(s, Command{Name: "user", Arguments: []string{"delete", admin.Name, "--yes"}})
+	})
+	if err != nil {
+		t.Fatalf("user delete: %v", err)
+	}
+
+	saved, err := Read()
+	if err != nil {
+		t.Fatalf("error reading config: %v", err)
+	}
+	if saved.SessionToken != "" || saved.CurrentUsername != "" {
+		t.Errorf("config still holds %v's session %q after deleting them", saved.CurrentUsername, saved.SessionToken)
+	}
+}
+
+func TestLastActivityCountsApiTokenUse(t *testing.T) {
+	s := newTestState(t)
+	ctx := context.Background()
+	created := time.Now().Add(-7 * 24 * time.Hour)
+	user, err := s.Db.CreateUser(ctx, database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: created,
+		UpdatedAt: created,
+		Name:      "scripted",
+		Role:      constants.ROLE_MEMBER,
+	})
+	if err != nil {
+		t.Fatalf("error creating user: %v", err)
+	}
+
+	// Compare against the stored creation time, as the column has no zone
+	createdActivity, err := s.Db.GetUserLastActivity(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("error fetching last activity: %v", err)
+	}
+
+	// A user who only reads through a script is still active
+	token := createTestToken(t, s, user, "cron", constants.TOKEN_SCOPE_READ_ONLY)
+	apiToken, err := s.Db.GetApiTokenByHash(ctx, secrets.HashToken(token))
+	if err != nil {
+		t.Fatalf("error fetching token: %v", err)
+	}
+	used := time.Now().Add(-time.Hour)
+	if err := s.Db.TouchApiToken(ctx, database.TouchApiTokenParams{
+		LastUsedAt: sql.NullTime{Time: used, Valid: true},
+		ID:         apiToken.ID,
+	}); err != nil {
+		t.Fatalf("error touching token: %v", err)
+	}
+
+	lastActivity, err := s.Db.GetUserLastActivity(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("error fetching last activity: %v", err)
+	}
+	if got, want := lastActivity.Sub(createdActivity), used.Sub(created); (got - want).Abs() > time.Second {
+		t.Errorf("last activity after using a token is %v after creation, want %v", got, want)
+	}
+}
+
+func TestOnlyAdminsResetAndManageUsers(t *testing.T) {
+	s := newTestState(t)
+	s.Config.BackupDir = t.TempDir()
+	member := createTestUser(t, s, "member")
+	admin := makeTestAdmin(t, s, createTestUser(t, s, "admin"))
+	createTestFeed(t, s, member, "Go Blog", "https://go.example.com/feed", "Go post one")
+
+	for _, args := range [][]string{
+		{"delete", "admin", "--yes"},
+		{"role", "member", "admin"},
+		{"rename", "admin", "root"},
+		{"set-password", "admin"},
+	} {
+		if _, err := captureStdout(t, func() error {
+			return UserHandler(s, Command{Name: "user", Arguments: args}, member)
+		}); err == nil || !strings.Contains(err.Error(), "only admins") {
+			t.Errorf("member ran user %v: %v", strings.Join(args, " "), err)
+		}
+	}
+	if _, err := captureStdout(t, func() error {
+		return ResetHandler(s, Command{Name: "reset", Arguments: []string{"--yes"}}, member)
+	}); err == nil {
+		t.Error("a member reset the database")
+	}
+
+	// The last admin can neither be demoted nor deleted
+	for _, args := range [][]string{
+		{"role", "admin", "member"},
+		{"delete", "admin", "--yes"},
+	} {
+		if _, err := captureStdout(t, func() error {
+			return UserHandler(s, Command{Name: "user", Arguments: args}, admin)
+		}); err == nil || !strings.Contains(err.Error(), "only admin") {
+			t.Errorf("user %v on the last admin: %v", strings.Join(args, " "), err)
+		}
+	}
+
+	// A dry run only counts
+	runAs(t, s, admin, ResetHandler, "reset", "--dry-run")
+	counts, err := s.Db.GetTableCounts(context.Background())
+	if err != nil {
+		t.Fatalf("error counting rows: %v", err)
+	}
+	if counts.Users != 2 || counts.Feeds != 1 || counts.Posts != 1 {
+		t.Errorf("reset --dry-run changed the database: %+v", counts)
+	}
+
+	runAs(t, s, admin, ResetHandler, "reset", "--yes")
+	counts, err = s.Db.GetTableCounts(context.Background())
+	if err != nil {
+		t.Fatalf("error counting rows: %v", err)
+	}
+	if counts.Users != 0 || counts.Feeds != 0 || counts.Posts != 0 || counts.FeedFollows != 0 {
+		t.Errorf("reset left rows behind: %+v", counts)
+	}
+	backups, err := os.ReadDir(s.Config.BackupDir)
+	if err != nil || len(backups) != 1 {
+		t.Errorf("reset should write one backup, found %v: %v", len(backups), err)
+	}
+}
+
+func TestDeletingAUserRemovesTheirData(t *testing.T) {
+	s := newTestState(t)
+	s.Config.BackupDir = t.TempDir()
+	admin := makeTestAdmin(t, s, createTestUser(t, s, "admin"))
+	bob := createTestUser(t, s, "bob")
+	feed := createTestFeed(t, s, admin, "Go Blog", "https://go.example.com/feed", "Go post one")
+	runAs(t, s, bob, FollowHandler, "follow", feed.Url)
+	runAs(t, s, bob, FolderHandler, "folder", "add", "Tech")
+	runAs(t, s, bob, FilterHandler, "filter", "add", "keyword", "crypto")
+	createTestToken(t, s, bob, "script", constants.TOKEN_SCOPE_READ_ONLY)
+
+	output := runAs(t, s, admin, UserHandler, "user", "delete", "bob", "--dry-run")
+	if !strings.Contains(output, "feed_follows") {
+		t.Errorf("user delete --dry-run didn't print the counts:\n%v", output)
+	}
+	if _, err := s.Db.GetUser(context.Background(), "bob"); err != nil {
+		t.Fatalf("user delete --dry-run deleted bob: %v", err)
+	}
+
+	runAs(t, s, admin, UserHandler, "user", "delete", "bob", "--yes")
+	counts, err := s.Db.GetUserRowCounts(context.Background(), bob.ID)
+	if err != nil {
+		t.Fatalf("error counting rows: %v", err)
+	}
+	if counts.FeedFollows != 0 || counts.Folders != 0 || counts.MuteFilters != 0 || counts.ApiTokens != 0 {
+		t.Errorf("rows of the deleted user remain: %+v", counts)
+	}
+	if _, err := s.Db.GetUser(context.Background(), "bob"); err == nil {
+		t.Error("bob still exists")
+	}
+}
+
+func TestRenameKeepsTheUsersData(t *testing.T) {
+	s := newTestState(t)
+	t.Setenv("HOME", t.TempDir())
+	alice := createTestUser(t, s, "alice")
+	createTestUser(t, s, "bob")
+	feed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed", "Go post one")
+	runAs(t, s, alice, FollowHandler, "follow", feed.Url)
+	if err := startSession(s, alice); err != nil {
+		t.Fatalf("error starting session: %v", err)
+	}
+
+	if _, err := captureStdout(t, func() error {
+		return UserHandler(s, Command{Name: "user", Arguments: []string{"rename", "bob", "robert"}}, alice)
+	}); err == nil {
+		t.Error("a member renamed another user")
+	}
+	if _, err := captureStdout(t, func() error {
+		return UserHandler(s, Command{Name: "user", Arguments: []string{"rename", "alice", "bob"}}, alice)
+	}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("renaming to a taken name returned %v", err)
+	}
+
+	// Surrounding spaces are dropped
+	runAs(t, s, alice, UserHandler, "user", "rename", "alice", " alicia ")
+	if s.Config.CurrentUsername != "alicia" {
+		t.Errorf("config names %q after renaming the current user", s.Config.CurrentUsername)
+	}
+	renamed, err := s.Db.GetUser(context.Background(), "alicia")
+	if err != nil || renamed.ID != alice.ID {
+		t.Fatalf("renamed user = %+v, %v, want alice's id", renamed, err)
+	}
+
+	// Follows refer to the id, so nothing is lost
+	output := runAs(t, s, renamed, WhoamiHandler, "whoami")
+	for _, line := range []string{"alicia (member)", "following 1 feeds", "1 unread posts"} {
+		if !strings.Contains(output, line) {
+			t.Errorf("whoami should contain %q, got:\n%v", line, output)
+		}
+	}
+}
+
+func TestRenameRejectsEmptyNames(t *testing.T) {
+	// Checked before the database is consulted
+	for _, name := range []string{"", "   ", "\t\n"} {
+		if err := renameUser(&State{}, "alice", name); err == nil || !strings.Contains(err.Error(), "empty") {
+			t.Errorf("renaming to %q returned %v", name, err)
+		}
+	}
+}
+
+// Backups must hold every table the migrations create
+func TestSnapshotQueryCoversEveryTable(t *testing.T) {
+	queries, err := os.ReadFile("../../sql/queries/admin.sql")
+	if err != nil {
+		t.Fatalf("error reading admin queries: %v", err)
+	}
+	_, snapshot, _ := strings.Cut(string(queries), "-- name: GetDatabaseSnapshot")
+	snapshot, _, _ = strings.Cut(snapshot, "-- name:")
+
+	migrations, err := filepath.Glob(filepath.Join(TEST_SCHEMA_DIR, "*.sql"))
+	if err != nil || len(migrations) == 0 {
+		t.Fatalf("no migrations found in %v: %v", TEST_SCHEMA_DIR, err)
+	}
+	createTable := regexp.MustCompile(`(?m)^CREATE TABLE (\w+)`)
+	for _, migration := range migrations {
+		content, err := os.ReadFile(migration)
+		if err != nil {
+			t.Fatalf("error reading migration: %v", err)
+		}
+		up, _, _ := strings.Cut(string(content), "-- +goose Down")
+		for _, match := range createTable.FindAllStringSubmatch(up, -1) {
+			if !strings.Contains(snapshot, "'"+match[1]+"', (SELECT COALESCE(json_agg(t), '[]') FROM "+match[1]+" t)") {
+				t.Errorf("GetDatabaseSnapshot leaves out %v from %v", match[1], filepath.Base(migration))
+			}
+		}
+	}
+}
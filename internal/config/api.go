@@ -0,0 +1,612 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Page size of post listings and the largest page the api returns
+const DEFAULT_API_PAGE_SIZE = 20
+const MAX_API_LIMIT = 200
+
+// An endpoint of the json api. The route table drives both the mux and the OpenAPI description.
+type apiRoute struct {
+	Method string
+	// ServeMux pattern path, which uses the OpenAPI {param} syntax
+	Path    string
+	Summary string
+	Query   []apiParam
+	// Samples of the request and response bodies, nil when there is none
+	Body     any
+	Response any
+	Status   int
+	Handle   func(s *State, r *http.Request) (any, error)
+}
+
+// A query parameter of an endpoint
+type apiParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// An error with the status code it is reported with
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e apiError) Error() string {
+	return e.Message
+}
+
+// Body of error responses
+type apiErrorBody struct {
+	Error string `json:"error"`
+}
+
+type apiUserView struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type apiFeedView struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	Url           string     `json:"url"`
+	Owner         string     `json:"owner,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at,omitempty"`
+	RobotsBlocked bool       `json:"robots_blocked"`
+}
+
+type apiFollowView struct {
+	FeedID      uuid.UUID `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+	Folder      string    `json:"folder,omitempty"`
+	UnreadCount int64     `json:"unread_count"`
+	Priority    int32     `json:"priority"`
+	Notify      bool      `json:"notify"`
+	Match       string    `json:"match,omitempty"`
+	FollowedAt  time.Time `json:"followed_at"`
+}
+
+type apiFollowRequest struct {
+	Url string `json:"url"`
+}
+
+type apiPostView struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Url         string    `json:"url"`
+	FeedID      uuid.UUID `json:"feed_id"`
+	FeedName    string    `json:"feed_name"`
+	Author      string    `json:"author,omitempty"`
+	Categories  []string  `json:"categories"`
+	Description string    `json:"description"`
+	PublishedAt time.Time `json:"published_at"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+type apiPostPage struct {
+	Posts []apiPostView `json:"posts"`
+	// Pass as after to get the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type apiSearchResultView struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Url         string    `json:"url"`
+	FeedName    string    `json:"feed_name"`
+	PublishedAt time.Time `json:"published_at"`
+	Rank        float32   `json:"rank"`
+	Snippet     string    `json:"snippet"`
+}
+
+// The endpoints of the json api
+func apiRoutes() []apiRoute {
+	return []apiRoute{
+		{Method: "GET", Path: "/api/users", Summary: "List the users", Response: []apiUserView{}, Handle: apiListUsers},
+		{Method: "GET", Path: "/api/users/{name}", Summary: "Get a user", Response: apiUserView{}, Handle: apiGetUser},
+		{Method: "GET", Path: "/api/feeds", Summary: "List the feeds", Response: []apiFeedView{}, Handle: apiListFeeds},
+		{Method: "GET", Path: "/api/users/{name}/follows", Summary: "List the feeds a user follows", Response: []apiFollowView{}, Handle: apiListFollows},
+		{Method: "POST", Path: "/api/users/{name}/follows", Summary: "Follow a feed by url", Body: apiFollowRequest{}, Response: apiFollowView{}, Status: http.StatusCreated, Handle: apiFollow},
+		{Method: "DELETE", Path: "/api/users/{name}/follows/{feed_id}", Summary: "Unfollow a feed", Status: http.StatusNoContent, Handle: apiUnfollow},
+		{
+			Method:  "GET",
+			Path:    "/api/users/{name}/posts",
+			Summary: "List the posts of the followed feeds, newest first",
+			Query: []apiParam{
+				{Name: "all", Type: "boolean", Description: "include read posts"},
+				{Name: "since_follow", Type: "boolean", Description: "hide posts published before the feed was followed"},
+				{Name: "feed", Type: "string", Description: "url or name of a followed feed"},
+				{Name: "folder", Type: "string", Description: "folder name, including its subfolders"},
+				{Name: "since", Type: "string", Description: "only posts published on or after this date"},
+				{Name: "until", Type: "string", Description: "only posts published before this date"},
+				{Name: "author", Type: "string", Description: "only posts whose author contains this text"},
+				{Name: "category", Type: "string", Description: "only posts in this category"},
+				{Name: "sort", Type: "string", Description: "published, fetched or priority"},
+				{Name: "after", Type: "string", Description: "next_cursor of the previous page"},
+				{Name: "limit", Type: "integer", Description: "page size, 20 by default"},
+			},
+			Response: apiPostPage{},
+			Handle:   apiListPosts,
+		},
+		{Method: "PUT", Path: "/api/users/{name}/posts/{post_id}/read", Summary: "Mark a post as read", Status: http.StatusNoContent, Handle: apiMarkRead},
+		{Method: "DELETE", Path: "/api/users/{name}/posts/{post_id}/read", Summary: "Mark a post as unread", Status: http.StatusNoContent, Handle: apiMarkUnread},
+		{
+			Method:  "GET",
+			Path:    "/api/users/{name}/search",
+			Summary: "Search the stored posts, best matches first",
+			Query: []apiParam{
+				{Name: "q", Type: "string", Description: "search query, supports \"phrases\", or and -word"},
+				{Name: "following", Type: "boolean", Description: "only search the followed feeds"},
+				{Name: "feed", Type: "string", Description: "url or followed name of a feed to search"},
+				{Name: "limit", Type: "integer", Description: "number of results, 10 by default"},
+			},
+			Response: []apiSearchResultView{},
+			Handle:   apiSearch,
+		},
+	}
+}
+
+// Register the api routes and the OpenAPI description on the mux
+func registerAPIRoutes(s *State, mux *http.ServeMux) {
+	routes := apiRoutes()
+	for _, route := range routes {
+		mux.HandleFunc(route.Method+" "+route.Path, func(w http.ResponseWriter, r *http.Request) {
+			serveAPIRoute(s, route, w, r)
+		})
+	}
+
+	description := openAPIDescription(routes)
+	mux.HandleFunc("GET /api/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, description)
+	})
+}
+
+// Run a route handler and write its result or error as json
+func serveAPIRoute(s *State, route apiRoute, w http.ResponseWriter, r *http.Request) {
+	var result any
+	err := checkAPIRequest(route, r)
+	if err == nil {
+		result, err = route.Handle(s, r)
+	}
+	if err != nil {
+		var apiErr apiError
+		switch {
+		case errors.As(err, &apiErr):
+		case errors.Is(err, sql.ErrNoRows):
+			apiErr = apiError{Status: http.StatusNotFound, Message: "not found"}
+		default:
+			fmt.Printf("Error serving %v %v: %v\n", r.Method, r.URL.Path, err)
+			apiErr = apiError{Status: http.StatusInternalServerError, Message: "internal error"}
+		}
+		writeJSON(w, apiErr.Status, apiErrorBody{Error: apiErr.Message})
+		return
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+	writeJSON(w, status, result)
+}
+
+// Browsers attach cached basic auth credentials to cross-site requests, so
+// requests changing data must not come from another origin, and bodies
+// must be labelled as json, which html forms can't do
+func checkAPIRequest(route apiRoute, r *http.Request) error {
+	if route.Method == http.MethodGet || route.Method == http.MethodHead {
+		return nil
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		parsed, err := url.Parse(origin)
+		if err != nil || parsed.Host != r.Host {
+			return apiError{Status: http.StatusForbidden, Message: "cross-origin requests are not allowed"}
+		}
+	}
+	if route.Body != nil {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			return apiError{Status: http.StatusUnsupportedMediaType, Message: "the request body must be application/json"}
+		}
+	}
+	return nil
+}
+
+// Write a json response
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
+
+// Report an invalid request
+func badRequest(err error) error {
+	return apiError{Status: http.StatusBadRequest, Message: err.Error()}
+}
+
+// Report a missing resource
+func notFound(format string, args ...any) error {
+	return apiError{Status: http.StatusNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Get the user a request acts as. With an api token that is the token's
+// user, and the path must name them. Only servers started with --insecure
+// let requests without a token through, which act as the user in the path.
+func apiPathUser(s *State, r *http.Request) (database.User, error) {
+	name := r.PathValue("name")
+	if tokenUser, ok := requestTokenUser(r); ok {
+		if tokenUser.Name != name {
+			return database.User{}, apiError{Status: http.StatusForbidden, Message: fmt.Sprintf("the api token can't access %v", name)}
+		}
+		return tokenUser, nil
+	}
+
+	user, err := s.Db.GetUser(r.Context(), name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, notFound("no user named %v", name)
+	}
+	if err != nil {
+		return database.User{}, fmt.Errorf("error fetching user: %w", err)
+	}
+	return user, nil
+}
+
+// Read an optional integer query parameter
+func apiQueryInt(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		return 0, badRequest(fmt.Errorf("%v must be a positive integer", name))
+	}
+	return min(parsed, MAX_API_LIMIT), nil
+}
+
+// Read an optional boolean query parameter
+func apiQueryBool(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, badRequest(fmt.Errorf("%v must be true or false", name))
+	}
+	return parsed, nil
+}
+
+func newAPIUserView(user database.User) apiUserView {
+	return apiUserView{ID: user.ID, Name: user.Name, CreatedAt: user.CreatedAt}
+}
+
+// GET /api/users
+func apiListUsers(s *State, r *http.Request) (any, error) {
+	users, err := s.Db.GetUsers(r.Context())
+	if err != nil {
+		return nil, fmt.Errorf("error fetching users: %w", err)
+	}
+
+	views := []apiUserView{}
+	for _, user := range users {
+		views = append(views, newAPIUserView(user))
+	}
+	return views, nil
+}
+
+// GET /api/users/{name}
+func apiGetUser(s *State, r *http.Request) (any, error) {
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIUserView(user), nil
+}
+
+// GET /api/feeds
+func apiListFeeds(s *State, r *http.Request) (any, error) {
+	feeds, err := s.Db.GetFeedsWithUsername(r.Context())
+	if err != nil {
+		return nil, fmt.Errorf("error fetching feeds: %w", err)
+	}
+
+	views := []apiFeedView{}
+	for _, feed := range feeds {
+		view := apiFeedView{
+			ID:            feed.ID,
+			Name:          feed.Name,
+			Url:           feed.Url,
+			Owner:         feed.Username.String,
+			CreatedAt:     feed.CreatedAt,
+			RobotsBlocked: feed.RobotsBlocked,
+		}
+		if feed.LastFetchedAt.Valid {
+			view.LastFetchedAt = &feed.LastFetchedAt.Time
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// Get the followed feeds of a user as api views
+func apiFollowViews(ctx context.Context, s *State, user database.User) ([]apiFollowView, error) {
+	feedFollows, err := s.Db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching feed follows: %w", err)
+	}
+	folders, err := s.Db.GetFoldersForUser(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching folders: %w", err)
+	}
+	folderNames := map[uuid.UUID]string{}
+	for _, folder := range folders {
+		folderNames[folder.ID] = folder.Name
+	}
+
+	views := []apiFollowView{}
+	for _, feedFollow := range feedFollows {
+		views = append(views, apiFollowView{
+			FeedID:      feedFollow.FeedID,
+			FeedName:    feedFollow.FeedName,
+			Folder:      folderNames[feedFollow.FolderID.UUID],
+			UnreadCount: feedFollow.UnreadCount,
+			Priority:    feedFollow.Priority,
+			Notify:      feedFollow.Notify,
+			Match:       feedFollow.MatchFilter.String,
+			FollowedAt:  feedFollow.CreatedAt,
+		})
+	}
+	return views, nil
+}
+
+// GET /api/users/{name}/follows
+func apiListFollows(s *State, r *http.Request) (any, error) {
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		return nil, err
+	}
+	return apiFollowViews(r.Context(), s, user)
+}
+
+// POST /api/users/{name}/follows
+func apiFollow(s *State, r *http.Request) (any, error) {
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var request apiFollowRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, badRequest(fmt.Errorf("invalid request body: %w", err))
+	}
+	feed, err := s.Db.GetFeedByUrl(r.Context(), request.Url)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound("no feed with url %v", request.Url)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching feed: %w", err)
+	}
+
+	params := database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	}
+	if _, err := s.Db.CreateFeedFollow(r.Context(), params); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == constants.ERR_CODE_UNIQUE_CONSTRAINT_VIOLATION {
+			return nil, apiError{Status: http.StatusConflict, Message: "already following " + feed.Url}
+		}
+		return nil, fmt.Errorf("error creating feed follow: %w", err)
+	}
+
+	views, err := apiFollowViews(r.Context(), s, user)
+	if err != nil {
+		return nil, err
+	}
+	for _, view := range views {
+		if view.FeedID == feed.ID {
+			return view, nil
+		}
+	}
+	return nil, fmt.Errorf("followed feed %v missing", feed.ID)
+}
+
+// DELETE /api/users/{name}/follows/{feed_id}
+func apiUnfollow(s *State, r *http.Request) (any, error) {
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		return nil, err
+	}
+	feedID, err := uuid.Parse(r.PathValue("feed_id"))
+	if err != nil {
+		return nil, badRequest(fmt.Errorf("invalid feed id: %w", err))
+	}
+
+	params := database.DeleteFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feedID,
+	}
+	result, err := s.Db.DeleteFeedFollow(r.Context(), params)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting feed follow: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("error deleting feed follow: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, notFound("not following feed %v", feedID)
+	}
+	return nil, nil
+}
+
+// GET /api/users/{name}/posts
+func apiListPosts(s *State, r *http.Request) (any, error) {
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		return nil, err
+	}
+
+	values := r.URL.Query()
+	query := postQuery{
+		Feed:     values.Get("feed"),
+		Folder:   values.Get("folder"),
+		Since:    values.Get("since"),
+		Until:    values.Get("until"),
+		Author:   values.Get("author"),
+		Category: values.Get("category"),
+		Sort:     values.Get("sort"),
+		After:    values.Get("after"),
+	}
+	if query.All, err = apiQueryBool(r, "all"); err != nil {
+		return nil, err
+	}
+	if query.SinceFollow, err = apiQueryBool(r, "since_follow"); err != nil {
+		return nil, err
+	}
+	if query.Limit, err = apiQueryInt(r, "limit", DEFAULT_API_PAGE_SIZE); err != nil {
+		return nil, err
+	}
+
+	params, err := query.params(s, user)
+	var invalid inputError
+	if errors.As(err, &invalid) {
+		return nil, badRequest(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.Db.GetPostsForUser(r.Context(), params)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching posts: %w", err)
+	}
+
+	page := apiPostPage{Posts: []apiPostView{}, NextCursor: nextPostCursor(params, posts)}
+	for _, post := range posts {
+		page.Posts = append(page.Posts, apiPostView{
+			ID:          post.ID,
+			Title:       post.Title,
+			Url:         post.Url,
+			FeedID:      post.FeedID,
+			FeedName:    post.FeedName,
+			Author:      post.Author,
+			Categories:  post.Categories,
+			Description: post.Description,
+			PublishedAt: post.PublishedAt,
+			FetchedAt:   post.CreatedAt,
+		})
+	}
+	return page, nil
+}
+
+// Get the post named in the path
+func apiPathPost(s *State, r *http.Request) (database.Post, error) {
+	post, err := findPost(s, r.PathValue("post_id"))
+	if err != nil {
+		return database.Post{}, notFound("%v", err)
+	}
+	return post, nil
+}
+
+// PUT /api/users/{name}/posts/{post_id}/read
+func apiMarkRead(s *State, r *http.Request) (any, error) {
+	return nil, apiSetPostState(s, r, "read")
+}
+
+// DELETE /api/users/{name}/posts/{post_id}/read
+func apiMarkUnread(s *State, r *http.Request) (any, error) {
+	return nil, apiSetPostState(s, r, "unread")
+}
+
+// Change the state of the post named in the path for the user named in the path
+func apiSetPostState(s *State, r *http.Request, action string) error {
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		return err
+	}
+	post, err := apiPathPost(s, r)
+	if err != nil {
+		return err
+	}
+	return setPostState(r.Context(), s, user, post, action)
+}
+
+// GET /api/users/{name}/search
+func apiSearch(s *State, r *http.Request) (any, error) {
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		return nil, badRequest(fmt.Errorf("q is required"))
+	}
+	params := database.SearchPostsParams{
+		QueryText: query,
+		UserID:    user.ID,
+	}
+	if params.FollowedOnly, err = apiQueryBool(r, "following"); err != nil {
+		return nil, err
+	}
+	limit, err := apiQueryInt(r, "limit", DEFAULT_SEARCH_LIMIT)
+	if err != nil {
+		return nil, err
+	}
+	params.ResultLimit = int32(limit)
+	if feedRef := r.URL.Query().Get("feed"); feedRef != "" {
+		feedID, err := findSearchFeed(s, user, feedRef)
+		if err != nil {
+			return nil, badRequest(err)
+		}
+		params.FeedID = uuid.NullUUID{UUID: feedID, Valid: true}
+	}
+
+	results, err := s.Db.SearchPosts(r.Context(), params)
+	if err != nil {
+		return nil, fmt.Errorf("error searching posts: %w", err)
+	}
+
+	views := []apiSearchResultView{}
+	for _, result := range results {
+		views = append(views, apiSearchResultView{
+			ID:          result.ID,
+			Title:       result.Title,
+			Url:         result.Url,
+			FeedName:    result.FeedName,
+			PublishedAt: result.PublishedAt,
+			Rank:        result.Rank,
+			Snippet:     result.Snippet,
+		})
+	}
+	return views, nil
+}
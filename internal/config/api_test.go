@@ -0,0 +1,257 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+func TestServeRejectsRequestsWithoutToken(t *testing.T) {
+	mux := http.NewServeMux()
+	registerAPIRoutes(&State{}, mux)
+	registerWebRoutes(&State{}, mux)
+	handler := authenticateRequests(&State{}, true, mux)
+
+	for _, target := range []string{"/", "/u/alice", "/api/users/alice/follows", "/api/users"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", target, nil))
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("GET %v without a token answered %v, want %v", target, recorder.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestAPIPathUserTakesTheTokenUser(t *testing.T) {
+	alice := database.User{ID: uuid.New(), Name: "alice"}
+	request := func(name string) *http.Request {
+		r := httptest.NewRequest("GET", "/api/users/"+name+"/follows", nil)
+		r.SetPathValue("name", name)
+		return r.WithContext(context.WithValue(r.Context(), tokenUserKey{}, alice))
+	}
+
+	// The user comes from the token, the database isn't consulted
+	user, err := apiPathUser(&State{}, request("alice"))
+	if err != nil || user.ID != alice.ID {
+		t.Errorf("apiPathUser for the token's own name = %v, %v, want alice", user.Name, err)
+	}
+
+	var apiErr apiError
+	if _, err := apiPathUser(&State{}, request("bob")); !errors.As(err, &apiErr) || apiErr.Status != http.StatusForbidden {
+		t.Errorf("apiPathUser for another user = %v, want a %v error", err, http.StatusForbidden)
+	}
+}
+
+func TestAPIRejectsCrossSiteMutations(t *testing.T) {
+	alice := database.User{ID: uuid.New(), Name: "alice"}
+	mux := http.NewServeMux()
+	registerAPIRoutes(&State{}, mux)
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		origin      string
+		want        int
+	}{
+		{"form post", "POST", "/api/users/alice/follows", "text/plain", "", http.StatusUnsupportedMediaType},
+		{"urlencoded post", "POST", "/api/users/alice/follows", "application/x-www-form-urlencoded", "", http.StatusUnsupportedMediaType},
+		{"other origin", "POST", "/api/users/alice/follows", "application/json", "https://evil.example.com", http.StatusForbidden},
+		{"other origin delete", "DELETE", "/api/users/alice/follows/" + uuid.NewString(), "", "https://evil.example.com", http.StatusForbidden},
+		{"opaque origin", "PUT", "/api/users/alice/posts/" + uuid.NewString() + "/read", "", "null", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.target, strings.NewReader(`{"url":"https://blog.example.com/feed"}`))
+		if test.contentType != "" {
+			r.Header.Set("Content-Type", test.contentType)
+		}
+		if test.origin != "" {
+			r.Header.Set("Origin", test.origin)
+		}
+		r = r.WithContext(context.WithValue(r.Context(), tokenUserKey{}, alice))
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, r)
+		if recorder.Code != test.want {
+			t.Errorf("%v answered %v, want %v", test.name, recorder.Code, test.want)
+		}
+	}
+}
+
+// Handler of gator serve with tokens required
+func newTestServer(s *State) http.Handler {
+	mux := http.NewServeMux()
+	registerAPIRoutes(s, mux)
+	registerWebRoutes(s, mux)
+	return authenticateRequests(s, true, mux)
+}
+
+// Send an api request with the token and decode the json response into out
+func apiRequest(t *testing.T, handler http.Handler, token string, method string, target string, body string, out any) int {
+	t.Helper()
+	var r *http.Request
+	if body != "" {
+		r = httptest.NewRequest(method, target, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+	} else {
+		r = httptest.NewRequest(method, target, nil)
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, r)
+	if out != nil && recorder.Code < 300 {
+		if err := json.Unmarshal(recorder.Body.Bytes(), out); err != nil {
+			t.Fatalf("%v %v answered invalid json: %v\n%v", method, target, err, recorder.Body.String())
+		}
+	}
+	return recorder.Code
+}
+
+func TestAPIFollowReadAndUnfollow(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	feed := createTestFeed(t, s, bob, "Go Blog", "https://go.example.com/feed", "Go post one", "Go post two")
+	token := createTestToken(t, s, alice, "script", constants.TOKEN_SCOPE_MANAGE_FOLLOWS)
+	handler := newTestServer(s)
+
+	var follow apiFollowView
+	if code := apiRequest(t, handler, token, "POST", "/api/users/alice/follows", `{"url":"`+feed.Url+`"}`, &follow); code != http.StatusCreated {
+		t.Fatalf("follow answered %v, want %v", code, http.StatusCreated)
+	}
+	if follow.FeedID != feed.ID || follow.UnreadCount != 2 {
+		t.Errorf("follow = %+v, want feed %v with 2 unread posts", follow, feed.ID)
+	}
+
+	// Pages follow the cursor until it runs out
+	var titles []string
+	target := "/api/users/alice/posts?limit=1"
+	for range 3 {
+		var page apiPostPage
+		if code := apiRequest(t, handler, token, "GET", target, "", &page); code != http.StatusOK {
+			t.Fatalf("GET %v answered %v", target, code)
+		}
+		for _, post := range page.Posts {
+			titles = append(titles, post.Title)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		target = "/api/users/alice/posts?limit=1&after=" + url.QueryEscape(page.NextCursor)
+	}
+	if strings.Join(titles, ",") != "Go post one,Go post two" {
+		t.Fatalf("paged posts = %q, want both posts newest first", titles)
+	}
+
+	var page apiPostPage
+	apiRequest(t, handler, token, "GET", "/api/users/alice/posts", "", &page)
+	if code := apiRequest(t, handler, token, "PUT", "/api/users/alice/posts/"+page.Posts[0].ID.String()+"/read", "", nil); code != http.StatusNoContent {
+		t.Fatalf("mark read answered %v, want %v", code, http.StatusNoContent)
+	}
+	page = apiPostPage{}
+	apiRequest(t, handler, token, "GET", "/api/users/alice/posts", "", &page)
+	if len(page.Posts) != 1 || page.Posts[0].Title != "Go post two" {
+		t.Errorf("unread posts after marking one read = %+v, want only Go post two", page.Posts)
+	}
+
+	// The token acts for alice only
+	if code := apiRequest(t, handler, token, "GET", "/api/users/bob/follows", "", nil); code != http.StatusForbidden {
+		t.Errorf("reading bob's follows answered %v, want %v", code, http.StatusForbidden)
+	}
+
+	if code := apiRequest(t, handler, token, "DELETE", "/api/users/alice/follows/"+feed.ID.String(), "", nil); code != http.StatusNoContent {
+		t.Fatalf("unfollow answered %v, want %v", code, http.StatusNoContent)
+	}
+	var follows []apiFollowView
+	apiRequest(t, handler, token, "GET", "/api/users/alice/follows", "", &follows)
+	if len(follows) != 0 {
+		t.Errorf("follows after unfollowing = %+v, want none", follows)
+	}
+}
+
+func TestAPIReadOnlyTokensCantChangeFollows(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed", "Go post one")
+	token := createTestToken(t, s, alice, "reader", constants.TOKEN_SCOPE_READ_ONLY)
+	handler := newTestServer(s)
+
+	if code := apiRequest(t, handler, token, "POST", "/api/users/alice/follows", `{"url":"`+feed.Url+`"}`, nil); code != http.StatusForbidden {
+		t.Errorf("follow with a read-only token answered %v, want %v", code, http.StatusForbidden)
+	}
+
+	var results []apiSearchResultView
+	if code := apiRequest(t, handler, token, "GET", "/api/users/alice/search?q=post", "", &results); code != http.StatusOK {
+		t.Fatalf("search answered %v", code)
+	}
+	if len(results) != 1 || results[0].Title != "Go post one" {
+		t.Errorf("search = %+v, want Go post one", results)
+	}
+
+	if code := apiRequest(t, handler, "gator_unknown", "GET", "/api/users/alice/follows", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("an unknown token answered %v, want %v", code, http.StatusUnauthorized)
+	}
+}
+
+func TestAPIListPostsOnlyBlamesTheRequestForBadQueries(t *testing.T) {
+	// Every query against a closed database fails
+	db, err := sql.Open("postgres", "postgres://localhost/gator_closed?sslmode=disable")
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	db.Close()
+	s := &State{Db: database.New(db)}
+	alice := database.User{ID: uuid.New(), Name: "alice"}
+
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"sort=newest", http.StatusBadRequest},
+		{"since=yesterday", http.StatusBadRequest},
+		{"until=31/01/2024", http.StatusBadRequest},
+		{"after=garbage", http.StatusBadRequest},
+		{"limit=0", http.StatusBadRequest},
+		{"feed=Go+Blog", http.StatusInternalServerError},
+		{"folder=News", http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/api/users/alice/posts?"+test.query, nil)
+		r.SetPathValue("name", "alice")
+		r = r.WithContext(context.WithValue(r.Context(), tokenUserKey{}, alice))
+
+		_, err := apiListPosts(s, r)
+		status := http.StatusInternalServerError
+		var apiErr apiError
+		if errors.As(err, &apiErr) {
+			status = apiErr.Status
+		}
+		if err == nil || status != test.status {
+			t.Errorf("listing posts with %v = %v, want a %v error", test.query, err, test.status)
+		}
+	}
+}
+
+func TestAPIListPostsRejectsUnknownFeedsAndFolders(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	token := createTestToken(t, s, alice, "reader", constants.TOKEN_SCOPE_READ_ONLY)
+	handler := newTestServer(s)
+
+	for _, query := range []string{"feed=Go+Blog", "feed=https://go.example.com/feed", "folder=News"} {
+		if code := apiRequest(t, handler, token, "GET", "/api/users/alice/posts?"+query, "", nil); code != http.StatusBadRequest {
+			t.Errorf("listing posts with %v answered %v, want %v", query, code, http.StatusBadRequest)
+		}
+	}
+}
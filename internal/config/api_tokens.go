@@ -0,0 +1,314 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/secrets"
+)
+
+// Environment variable the cli reads an api token from, used instead of the login session
+const API_TOKEN_ENV = "GATOR_TOKEN"
+
+// Prefix of api tokens, so that leaked tokens are easy to recognise
+const API_TOKEN_PREFIX = "gator_"
+
+// Token scopes from least to most privileged
+var tokenScopes = []string{
+	constants.TOKEN_SCOPE_READ_ONLY,
+	constants.TOKEN_SCOPE_MANAGE_FOLLOWS,
+	constants.TOKEN_SCOPE_ADMIN,
+}
+
+// Commands a read-only token may run. Every other command needs
+// manage-follows, except those listed in adminCommands. publish writes
+// files and opens listeners, so it isn't read-only.
+var readOnlyCommands = map[string]bool{
+	"browse":    true,
+	"following": true,
+	"search":    true,
+	"saved":     true,
+	"whoami":    true,
+	"inbox":     true,
+}
+
+// Commands that need an admin token
+var adminCommands = map[string]bool{
+	"token": true,
+	"reset": true,
+	"user":  true,
+}
+
+// Invalid, expired or revoked api tokens
+var errInvalidAPIToken = errors.New("invalid or expired api token")
+
+// Token Handler
+// token create <name> [--scope read-only|manage-follows|admin] [--expires <date>]
+// token list
+// token revoke <name>
+// The token is printed once on creation, only its hash is stored.
+func TokenHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	scope := fs.String("scope", constants.TOKEN_SCOPE_READ_ONLY, "read-only, manage-follows or admin")
+	expires := fs.String("expires", "", "date the token stops working, never by default")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("usage: token create|list|revoke")
+	}
+
+	switch args[0] {
+	case "create":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: token create <name> [--scope read-only|manage-follows|admin] [--expires <date>]")
+		}
+		return createAPIToken(s, user, args[1], *scope, *expires)
+	case "list":
+		return listAPITokens(s, user)
+	case "revoke":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: token revoke <name>")
+		}
+		return revokeAPIToken(s, user, args[1])
+	default:
+		return fmt.Errorf("unknown subcommand %v, expected create, list or revoke", args[0])
+	}
+}
+
+// Create a token for the user and print it
+func createAPIToken(s *State, user database.User, name string, scope string, expires string) error {
+	if scopeRank(scope) < 0 {
+		return fmt.Errorf("unknown scope %v, expected %v", scope, strings.Join(tokenScopes, ", "))
+	}
+
+	var expiresAt sql.NullTime
+	if expires != "" {
+		parsed, err := parseFlagDate(expires)
+		if err != nil {
+			return err
+		}
+		if !parsed.After(time.Now()) {
+			return fmt.Errorf("expiry date %v is in the past", expires)
+		}
+		expiresAt = sql.NullTime{Time: parsed, Valid: true}
+	}
+
+	random, err := secrets.NewToken()
+	if err != nil {
+		return err
+	}
+	token := API_TOKEN_PREFIX + random
+
+	_, err = s.Db.CreateApiToken(context.Background(), database.CreateApiTokenParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: secrets.HashToken(token),
+		Scope:     scope,
+		ExpiresAt: expiresAt,
+	})
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == constants.ERR_CODE_UNIQUE_CONSTRAINT_VIOLATION {
+		return fmt.Errorf("you already have a token named %v", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error creating token: %w", err)
+	}
+
+	fmt.Printf("Created %v token %v. It won't be shown again:\n", scope, name)
+	fmt.Println(token)
+	return nil
+}
+
+// Print the user's tokens
+func listAPITokens(s *State, user database.User) error {
+	tokens, err := s.Db.GetApiTokensForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching tokens: %w", err)
+	}
+
+	now := time.Now()
+	fmt.Println("API tokens:")
+	for _, token := range tokens {
+		lastUsed := "never used"
+		if token.LastUsedAt.Valid {
+			lastUsed = "last used " + relativeTime(token.LastUsedAt.Time, now)
+		}
+		expiry := ""
+		if token.ExpiresAt.Valid {
+			if token.ExpiresAt.Time.After(now) {
+				expiry = ", expires " + token.ExpiresAt.Time.Format(time.DateOnly)
+			} else {
+				expiry = ", expired"
+			}
+		}
+		fmt.Printf("  * %v (%v) created %v, %v%v\n", token.Name, token.Scope, token.CreatedAt.Format(time.DateOnly), lastUsed, expiry)
+	}
+	return nil
+}
+
+// Delete one of the user's tokens by name
+func revokeAPIToken(s *State, user database.User, name string) error {
+	deleted, err := s.Db.DeleteApiToken(context.Background(), database.DeleteApiTokenParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if err != nil {
+		return fmt.Errorf("error revoking token: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("token %v not found", name)
+	}
+
+	fmt.Printf("Revoked token %v\n", name)
+	return nil
+}
+
+// Position of a scope in tokenScopes, -1 for unknown scopes
+func scopeRank(scope string) int {
+	for i, known := range tokenScopes {
+		if known == scope {
+			return i
+		}
+	}
+	return -1
+}
+
+// Whether a token with the granted scope may do what needs the required scope
+func scopeAllows(granted string, required string) bool {
+	return scopeRank(granted) >= 0 && scopeRank(granted) >= scopeRank(required)
+}
+
+// Scope a command needs when run with an api token
+func commandScope(name string) string {
+	switch {
+	case adminCommands[name]:
+		return constants.TOKEN_SCOPE_ADMIN
+	case readOnlyCommands[name]:
+		return constants.TOKEN_SCOPE_READ_ONLY
+	default:
+		return constants.TOKEN_SCOPE_MANAGE_FOLLOWS
+	}
+}
+
+// Look up the user of an api token and record that it was used
+func apiTokenUser(ctx context.Context, s *State, token string) (database.User, database.ApiToken, error) {
+	apiToken, err := s.Db.GetApiTokenByHash(ctx, secrets.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, database.ApiToken{}, errInvalidAPIToken
+	}
+	if err != nil {
+		return database.User{}, database.ApiToken{}, fmt.Errorf("error fetching token: %w", err)
+	}
+
+	now := time.Now()
+	if apiToken.ExpiresAt.Valid && !apiToken.ExpiresAt.Time.After(now) {
+		return database.User{}, database.ApiToken{}, errInvalidAPIToken
+	}
+
+	if err := s.Db.TouchApiToken(ctx, database.TouchApiTokenParams{
+		LastUsedAt: sql.NullTime{Time: now, Valid: true},
+		ID:         apiToken.ID,
+	}); err != nil {
+		return database.User{}, database.ApiToken{}, fmt.Errorf("error updating token: %w", err)
+	}
+
+	user, err := s.Db.GetUserByID(ctx, apiToken.UserID)
+	if err != nil {
+		return database.User{}, database.ApiToken{}, fmt.Errorf("error fetching user: %w", err)
+	}
+
+	// Admin rights only come with admin tokens, so with a narrower token
+	// an admin passes every role check inside the commands as a member
+	if user.Role == constants.ROLE_ADMIN && apiToken.Scope != constants.TOKEN_SCOPE_ADMIN {
+		user.Role = constants.ROLE_MEMBER
+	}
+	return user, apiToken, nil
+}
+
+// Look up the user of the token in GATOR_TOKEN and check it may run the command
+func envTokenUser(s *State, cmd Command) (database.User, error) {
+	user, apiToken, err := apiTokenUser(context.Background(), s, os.Getenv(API_TOKEN_ENV))
+	if errors.Is(err, errInvalidAPIToken) {
+		return database.User{}, fmt.Errorf("%v: %w", API_TOKEN_ENV, err)
+	}
+	if err != nil {
+		return database.User{}, err
+	}
+
+	if required := commandScope(cmd.Name); !scopeAllows(apiToken.Scope, required) {
+		return database.User{}, fmt.Errorf("%v needs a %v token, %v is %v", cmd.Name, required, apiToken.Name, apiToken.Scope)
+	}
+	return user, nil
+}
+
+// Key of the user authenticated by an api token in a request context
+type tokenUserKey struct{}
+
+// The user an api token authenticated the request as, if any
+func requestTokenUser(r *http.Request) (database.User, bool) {
+	user, ok := r.Context().Value(tokenUserKey{}).(database.User)
+	return user, ok
+}
+
+// Authenticate requests carrying an api token, either as a bearer token or as
+// the basic auth password so that browsers and feed readers can use it.
+// Reads need a read-only token and anything else manage-follows. Requests
+// without a token are only let through when tokens aren't required.
+func authenticateRequests(s *State, required bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := ""
+		if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+			token = strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+		} else if _, password, ok := r.BasicAuth(); ok {
+			token = password
+		}
+
+		if token == "" {
+			if required {
+				w.Header().Set("WWW-Authenticate", `Basic realm="gator", charset="UTF-8"`)
+				http.Error(w, "an api token is required", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, apiToken, err := apiTokenUser(r.Context(), s, token)
+		if errors.Is(err, errInvalidAPIToken) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="gator", charset="UTF-8"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			fmt.Printf("Error authenticating request: %v\n", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		required := constants.TOKEN_SCOPE_MANAGE_FOLLOWS
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			required = constants.TOKEN_SCOPE_READ_ONLY
+		}
+		if !scopeAllows(apiToken.Scope, required) {
+			http.Error(w, fmt.Sprintf("this request needs a %v token", required), http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenUserKey{}, user)))
+	})
+}
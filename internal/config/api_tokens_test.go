@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+)
+
+func TestCommandScopes(t *testing.T) {
+	tests := map[string]string{
+		"browse":  constants.TOKEN_SCOPE_READ_ONLY,
+		"publish": constants.TOKEN_SCOPE_MANAGE_FOLLOWS,
+		"feed":    constants.TOKEN_SCOPE_MANAGE_FOLLOWS,
+		"user":    constants.TOKEN_SCOPE_ADMIN,
+	}
+	for command, want := range tests {
+		if got := commandScope(command); got != want {
+			t.Errorf("commandScope(%v) = %v, want %v", command, got, want)
+		}
+	}
+}
+
+func TestAdminTokenScopeLimitsAdminRights(t *testing.T) {
+	s := newTestState(t)
+	s.Config.BackupDir = t.TempDir()
+	admin := makeTestAdmin(t, s, createTestUser(t, s, "admin"))
+	bob := createTestUser(t, s, "bob")
+	createTestFeed(t, s, bob, "Blog", "https://blog.example.com/feed", "First")
+
+	deleteBlog := MiddlewareLoggedIn(FeedHandler)
+	cmd := Command{Name: "feed", Arguments: []string{"delete", "Blog", "--yes", "--force"}}
+
+	t.Setenv(API_TOKEN_ENV, createTestToken(t, s, admin, "follows", constants.TOKEN_SCOPE_MANAGE_FOLLOWS))
+	_, err := captureStdout(t, func() error { return deleteBlog(s, cmd) })
+	if err == nil || !strings.Contains(err.Error(), "only the user who added") {
+		t.Fatalf("feed delete of bob's feed with an admin's manage-follows token = %v, want an ownership error", err)
+	}
+
+	t.Setenv(API_TOKEN_ENV, createTestToken(t, s, admin, "admin", constants.TOKEN_SCOPE_ADMIN))
+	if _, err := captureStdout(t, func() error { return deleteBlog(s, cmd) }); err != nil {
+		t.Fatalf("feed delete of bob's feed with an admin token: %v", err)
+	}
+}
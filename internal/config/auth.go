@@ -0,0 +1,154 @@
+package config
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/secrets"
+	"golang.org/x/term"
+)
+
+// How long a login lasts
+const SESSION_TTL = 30 * 24 * time.Hour
+
+const MIN_PASSWORD_LENGTH = 8
+
+// Logout Handler
+// Revokes the session stored in the config file
+func LogoutHandler(s *State, cmd Command) error {
+	if s.Config.SessionToken == "" {
+		return fmt.Errorf("not logged in")
+	}
+
+	if _, err := s.Db.DeleteSession(context.Background(), secrets.HashToken(s.Config.SessionToken)); err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+
+	username := s.Config.CurrentUsername
+	s.Config.SessionToken = ""
+	s.Config.CurrentUsername = ""
+	if err := s.SaveConfig(); err != nil {
+		return fmt.Errorf("error saving config %w", err)
+	}
+
+	fmt.Printf("%v has been logged out\n", username)
+	return nil
+}
+
+// Look up the user of the session stored in the config file
+func sessionUser(s *State) (database.User, error) {
+	if s.Config.SessionToken == "" {
+		return database.User{}, fmt.Errorf("not logged in, run gator login <name> first")
+	}
+
+	tokenHash := secrets.HashToken(s.Config.SessionToken)
+	user, err := s.Db.GetUserForSession(context.Background(), database.GetUserForSessionParams{
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, fmt.Errorf("session expired or revoked, run gator login <name> again")
+	}
+	if err != nil {
+		return database.User{}, fmt.Errorf("error fetching session: %w", err)
+	}
+
+	if err := s.Db.TouchSession(context.Background(), database.TouchSessionParams{
+		LastUsedAt: time.Now(),
+		TokenHash:  tokenHash,
+	}); err != nil {
+		return database.User{}, fmt.Errorf("error updating session: %w", err)
+	}
+
+	return user, nil
+}
+
+// Create a session for the user and store its token in the config file.
+// The previous session of this config is revoked.
+func startSession(s *State, user database.User) error {
+	if s.Config.SessionToken != "" {
+		if _, err := s.Db.DeleteSession(context.Background(), secrets.HashToken(s.Config.SessionToken)); err != nil {
+			return fmt.Errorf("error revoking previous session: %w", err)
+		}
+	}
+
+	now := time.Now()
+	if err := s.Db.DeleteExpiredSessions(context.Background(), database.DeleteExpiredSessionsParams{
+		UserID:    user.ID,
+		ExpiresAt: now,
+	}); err != nil {
+		return fmt.Errorf("error removing expired sessions: %w", err)
+	}
+
+	token, err := secrets.NewToken()
+	if err != nil {
+		return err
+	}
+	if _, err := s.Db.CreateSession(context.Background(), database.CreateSessionParams{
+		ID:         uuid.New(),
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(SESSION_TTL),
+		LastUsedAt: now,
+		UserID:     user.ID,
+		TokenHash:  secrets.HashToken(token),
+	}); err != nil {
+		return fmt.Errorf("error creating session: %w", err)
+	}
+
+	s.Config.CurrentUsername = user.Name
+	s.Config.SessionToken = token
+	if err := s.SaveConfig(); err != nil {
+		return fmt.Errorf("error saving config %w", err)
+	}
+	return nil
+}
+
+// Ask for a new password, twice when reading from a terminal
+func promptNewPassword() (string, error) {
+	password, err := promptPassword("Password: ")
+	if err != nil {
+		return "", err
+	}
+	if len(password) < MIN_PASSWORD_LENGTH {
+		return "", fmt.Errorf("password must be at least %v characters", MIN_PASSWORD_LENGTH)
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		confirmation, err := promptPassword("Confirm password: ")
+		if err != nil {
+			return "", err
+		}
+		if confirmation != password {
+			return "", fmt.Errorf("passwords don't match")
+		}
+	}
+	return password, nil
+}
+
+// Read a password without echoing it. When stdin isn't a terminal the
+// first line is used, so scripts can pipe the password in.
+func promptPassword(prompt string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("error reading password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("error reading password: %w", err)
+	}
+	return string(password), nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// Feed the text to the password prompts, which read stdin when it isn't a terminal
+func withStdin(t *testing.T, text string) {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("error creating pipe: %v", err)
+	}
+	writer.WriteString(text)
+	writer.Close()
+	stdin := os.Stdin
+	os.Stdin = reader
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		reader.Close()
+	})
+}
+
+func TestLoginNeedsAnAdminIssuedPassword(t *testing.T) {
+	s := newTestState(t)
+	t.Setenv("HOME", t.TempDir())
+	legacy := createTestUser(t, s, "legacy")
+
+	// Even the config already naming the user can't choose a password
+	s.Config.CurrentUsername = legacy.Name
+	_, err := captureStdout(t, func() error {
+		return LoginHandler(s, Command{Name: "login", Arguments: []string{legacy.Name}})
+	})
+	if err == nil || !strings.Contains(err.Error(), "set-password") {
+		t.Fatalf("login without a password = %v, want an error pointing to set-password", err)
+	}
+
+	withStdin(t, "correct horse\n")
+	if _, err := captureStdout(t, func() error { return setUserPassword(s, legacy.Name) }); err != nil {
+		t.Fatalf("set-password: %v", err)
+	}
+
+	withStdin(t, "correct horse\n")
+	if _, err := captureStdout(t, func() error {
+		return LoginHandler(s, Command{Name: "login", Arguments: []string{legacy.Name}})
+	}); err != nil {
+		t.Fatalf("login with the new password: %v", err)
+	}
+	if s.Config.SessionToken == "" {
+		t.Error("login didn't start a session")
+	}
+}
+
+// Run user set-password the way main registers it
+func bootstrapSetPassword(t *testing.T, s *State, args ...string) error {
+	t.Helper()
+	handler := AllowPasswordBootstrap(MiddlewareLoggedIn(UserHandler))
+	_, err := captureStdout(t, func() error {
+		return handler(s, Command{Name: "user", Arguments: append([]string{"set-password"}, args...)})
+	})
+	return err
+}
+
+func TestAdminPasswordBootstrapAfterAMemberRegistered(t *testing.T) {
+	s := newTestState(t)
+	t.Setenv("HOME", t.TempDir())
+	legacy := makeTestAdmin(t, s, createTestUser(t, s, "legacy"))
+
+	// A member registering doesn't close the way for the legacy admin
+	withStdin(t, "member password\n")
+	if _, err := captureStdout(t, func() error {
+		return RegisterHandler(s, Command{Name: "register", Arguments: []string{"member"}})
+	}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	// Without a code the member's session is used, which can't change an admin
+	withStdin(t, "taken over\n")
+	if err := bootstrapSetPassword(t, s, legacy.Name); err == nil {
+		t.Fatal("a member set the admin's password")
+	}
+	if err := bootstrapSetPassword(t, s, legacy.Name, "--bootstrap-code", "guessed"); err == nil {
+		t.Fatal("a made up bootstrap code was accepted")
+	}
+
+	output, err := captureStdout(t, func() error { return offerPasswordBootstrap(s) })
+	if err != nil {
+		t.Fatalf("offering a bootstrap code: %v", err)
+	}
+	fields := strings.Fields(output)
+	if len(fields) == 0 || !strings.Contains(output, "--bootstrap-code") {
+		t.Fatalf("no bootstrap code printed:\n%v", output)
+	}
+	code := fields[len(fields)-1]
+
+	if err := bootstrapSetPassword(t, s, "member", "--bootstrap-code", code); err == nil {
+		t.Fatal("the bootstrap code set a member's password")
+	}
+
+	withStdin(t, "admin password\n")
+	if err := bootstrapSetPassword(t, s, legacy.Name, "--bootstrap-code", code); err != nil {
+		t.Fatalf("set-password with the bootstrap code: %v", err)
+	}
+
+	withStdin(t, "admin password\n")
+	if _, err := captureStdout(t, func() error {
+		return LoginHandler(s, Command{Name: "login", Arguments: []string{legacy.Name}})
+	}); err != nil {
+		t.Fatalf("login as the admin: %v", err)
+	}
+
+	// Once an admin can log in, codes are neither offered nor accepted
+	if output, _ := captureStdout(t, func() error { return offerPasswordBootstrap(s) }); output != "" {
+		t.Errorf("a bootstrap code was offered while an admin has a password:\n%v", output)
+	}
+	withStdin(t, "another password\n")
+	if err := bootstrapSetPassword(t, s, legacy.Name, "--bootstrap-code", code); err == nil {
+		t.Error("the bootstrap code was accepted twice")
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Titles printed by browse in the compact format
+func browseTitles(t *testing.T, s *State, user database.User, args ...string) string {
+	t.Helper()
+	cmd := Command{Name: "browse", Arguments: append([]string{"20", "--format", FORMAT_COMPACT}, args...)}
+	output, err := captureStdout(t, func() error {
+		return BrowseHandler(s, cmd, user)
+	})
+	if err != nil {
+		t.Fatalf("browse %v: %v", strings.Join(cmd.Arguments, " "), err)
+	}
+	return output
+}
+
+// Check which of the titles browse printed
+func expectTitles(t *testing.T, output string, shown []string, hidden []string) {
+	t.Helper()
+	for _, title := range shown {
+		if !strings.Contains(output, title) {
+			t.Errorf("browse should show %q, got:\n%v", title, output)
+		}
+	}
+	for _, title := range hidden {
+		if strings.Contains(output, title) {
+			t.Errorf("browse should not show %q, got:\n%v", title, output)
+		}
+	}
+}
+
+func TestFollowAndUnfollowChangeBrowse(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	createTestFeed(t, s, bob, "Go Blog", "https://go.example.com/feed", "Go post one", "Go post two")
+	createTestFeed(t, s, bob, "Rust Blog", "https://rust.example.com/feed", "Rust post one")
+	all := []string{"Go post one", "Go post two", "Rust post one"}
+
+	run := func(handler func(*State, Command, database.User) error, name string, args ...string) {
+		t.Helper()
+		if _, err := captureStdout(t, func() error {
+			return handler(s, Command{Name: name, Arguments: args}, alice)
+		}); err != nil {
+			t.Fatalf("%v %v: %v", name, strings.Join(args, " "), err)
+		}
+	}
+
+	// Nothing followed, nothing shown
+	expectTitles(t, browseTitles(t, s, alice), nil, all)
+
+	run(FollowHandler, "follow", "https://go.example.com/feed")
+	expectTitles(t, browseTitles(t, s, alice), []string{"Go post one", "Go post two"}, []string{"Rust post one"})
+
+	run(FollowHandler, "follow", "https://rust.example.com/feed")
+	expectTitles(t, browseTitles(t, s, alice), all, nil)
+
+	run(UnfollowHandler, "unfollow", "https://go.example.com/feed")
+	expectTitles(t, browseTitles(t, s, alice), []string{"Rust post one"}, []string{"Go post one", "Go post two"})
+
+	// Following is per user
+	expectTitles(t, browseTitles(t, s, bob), nil, all)
+}
+
+func TestBrowseHidesReadPostsUnlessAll(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed", "Go post one", "Go post two")
+
+	if _, err := captureStdout(t, func() error {
+		return FollowHandler(s, Command{Name: "follow", Arguments: []string{"https://go.example.com/feed"}}, alice)
+	}); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+	if _, err := captureStdout(t, func() error {
+		return ReadHandler(s, Command{Name: "read", Arguments: []string{"https://go.example.com/feed/0"}}, alice)
+	}); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	expectTitles(t, browseTitles(t, s, alice), []string{"Go post two"}, []string{"Go post one"})
+	expectTitles(t, browseTitles(t, s, alice, "--all"), []string{"Go post one", "Go post two"}, nil)
+	expectTitles(t, browseTitles(t, s, alice, "--unread=false"), []string{"Go post one", "Go post two"}, nil)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +19,7 @@ import (
 	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
 	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
 	"github.com/zawhtetnaing10/Blog-Aggregator/internal/network"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/secrets"
 )
 
 const configFileName = ".gatorconfig.json"
@@ -25,6 +28,7 @@ const configFileName = ".gatorconfig.json"
 type State struct {
 	Config *Config
 	Db     *database.Queries
+	Client *network.Client
 }
 
 // Write the state back to the config file
@@ -40,8 +44,75 @@ type Command struct {
 
 // Config
 type Config struct {
-	DbUrl           string `json:"db_url"`
-	CurrentUsername string `json:"current_user_name"`
+	DbUrl           string     `json:"db_url"`
+	CurrentUsername string     `json:"current_user_name"`
+	HTTP            HTTPConfig `json:"http"`
+	// Base64 encoded 32 byte key used to encrypt feed credentials
+	EncryptionKey string `json:"encryption_key,omitempty"`
+	// Token of the session created by login
+	SessionToken string `json:"session_token,omitempty"`
+	// Directory of the backups taken before destructive commands, ~/.gator/backups by default
+	BackupDir string `json:"backup_dir,omitempty"`
+	// WebSub callback server used by agg
+	WebSub WebSubConfig `json:"websub"`
+	// Smtp server digests are sent through
+	SMTP SMTPConfig `json:"smtp"`
+	// How often agg sends digests as a duration string such as "24h", never when empty
+	DigestInterval string `json:"digest_interval,omitempty"`
+}
+
+// HTTP client settings. Timeouts are duration strings such as "10s".
+type HTTPConfig struct {
+	ProxyURL            string   `json:"proxy_url,omitempty"`
+	NoProxy             string   `json:"no_proxy,omitempty"`
+	RootCAFiles         []string `json:"root_ca_files,omitempty"`
+	ClientCertFile      string   `json:"client_cert_file,omitempty"`
+	ClientKeyFile       string   `json:"client_key_file,omitempty"`
+	ConnectTimeout      string   `json:"connect_timeout,omitempty"`
+	ReadTimeout         string   `json:"read_timeout,omitempty"`
+	TotalTimeout        string   `json:"total_timeout,omitempty"`
+	UserAgent           string   `json:"user_agent,omitempty"`
+	ContactURL          string   `json:"contact_url,omitempty"`
+	MaxIdleConnsPerHost int      `json:"max_idle_conns_per_host,omitempty"`
+	RobotsTTL           string   `json:"robots_ttl,omitempty"`
+}
+
+// Build the shared http client from the config
+func (c *Config) NewHTTPClient() (*network.Client, error) {
+	opts := network.ClientOptions{
+		ProxyURL:            c.HTTP.ProxyURL,
+		NoProxy:             c.HTTP.NoProxy,
+		RootCAFiles:         c.HTTP.RootCAFiles,
+		ClientCertFile:      c.HTTP.ClientCertFile,
+		ClientKeyFile:       c.HTTP.ClientKeyFile,
+		UserAgent:           c.HTTP.UserAgent,
+		ContactURL:          c.HTTP.ContactURL,
+		MaxIdleConnsPerHost: c.HTTP.MaxIdleConnsPerHost,
+	}
+
+	// Parse the timeouts, empty values keep the defaults
+	timeouts := []struct {
+		name  string
+		value string
+		dest  *time.Duration
+	}{
+		{"connect_timeout", c.HTTP.ConnectTimeout, &opts.ConnectTimeout},
+		{"read_timeout", c.HTTP.ReadTimeout, &opts.ReadTimeout},
+		{"total_timeout", c.HTTP.TotalTimeout, &opts.TotalTimeout},
+		{"robots_ttl", c.HTTP.RobotsTTL, &opts.RobotsTTL},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(timeout.value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %v: %w", timeout.name, err)
+		}
+		*timeout.dest = duration
+	}
+
+	return network.NewClient(opts)
 }
 
 // Commands
@@ -71,12 +142,19 @@ func (c *Commands) Run(s *State, cmd Command) error {
 }
 
 // Midel ware logged in
+// Runs the handler as the user of the GATOR_TOKEN api token or of the session stored in the config file
 func MiddlewareLoggedIn(handler func(s *State, cmd Command, user database.User) error) func(s *State, cmd Command) error {
 	return func(s *State, cmd Command) error {
-		user, err := s.Db.GetUser(context.Background(), s.Config.CurrentUsername)
-
+		// An api token in the environment takes the place of the login session
+		var user database.User
+		var err error
+		if os.Getenv(API_TOKEN_ENV) != "" {
+			user, err = envTokenUser(s, cmd)
+		} else {
+			user, err = sessionUser(s)
+		}
 		if err != nil {
-			return fmt.Errorf("error fetching user: %w", err)
+			return err
 		}
 
 		return handler(s, cmd, user)
@@ -84,24 +162,65 @@ func MiddlewareLoggedIn(handler func(s *State, cmd Command, user database.User)
 }
 
 // Browse Handler
+// browse [limit] [--all] [--since-follow] [--format compact|detailed|<template>]
+// [--after <cursor>] [--feed <url|name>] [--since <date>] [--until <date>]
+// [--folder <name>] [--author <name>] [--category <name>] [--sort published|fetched|priority].
+// Only unread posts of the followed feeds are shown unless --all or --unread=false is given.
 func BrowseHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	unread := fs.Bool("unread", true, "show only unread posts, --unread=false is the same as --all")
+	all := fs.Bool("all", false, "show read posts too")
+	sinceFollow := fs.Bool("since-follow", false, "hide posts published before the feed was followed")
+	format := fs.String("format", FORMAT_DETAILED, "compact, detailed or a text/template")
+	after := fs.String("after", "", "cursor printed by the previous page")
+	feedRef := fs.String("feed", "", "url or name of a followed feed")
+	folderName := fs.String("folder", "", "only feeds in this folder and its subfolders")
+	since := fs.String("since", "", "only posts published on or after this date")
+	until := fs.String("until", "", "only posts published before this date")
+	author := fs.String("author", "", "only posts whose author contains this text")
+	category := fs.String("category", "", "only posts in this category")
+	sortBy := fs.String("sort", SORT_PUBLISHED, "sort by published or fetched time, or by feed priority")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	formatter, err := newPostFormatter(*format)
+	if err != nil {
+		return err
+	}
+	showAll := *all || !*unread
+
 	var limit int
-	if len(cmd.Arguments) == 0 {
+	if len(args) == 0 {
 		limit = 2
 	} else {
-		convertedInt, err := strconv.ParseInt(cmd.Arguments[0], 10, 32)
+		convertedInt, err := strconv.ParseInt(args[0], 10, 32)
 		if err != nil {
 			return fmt.Errorf("error converting the input: %w", err)
 		}
+		if convertedInt < 1 {
+			return fmt.Errorf("the number of posts must be at least 1")
+		}
 		limit = int(convertedInt)
 	}
 
-	params := database.GetPostsForUserParams{
-		UserID: uuid.NullUUID{
-			UUID:  user.ID,
-			Valid: true,
-		},
-		Limit: int32(limit),
+	query := postQuery{
+		All:         showAll,
+		SinceFollow: *sinceFollow,
+		Feed:        *feedRef,
+		Folder:      *folderName,
+		Since:       *since,
+		Until:       *until,
+		Author:      *author,
+		Category:    *category,
+		Sort:        *sortBy,
+		After:       *after,
+		Limit:       limit,
+	}
+	params, err := query.params(s, user)
+	if err != nil {
+		return err
 	}
 
 	posts, err := s.Db.GetPostsForUser(context.Background(), params)
@@ -109,14 +228,67 @@ func BrowseHandler(s *State, cmd Command, user database.User) error {
 		return fmt.Errorf("error fetching posts from db: %w", err)
 	}
 
-	fmt.Println("Followed posts : ")
+	builtInFormat := *format == FORMAT_DETAILED || *format == FORMAT_COMPACT
+	if builtInFormat {
+		if showAll {
+			fmt.Println("Followed posts : ")
+		} else {
+			fmt.Println("Unread followed posts : ")
+		}
+	}
 	for _, post := range posts {
-		fmt.Printf(" * %v\n", post.Title)
+		view := newPostView(post.ID, post.Title, post.Url, post.Description, post.PublishedAt, post.FeedName)
+		if err := formatter(os.Stdout, view); err != nil {
+			return err
+		}
+	}
+
+	// A full page may be followed by more posts
+	if next := nextPostCursor(params, posts); builtInFormat && next != "" {
+		fmt.Printf("Next page: --after %v\n", next)
 	}
 
 	return nil
 }
 
+// Find a followed feed by its url or name
+func findFollowedFeed(s *State, user database.User, ref string) (uuid.UUID, error) {
+	feedFollows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("error getting feed follows: %w", err)
+	}
+
+	// Urls are unique
+	feed, err := s.Db.GetFeedByUrl(context.Background(), ref)
+	if err == nil {
+		for _, feedFollow := range feedFollows {
+			if feedFollow.FeedID == feed.ID {
+				return feed.ID, nil
+			}
+		}
+		return uuid.UUID{}, inputError{fmt.Errorf("you are not following %v", ref)}
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return uuid.UUID{}, fmt.Errorf("error getting feed: %w", err)
+	}
+
+	// Names are not, so they must match exactly one followed feed
+	var matches []uuid.UUID
+	for _, feedFollow := range feedFollows {
+		if strings.EqualFold(feedFollow.FeedName, ref) {
+			matches = append(matches, feedFollow.FeedID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return uuid.UUID{}, inputError{fmt.Errorf("no followed feed named %v", ref)}
+	case 1:
+		return matches[0], nil
+	default:
+		return uuid.UUID{}, inputError{fmt.Errorf("several followed feeds are named %v, use the url instead", ref)}
+	}
+}
+
 // Unfollow Handler
 func UnfollowHandler(s *State, cmd Command, user database.User) error {
 	// early exit with error if command arguments are empty
@@ -163,11 +335,14 @@ func FollowingHandler(s *State, cmd Command, user database.User) error {
 		return fmt.Errorf("error getting feed follows: %w", err)
 	}
 
+	folders, err := s.Db.GetFoldersForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting folders: %w", err)
+	}
+
 	// Successfully print out the result
 	fmt.Println("Following feeds:")
-	for _, feedFollow := range feedFollows {
-		fmt.Printf("  * %v\n", feedFollow.FeedName)
-	}
+	printFollowingByFolder(folders, feedFollows)
 	return nil
 }
 
@@ -177,9 +352,30 @@ func FollowHandler(s *State, cmd Command, user database.User) error {
 	if len(cmd.Arguments) == 0 {
 		return fmt.Errorf("you need to provide the feed url to follow")
 	}
+	if cmd.Arguments[0] == "edit" {
+		return editFeedFollow(s, user, cmd.Arguments[1:])
+	}
+
+	fs := newFlagSet(cmd.Name)
+	fromUser := fs.String("from-user", "", "follow every feed this user follows")
+	dryRun := fs.Bool("dry-run", false, "with --from-user, only print what would be followed")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if *fromUser != "" {
+		if len(args) != 0 {
+			return fmt.Errorf("usage: follow --from-user <name> [--dry-run]")
+		}
+		return followFromUser(s, user, *fromUser, *dryRun)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("you need to provide the feed url to follow")
+	}
 
 	// Feed url from command
-	feed_url := cmd.Arguments[0]
+	feed_url := args[0]
 
 	feed, err := s.Db.GetFeedByUrl(context.Background(), feed_url)
 	if err != nil {
@@ -219,7 +415,14 @@ func FeedsHandler(s *State, cmd Command) error {
 		fmt.Printf("Feed : %v\n", index+1)
 		fmt.Printf("  * %v\n", feed.Name)
 		fmt.Printf("  * %v\n", feed.Url)
-		fmt.Printf("  * %v\n", feed.Username)
+		if feed.Username.Valid {
+			fmt.Printf("  * %v\n", feed.Username.String)
+		} else {
+			fmt.Println("  * no owner")
+		}
+		if feed.RobotsBlocked {
+			fmt.Println("  * blocked by robots.txt")
+		}
 	}
 
 	return nil
@@ -265,8 +468,8 @@ func AddFeedHandler(s *State, cmd Command, user database.User) error {
 		return fmt.Errorf("error creating feed follow: %w", feedFollowErr)
 	}
 
-	// Print out the inserted feed
-	fmt.Printf("%v\n", insertedFeed)
+	// Print out the inserted feed. The struct holds the encrypted credentials, so only name and url are shown.
+	fmt.Printf("Added feed %v (%v)\n", insertedFeed.Name, insertedFeed.Url)
 
 	return nil
 }
@@ -291,10 +494,43 @@ func AggHandler(s *State, cmd Command) error {
 	// Collecting feeds message
 	fmt.Printf("Collecting feed every %v\n", time_string)
 
+	// Let the operator set the first admin password after upgrading
+	if err := offerPasswordBootstrap(s); err != nil {
+		fmt.Printf("Error offering a bootstrap code: %v\n", err)
+	}
+
+	// Mail digests on a schedule
+	if s.Config.DigestInterval != "" {
+		interval, err := time.ParseDuration(s.Config.DigestInterval)
+		if err != nil {
+			return fmt.Errorf("error parsing digest_interval: %w", err)
+		}
+		if interval <= 0 {
+			return fmt.Errorf("digest_interval must be positive, got %v", s.Config.DigestInterval)
+		}
+		if !s.Config.SMTP.Enabled() {
+			return fmt.Errorf("digest_interval is set but no smtp server is configured")
+		}
+		fmt.Printf("Sending digests every %v\n", interval)
+		startDigestSchedule(s, interval)
+	}
+
+	// Receive pushed updates from WebSub hubs
+	if s.Config.WebSub.Enabled() {
+		if err := startWebSubServer(s); err != nil {
+			return err
+		}
+	}
+
 	// Print out the feeds to console
 	ticker := time.NewTicker(time_between_reqs)
 	for ; ; <-ticker.C {
 		scrapeFeeds(s)
+
+		// Renew hub subscriptions before their leases expire
+		if s.Config.WebSub.Enabled() {
+			renewWebSubLeases(s)
+		}
 	}
 }
 
@@ -319,42 +555,45 @@ func scrapeFeeds(s *State) error {
 		return fmt.Errorf("error marking feed as fetched: %w", err)
 	}
 
+	// Skip feeds disallowed by robots.txt
+	allowed, err := checkRobots(s, nextFeed)
+	if err != nil {
+		return fmt.Errorf("error checking robots.txt for %v: %w", nextFeed.Name, err)
+	}
+	if !allowed {
+		fmt.Printf("Skipped %v: blocked by robots.txt\n", nextFeed.Name)
+		return nil
+	}
+
+	// Credentials for private feeds
+	auth, err := loadFeedAuth(s, nextFeed)
+	if err != nil {
+		return err
+	}
+
 	// Fetch feeds from network using the url
-	fetchedFeeds, err := fetchFeedsFromNetwork(nextFeed.Url)
+	fetchedFeeds, err := fetchFeedsFromNetwork(s, nextFeed.Url, auth)
 	if err != nil {
 		return fmt.Errorf("error fetching feeds from network: %w", err)
 	}
 
 	// Print out the results
 	fmt.Printf("Fetched feeds from %v: \n", fetchedFeeds.Channel.Title)
-	for _, post := range fetchedFeeds.Channel.Item {
-		// Parse the date and save the post only when parsing is successful
-		parsedDate, err := parseDate(post.PubDate)
-		if err == nil {
-			// Parsing successful, save the post
-			params := database.CreatePostParams{
-				ID:          uuid.New(),
-				CreatedAt:   time.Now(),
-				UpdatedAt:   time.Now(),
-				Url:         post.Link,
-				Title:       post.Title,
-				Description: post.Description,
-				PublishedAt: parsedDate,
-				FeedID:      nextFeed.ID,
-			}
+	if fetchedFeeds.Status == network.ParseStatusWarnings {
+		fmt.Printf("Feed %v:\n", fetchedFeeds.Status)
+		for _, warning := range fetchedFeeds.Warnings {
+			fmt.Printf("  * %v\n", warning)
+		}
+	}
+	// Save the posts
+	if err := savePosts(s, nextFeed.ID, fetchedFeeds.Channel.Item); err != nil {
+		return err
+	}
 
-			// Add the post to db
-			_, createPostErr := s.Db.CreatePost(context.Background(), params)
-			if createPostErr != nil {
-				// If error is not nil, check if its unique constraint violaton
-				// Only when it's not, print out the error
-				errCode := createPostErr.(*pq.Error).Code
-				if errCode != constants.ERR_CODE_UNIQUE_CONSTRAINT_VIOLATION {
-					fmt.Printf("Error saving post: %v", createPostErr.Error())
-				}
-			}
-		} else {
-			return fmt.Errorf("error parsing date: %w", err)
+	// Subscribe to the feed's hub to receive updates without polling
+	if s.Config.WebSub.Enabled() {
+		if err := subscribeToHub(s, nextFeed, fetchedFeeds); err != nil {
+			fmt.Printf("Error subscribing to hub, keep polling %v: %v\n", nextFeed.Name, err)
 		}
 	}
 
@@ -363,6 +602,72 @@ func scrapeFeeds(s *State) error {
 	return nil
 }
 
+// Save the posts of a feed. Used for both polled and pushed content.
+func savePosts(s *State, feedID uuid.UUID, items []network.RSSItem) error {
+	// Ids of the posts inserted, matched against saved searches afterwards
+	var insertedIDs []uuid.UUID
+	defer func() {
+		evaluateSavedSearches(s, insertedIDs)
+	}()
+
+	for _, post := range items {
+		// A bad date only skips its own post, the rest of the batch is still saved
+		parsedDate, err := parseDate(post.PubDate)
+		if err != nil {
+			fmt.Printf("Skipping post %v: error parsing date: %v\n", post.Link, err)
+			continue
+		}
+
+		params := database.CreatePostParams{
+			ID:          uuid.New(),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			Url:         post.Link,
+			Title:       post.Title,
+			Description: post.Description,
+			PublishedAt: parsedDate,
+			FeedID:      feedID,
+			Author:      itemAuthor(post),
+			Categories:  itemCategories(post),
+			Content:     post.Content,
+		}
+
+		// Add the post to db
+		createdPost, createPostErr := s.Db.CreatePost(context.Background(), params)
+		if createPostErr == nil {
+			insertedIDs = append(insertedIDs, createdPost.ID)
+			continue
+		}
+
+		// Posts stored before are expected, only print other errors
+		var pqErr *pq.Error
+		if !errors.As(createPostErr, &pqErr) || pqErr.Code != constants.ERR_CODE_UNIQUE_CONSTRAINT_VIOLATION {
+			fmt.Printf("Error saving post: %v\n", createPostErr)
+		}
+	}
+
+	return nil
+}
+
+// Author of the item, falling back to the dublin core creator
+func itemAuthor(item network.RSSItem) string {
+	if item.Author != "" {
+		return strings.TrimSpace(item.Author)
+	}
+	return strings.TrimSpace(item.Creator)
+}
+
+// Categories of the item, never nil since the column is not nullable
+func itemCategories(item network.RSSItem) []string {
+	categories := []string{}
+	for _, category := range item.Categories {
+		if category = strings.TrimSpace(category); category != "" {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
 // Parse date from server
 func parseDate(date string) (time.Time, error) {
 	// Try multiple formats in sequence
@@ -395,9 +700,9 @@ func parseDate(date string) (time.Time, error) {
 }
 
 // Utility function to fetch feeds from Network
-func fetchFeedsFromNetwork(url string) (*network.RSSFeed, error) {
+func fetchFeedsFromNetwork(s *State, url string, auth *network.FeedAuth) (*network.RSSFeed, error) {
 	// Make the api request
-	result, err := network.FetchFeed(context.Background(), url)
+	result, err := s.Client.FetchFeed(context.Background(), url, auth)
 	if err != nil {
 		return &network.RSSFeed{}, err
 	}
@@ -426,9 +731,64 @@ func UsersHandler(s *State, cmd Command) error {
 }
 
 // Handle Reset
-func ResetHandler(s *State, cmd Command) error {
-	if err := s.Db.ResetUsers(context.Background()); err != nil {
-		return fmt.Errorf("error resetting users %w", err)
+// reset [--yes] [--dry-run]
+// Deletes every user and feed and with them all posts and reading state. Admins only.
+func ResetHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	dryRun := fs.Bool("dry-run", false, "print what would be deleted without deleting it")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 0 {
+		return fmt.Errorf("usage: reset [--yes] [--dry-run]")
+	}
+
+	if err := requireAdmin(user); err != nil {
+		return err
+	}
+
+	counts, err := s.Db.GetTableCounts(context.Background())
+	if err != nil {
+		return fmt.Errorf("error counting rows: %w", err)
+	}
+	fmt.Println("Resetting deletes:")
+	printRowCounts([]rowCount{
+		{"users", counts.Users},
+		{"sessions", counts.Sessions},
+		{"api_tokens", counts.ApiTokens},
+		{"feeds", counts.Feeds},
+		{"feed_follows", counts.FeedFollows},
+		{"folders", counts.Folders},
+		{"posts", counts.Posts},
+		{"post_reads", counts.PostReads},
+		{"saved_posts", counts.SavedPosts},
+		{"saved_searches", counts.SavedSearches},
+		{"saved_search_matches", counts.SavedSearchMatches},
+		{"mute_filters", counts.MuteFilters},
+		{"websub_subscriptions", counts.WebsubSubscriptions},
+		{"shares", counts.Shares},
+		{"digest_subscriptions", counts.DigestSubscriptions},
+		{"digests", counts.Digests},
+		{"digest_posts", counts.DigestPosts},
+	})
+	if *dryRun {
+		return nil
+	}
+
+	if err := confirmAction("Delete all data?", *yes); err != nil {
+		return err
+	}
+	if err := backupDatabase(s, "reset"); err != nil {
+		return err
+	}
+
+	// Feeds outlive the users who added them, so both are emptied. Every
+	// other table references one of them and is emptied with them.
+	if err := s.Db.ResetAll(context.Background()); err != nil {
+		return fmt.Errorf("error resetting database %w", err)
 	}
 
 	fmt.Println("All data has been reset")
@@ -453,12 +813,34 @@ func RegisterHandler(s *State, cmd Command) error {
 		return fmt.Errorf("user already exists")
 	}
 
+	// Ask for the password before creating anything
+	password, err := promptNewPassword()
+	if err != nil {
+		return err
+	}
+	passwordHash, err := secrets.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	// The first user of an empty database is its admin
+	role := constants.ROLE_MEMBER
+	users, err := s.Db.GetUsers(context.Background())
+	if err != nil {
+		return fmt.Errorf("error fetching users: %w", err)
+	}
+	if len(users) == 0 {
+		role = constants.ROLE_ADMIN
+	}
+
 	// Create the params to save to db
 	createUserParams := database.CreateUserParams{
-		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name:      name,
+		ID:           uuid.New(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		Name:         name,
+		PasswordHash: sql.NullString{String: passwordHash, Valid: true},
+		Role:         role,
 	}
 	// Save to db
 	createdUser, err := s.Db.CreateUser(context.Background(), createUserParams)
@@ -466,16 +848,16 @@ func RegisterHandler(s *State, cmd Command) error {
 		return fmt.Errorf("error creating user %w", err)
 	}
 
-	// Update config
-	s.Config.CurrentUsername = createdUser.Name
-
-	// Write the config to json file
-	if err := s.SaveConfig(); err != nil {
-		return fmt.Errorf("error saving config %w", err)
+	// Log in as the new user
+	if err := startSession(s, createdUser); err != nil {
+		return err
 	}
 
 	// Success message
 	fmt.Println("user has been created")
+	if role == constants.ROLE_ADMIN {
+		fmt.Println("as the first user, they are an admin")
+	}
 
 	return nil
 }
@@ -496,16 +878,29 @@ func LoginHandler(s *State, cmd Command) error {
 		return fmt.Errorf("user not found %w", err)
 	}
 
-	// Set username to State
-	s.Config.CurrentUsername = user.Name
+	// Accounts from before passwords exist need an admin to set one
+	if !user.PasswordHash.Valid {
+		return fmt.Errorf("%v has no password yet, ask an admin to run gator user set-password %v", user.Name, user.Name)
+	}
+	password, err := promptPassword("Password: ")
+	if err != nil {
+		return err
+	}
+	ok, err := secrets.CheckPassword(password, user.PasswordHash.String)
+	if err != nil {
+		return fmt.Errorf("error checking password: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("wrong password")
+	}
 
-	// Write the config to json file
-	if err := s.SaveConfig(); err != nil {
-		return fmt.Errorf("error saving config %w", err)
+	// Store the session in the config file
+	if err := startSession(s, user); err != nil {
+		return err
 	}
 
 	// Prints message
-	fmt.Println("user has been set")
+	fmt.Println("user has been logged in")
 
 	return nil
 }
@@ -558,9 +953,15 @@ func write(config Config) error {
 		return err
 	}
 
-	writeErr := os.WriteFile(configFilePath, bytes, 0644)
+	// The config holds the session token, keep it private. WriteFile only
+	// sets the mode of new files, so existing configs, which older versions
+	// wrote world readable, are restricted before the token is written.
+	if chmodErr := os.Chmod(configFilePath, 0600); chmodErr != nil && !os.IsNotExist(chmodErr) {
+		return fmt.Errorf("error restricting file permissions %w", chmodErr)
+	}
+	writeErr := os.WriteFile(configFilePath, bytes, 0600)
 	if writeErr != nil {
-		return fmt.Errorf("error writing file %w", err)
+		return fmt.Errorf("error writing file %w", writeErr)
 	}
 
 	return nil
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/network"
+)
+
+func TestResetDeletesEveryTable(t *testing.T) {
+	s := newTestState(t)
+	s.Config.BackupDir = t.TempDir()
+	admin := createTestUser(t, s, "admin")
+	admin.Role = constants.ROLE_ADMIN
+	createTestFeed(t, s, admin, "Blog", "https://blog.example.com/feed", "First", "Second")
+
+	if _, err := captureStdout(t, func() error {
+		return ResetHandler(s, Command{Name: "reset", Arguments: []string{"--yes"}}, admin)
+	}); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+
+	counts, err := s.Db.GetTableCounts(context.Background())
+	if err != nil {
+		t.Fatalf("error counting rows: %v", err)
+	}
+	if counts.Users != 0 || counts.Feeds != 0 || counts.Posts != 0 {
+		t.Errorf("after reset %v users, %v feeds and %v posts are left, want none", counts.Users, counts.Feeds, counts.Posts)
+	}
+}
+
+func TestWriteRestrictsAnExistingConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, configFileName)
+	if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := write(Config{SessionToken: "secret"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("config file mode is %v, want -rw-------", mode)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		date string
+		want string
+	}{
+		{"Mon, 02 Jan 2006 15:04:05 -0700", "2006-01-02T22:04:05Z"},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", "2006-01-02T15:04:05Z"},
+		{"2006-01-02T15:04:05Z", "2006-01-02T15:04:05Z"},
+		{"2006-01-02T15:04:05+01:00", "2006-01-02T14:04:05Z"},
+		{"Mon, 2 Jan 2006 15:04:05 -0700", "2006-01-02T22:04:05Z"},
+		{"yesterday", ""},
+		{"", ""},
+	}
+	for _, test := range tests {
+		got, err := parseDate(test.date)
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("parseDate(%q) = %v, want an error", test.date, got)
+			}
+			continue
+		}
+		if err != nil || got.UTC().Format(time.RFC3339) != test.want {
+			t.Errorf("parseDate(%q) = %v, %v, want %v", test.date, got.UTC().Format(time.RFC3339), err, test.want)
+		}
+	}
+}
+
+func TestSavePostsSkipsOnlyItemsWithBadDates(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "Blog", "https://blog.example.com/feed")
+	items := []network.RSSItem{
+		{Title: "First", Link: "https://blog.example.com/1", PubDate: "Mon, 02 Jan 2006 15:04:05 -0700"},
+		{Title: "Undated", Link: "https://blog.example.com/2", PubDate: "someday"},
+		{Title: "Third", Link: "https://blog.example.com/3", PubDate: "2006-01-03T15:04:05Z"},
+		{Title: "First again", Link: "https://blog.example.com/1", PubDate: "Mon, 02 Jan 2006 15:04:05 -0700"},
+	}
+
+	output, err := captureStdout(t, func() error { return savePosts(s, feed.ID, items) })
+	if err != nil {
+		t.Fatalf("savePosts: %v", err)
+	}
+	if !strings.Contains(output, "https://blog.example.com/2") || strings.Contains(output, "Error saving post") {
+		t.Errorf("savePosts should only report the undated post, printed:\n%v", output)
+	}
+
+	counts, err := s.Db.GetTableCounts(context.Background())
+	if err != nil {
+		t.Fatalf("error counting rows: %v", err)
+	}
+	if counts.Posts != 2 {
+		t.Errorf("savePosts stored %v posts, want the 2 with valid dates", counts.Posts)
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Position in a post listing, the feed priority, sort time and id of the last post shown.
+// Encoded as an opaque string for --after.
+type postCursor struct {
+	Priority int32
+	Time     time.Time
+	ID       uuid.UUID
+}
+
+// Encode the cursor for the command line
+func (c postCursor) String() string {
+	raw := fmt.Sprintf("%v|%v|%v", c.Time.UnixMicro(), c.ID, c.Priority)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode a cursor printed by a previous listing
+func parsePostCursor(encoded string) (postCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return postCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	micros, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return postCursor{}, fmt.Errorf("invalid cursor")
+	}
+
+	parsedMicros, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return postCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	// Cursors printed before priorities existed have no third part
+	id, priority, hasPriority := strings.Cut(id, "|")
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return postCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	var parsedPriority int64
+	if hasPriority {
+		parsedPriority, err = strconv.ParseInt(priority, 10, 32)
+		if err != nil {
+			return postCursor{}, fmt.Errorf("invalid cursor: %w", err)
+		}
+	}
+
+	return postCursor{Priority: int32(parsedPriority), Time: time.UnixMicro(parsedMicros).UTC(), ID: parsedID}, nil
+}
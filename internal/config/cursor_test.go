@@ -0,0 +1,211 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+func TestPostCursorRoundTrip(t *testing.T) {
+	cursors := []postCursor{
+		{Time: time.Date(2024, 1, 31, 8, 30, 15, 123456000, time.UTC), ID: uuid.New()},
+		{Priority: 5, Time: time.Date(1999, 12, 31, 23, 59, 59, 0, time.UTC), ID: uuid.New()},
+		{Priority: -3, Time: time.UnixMicro(0).UTC(), ID: uuid.New()},
+	}
+	for _, cursor := range cursors {
+		parsed, err := parsePostCursor(cursor.String())
+		if err != nil {
+			t.Fatalf("parsePostCursor(%v): %v", cursor, err)
+		}
+		if parsed != cursor {
+			t.Errorf("cursor %+v came back as %+v", cursor, parsed)
+		}
+	}
+}
+
+func TestParsePostCursor(t *testing.T) {
+	id := uuid.New()
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	// Cursors printed before priorities existed still work
+	legacy, err := parsePostCursor(encode("1700000000000000|" + id.String()))
+	if err != nil {
+		t.Fatalf("legacy cursor: %v", err)
+	}
+	if want := (postCursor{Time: time.UnixMicro(1700000000000000).UTC(), ID: id}); legacy != want {
+		t.Errorf("legacy cursor = %+v, want %+v", legacy, want)
+	}
+
+	invalid := []string{
+		"not base64!",
+		encode("1700000000000000"),
+		encode("yesterday|" + id.String()),
+		encode("1700000000000000|not-a-uuid"),
+		encode("1700000000000000|" + id.String() + "|high"),
+		encode("1700000000000000|" + id.String() + "|99999999999"),
+	}
+	for _, cursor := range invalid {
+		if _, err := parsePostCursor(cursor); err == nil {
+			t.Errorf("parsePostCursor(%q) was accepted", cursor)
+		}
+	}
+}
+
+func TestCursorPriorityOnlyAppliesToPrioritySort(t *testing.T) {
+	s := &State{}
+	user := database.User{ID: uuid.New()}
+	after := postCursor{Priority: 7, Time: time.Now().UTC(), ID: uuid.New()}.String()
+
+	for _, sortBy := range []string{"", SORT_PUBLISHED, SORT_FETCHED, SORT_PRIORITY} {
+		params, err := postQuery{Sort: sortBy, After: after, Limit: 10}.params(s, user)
+		if err != nil {
+			t.Fatalf("params with sort %q: %v", sortBy, err)
+		}
+		want := int32(0)
+		if sortBy == SORT_PRIORITY {
+			want = 7
+		}
+		if params.AfterPriority != want {
+			t.Errorf("sort %q: AfterPriority = %v, want %v", sortBy, params.AfterPriority, want)
+		}
+		if !params.AfterTime.Valid || !params.AfterID.Valid {
+			t.Errorf("sort %q: the cursor time and id weren't set", sortBy)
+		}
+	}
+
+	// The next cursor carries the priority only when sorting by it
+	posts := []database.GetPostsForUserRow{{ID: uuid.New(), FeedPriority: 4, PublishedAt: time.Now()}}
+	for _, params := range []database.GetPostsForUserParams{
+		{PostLimit: 1},
+		{PostLimit: 1, SortByFetched: true},
+		{PostLimit: 1, SortByPriority: true},
+	} {
+		cursor, err := parsePostCursor(nextPostCursor(params, posts))
+		if err != nil {
+			t.Fatalf("next cursor: %v", err)
+		}
+		want := int32(0)
+		if params.SortByPriority {
+			want = 4
+		}
+		if cursor.Priority != want {
+			t.Errorf("next cursor priority with %+v = %v, want %v", params, cursor.Priority, want)
+		}
+	}
+
+	// A page shorter than the limit is the last one
+	if next := nextPostCursor(database.GetPostsForUserParams{PostLimit: 2}, posts); next != "" {
+		t.Errorf("a short page had a next cursor %q", next)
+	}
+	if _, err := (postQuery{Sort: "newest", Limit: 10}).params(s, user); err == nil {
+		t.Error("an unknown sort was accepted")
+	}
+	if _, err := (postQuery{After: "garbage", Limit: 10}).params(s, user); err == nil {
+		t.Error("a bad cursor was accepted")
+	}
+}
+
+func TestPagesAreDisjointAndComplete(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	blog := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed")
+	news := createTestFeed(t, s, alice, "Go News", "https://news.example.com/feed")
+	runAs(t, s, alice, FollowHandler, "follow", blog.Url)
+	runAs(t, s, alice, FollowHandler, "follow", news.Url)
+	runAs(t, s, alice, FollowHandler, "follow", "edit", news.Url, "--priority", "5")
+
+	// Many posts share a timestamp, so only the id orders them
+	today := time.Now().UTC().Truncate(time.Second)
+	yesterday := today.Add(-24 * time.Hour)
+	for i := range 12 {
+		feed, published, author, categories := blog, today, "Rob Pike", []string{"go"}
+		if i%2 == 1 {
+			author, categories = "Ken Thompson", []string{"unix"}
+		}
+		if i%3 == 0 {
+			feed = news
+		}
+		if i >= 8 {
+			published = yesterday
+		}
+		createTestPost(t, s, feed, database.CreatePostParams{
+			Title:       "Post",
+			PublishedAt: published,
+			Author:      author,
+			Categories:  categories,
+		})
+	}
+
+	queries := map[string]postQuery{
+		"everything": {},
+		"since":      {Since: today.Add(-time.Hour).Format(time.RFC3339)},
+		"until":      {Until: today.Add(-time.Hour).Format(time.RFC3339)},
+		"author":     {Author: "pike"},
+		"category":   {Category: "Unix"},
+		"feed":       {Feed: news.Url},
+		"fetched":    {Sort: SORT_FETCHED},
+		"priority":   {Sort: SORT_PRIORITY},
+	}
+	for name, query := range queries {
+		query.All = true
+
+		// The whole listing in one page
+		query.Limit = 100
+		params, err := query.params(s, alice)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		whole, err := s.Db.GetPostsForUser(context.Background(), params)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if len(whole) == 0 {
+			t.Fatalf("%v: no posts matched", name)
+		}
+
+		// The same listing two posts at a time
+		query.Limit = 2
+		var paged []uuid.UUID
+		seen := map[uuid.UUID]bool{}
+		for page := 0; ; page++ {
+			if page > len(whole) {
+				t.Fatalf("%v: paging didn't end", name)
+			}
+			params, err := query.params(s, alice)
+			if err != nil {
+				t.Fatalf("%v page %v: %v", name, page, err)
+			}
+			posts, err := s.Db.GetPostsForUser(context.Background(), params)
+			if err != nil {
+				t.Fatalf("%v page %v: %v", name, page, err)
+			}
+			for _, post := range posts {
+				if seen[post.ID] {
+					t.Errorf("%v: post %v was on more than one page", name, post.ID)
+				}
+				seen[post.ID] = true
+				paged = append(paged, post.ID)
+			}
+			query.After = nextPostCursor(params, posts)
+			if query.After == "" {
+				break
+			}
+		}
+
+		if len(paged) != len(whole) {
+			t.Errorf("%v: pages held %v posts, want %v", name, len(paged), len(whole))
+			continue
+		}
+		for i := range whole {
+			if paged[i] != whole[i].ID {
+				t.Errorf("%v: post %v of the pages is %v, want %v", name, i, paged[i], whole[i].ID)
+			}
+		}
+	}
+}
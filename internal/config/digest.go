@@ -0,0 +1,385 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"net/mail"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/network"
+)
+
+// Most posts mailed in one digest
+const DIGEST_POST_LIMIT = 200
+
+// Number of past digests digest status shows
+const DIGEST_HISTORY_LIMIT = 10
+
+// Length of the post summaries in a digest
+const DIGEST_SUMMARY_LENGTH = 300
+
+//go:embed templates/digest.html templates/digest.txt
+var digestTemplateFiles embed.FS
+
+var (
+	digestHTMLTemplate = template.Must(template.ParseFS(digestTemplateFiles, "templates/digest.html"))
+	digestTextTemplate = texttemplate.Must(texttemplate.ParseFS(digestTemplateFiles, "templates/digest.txt"))
+)
+
+// Smtp server digests are sent through. Host and from are required, for
+// local mail sinks set tls to none.
+type SMTPConfig struct {
+	Host string `json:"host,omitempty"`
+	// 587 for starttls, 465 for tls and 25 for none by default
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Sender address, e.g. "Gator <gator@example.com>"
+	From string `json:"from,omitempty"`
+	// starttls (default), tls or none
+	TLS string `json:"tls,omitempty"`
+}
+
+// Whether an smtp server is configured
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != "" && c.From != ""
+}
+
+// Smtp settings with the defaults filled in
+func (c SMTPConfig) options() (network.SMTPOptions, error) {
+	opts := network.SMTPOptions{
+		Host:     c.Host,
+		Port:     c.Port,
+		Username: c.Username,
+		Password: c.Password,
+		TLS:      c.TLS,
+	}
+	if opts.TLS == "" {
+		opts.TLS = network.SMTP_TLS_STARTTLS
+	}
+
+	defaultPort := 0
+	switch opts.TLS {
+	case network.SMTP_TLS_STARTTLS:
+		defaultPort = 587
+	case network.SMTP_TLS_IMPLICIT:
+		defaultPort = 465
+	case network.SMTP_TLS_NONE:
+		defaultPort = 25
+	default:
+		return network.SMTPOptions{}, fmt.Errorf("unknown smtp tls setting %v, expected starttls, tls or none", opts.TLS)
+	}
+	if opts.Port == 0 {
+		opts.Port = defaultPort
+	}
+	return opts, nil
+}
+
+// Digest Handler
+// digest [--all] [--dry-run]
+// digest subscribe <email>
+// digest unsubscribe
+// digest status
+// Mails the unread posts that arrived since subscribing and weren't mailed yet. Admins can
+// send everyone's digest with --all, which agg also does on a schedule.
+func DigestHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	all := fs.Bool("all", false, "send the digests of all subscribed users")
+	dryRun := fs.Bool("dry-run", false, "print the digest instead of sending it")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		if *all {
+			if err := requireAdmin(user); err != nil {
+				return err
+			}
+			return sendDigests(s, uuid.Nil, *dryRun)
+		}
+		return sendDigests(s, user.ID, *dryRun)
+	}
+
+	switch args[0] {
+	case "subscribe":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: digest subscribe <email>")
+		}
+		return subscribeDigest(s, user, args[1])
+	case "unsubscribe":
+		return unsubscribeDigest(s, user)
+	case "status":
+		return printDigestStatus(s, user)
+	default:
+		return fmt.Errorf("unknown subcommand %v, expected subscribe, unsubscribe or status", args[0])
+	}
+}
+
+// Mail the user's digests to the address
+func subscribeDigest(s *State, user database.User, email string) error {
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return fmt.Errorf("invalid email address %v", email)
+	}
+
+	if err := s.Db.UpsertDigestSubscription(context.Background(), database.UpsertDigestSubscriptionParams{
+		UserID:    user.ID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Email:     address.Address,
+	}); err != nil {
+		return fmt.Errorf("error subscribing to digests: %w", err)
+	}
+
+	fmt.Printf("Digests for %v will be sent to %v\n", user.Name, address.Address)
+	if !s.Config.SMTP.Enabled() {
+		fmt.Println("No smtp server is configured yet, digests are sent once one is")
+	}
+	return nil
+}
+
+// Stop mailing the user's digests
+func unsubscribeDigest(s *State, user database.User) error {
+	deleted, err := s.Db.DeleteDigestSubscription(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error unsubscribing from digests: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%v isn't subscribed to digests", user.Name)
+	}
+
+	fmt.Printf("Digests for %v are no longer sent\n", user.Name)
+	return nil
+}
+
+// Print where the user's digests go and the last digests sent
+func printDigestStatus(s *State, user database.User) error {
+	subscription, ok, err := digestSubscription(s, user.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		fmt.Printf("%v isn't subscribed, run gator digest subscribe <email>\n", user.Name)
+	} else {
+		fmt.Printf("Digests go to %v since %v, the next one has the unread posts not mailed yet\n", subscription.Email, subscription.CreatedAt.Format(time.DateTime))
+	}
+
+	digests, err := s.Db.GetDigestsForUser(context.Background(), database.GetDigestsForUserParams{
+		UserID: user.ID,
+		Limit:  DIGEST_HISTORY_LIMIT,
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching digests: %w", err)
+	}
+	now := time.Now()
+	fmt.Println("Sent digests:")
+	for _, digest := range digests {
+		fmt.Printf("  * %v: %v posts to %v\n", relativeTime(digest.SentAt, now), digest.PostCount, digest.Email)
+	}
+	return nil
+}
+
+// The user's digest subscription, if they have one
+func digestSubscription(s *State, userID uuid.UUID) (database.GetDigestSubscriptionsRow, bool, error) {
+	subscriptions, err := s.Db.GetDigestSubscriptions(context.Background())
+	if err != nil {
+		return database.GetDigestSubscriptionsRow{}, false, fmt.Errorf("error fetching digest subscriptions: %w", err)
+	}
+	for _, subscription := range subscriptions {
+		if subscription.UserID == userID {
+			return subscription, true, nil
+		}
+	}
+	return database.GetDigestSubscriptionsRow{}, false, nil
+}
+
+// Send the digests of all subscribed users, or only of userID unless it is
+// uuid.Nil. A failure for one user doesn't stop the others.
+func sendDigests(s *State, userID uuid.UUID, dryRun bool) error {
+	if !dryRun && !s.Config.SMTP.Enabled() {
+		return fmt.Errorf("no smtp server configured, add an smtp object with host and from to the config file")
+	}
+
+	subscriptions, err := s.Db.GetDigestSubscriptions(context.Background())
+	if err != nil {
+		return fmt.Errorf("error fetching digest subscriptions: %w", err)
+	}
+
+	found := false
+	failed := 0
+	for _, subscription := range subscriptions {
+		if userID != uuid.Nil && subscription.UserID != userID {
+			continue
+		}
+		found = true
+		if err := sendDigest(s, subscription, dryRun); err != nil {
+			fmt.Printf("Error sending digest to %v: %v\n", subscription.Name, err)
+			failed++
+		}
+	}
+
+	if userID != uuid.Nil && !found {
+		return fmt.Errorf("not subscribed, run gator digest subscribe <email> first")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%v digests couldn't be sent", failed)
+	}
+	return nil
+}
+
+// Posts of one feed in a digest
+type digestSection struct {
+	FeedName string
+	Posts    []digestPost
+}
+
+// A post as shown in a digest
+type digestPost struct {
+	Title   string
+	Url     string
+	Meta    string
+	Summary string
+}
+
+// Everything the digest templates show
+type digestData struct {
+	Subject  string
+	Name     string
+	Count    int
+	More     bool
+	Sections []digestSection
+}
+
+// Mail one user their unread posts that arrived since they subscribed and
+// record which posts were sent, so they aren't sent again. Posts past the
+// limit aren't recorded and go out with the next digest.
+func sendDigest(s *State, subscription database.GetDigestSubscriptionsRow, dryRun bool) error {
+	sentAt := time.Now()
+	posts, err := s.Db.GetDigestPostsForUser(context.Background(), database.GetDigestPostsForUserParams{
+		UserID:       subscription.UserID,
+		SubscribedAt: subscription.CreatedAt,
+		PostLimit:    DIGEST_POST_LIMIT + 1,
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching posts: %w", err)
+	}
+	if len(posts) == 0 {
+		fmt.Printf("No new posts for %v\n", subscription.Name)
+		return nil
+	}
+
+	more := len(posts) > DIGEST_POST_LIMIT
+	if more {
+		posts = posts[:DIGEST_POST_LIMIT]
+	}
+
+	message, err := renderDigest(s, subscription, posts, more)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Printf("To: %v\nSubject: %v\n\n%v\n", message.To, message.Subject, message.Text)
+		return nil
+	}
+
+	opts, err := s.Config.SMTP.options()
+	if err != nil {
+		return err
+	}
+	if err := network.SendMail(opts, message); err != nil {
+		return err
+	}
+
+	digest, err := s.Db.CreateDigest(context.Background(), database.CreateDigestParams{
+		ID:        uuid.New(),
+		SentAt:    sentAt,
+		UserID:    subscription.UserID,
+		Email:     subscription.Email,
+		PostCount: int32(len(posts)),
+	})
+	if err != nil {
+		return fmt.Errorf("digest was sent but couldn't be recorded: %w", err)
+	}
+	postIDs := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		postIDs[i] = post.ID
+	}
+	if err := s.Db.CreateDigestPosts(context.Background(), database.CreateDigestPostsParams{
+		DigestID: digest.ID,
+		PostIds:  postIDs,
+	}); err != nil {
+		return fmt.Errorf("digest was sent but its posts couldn't be recorded: %w", err)
+	}
+
+	fmt.Printf("Sent %v posts to %v (%v)\n", len(posts), subscription.Name, subscription.Email)
+	return nil
+}
+
+// Build the digest mail with a section per feed. Posts arrive ordered by feed.
+func renderDigest(s *State, subscription database.GetDigestSubscriptionsRow, posts []database.GetDigestPostsForUserRow, more bool) (network.Mail, error) {
+	data := digestData{
+		Subject: fmt.Sprintf("gator: %v new posts", len(posts)),
+		Name:    subscription.Name,
+		Count:   len(posts),
+		More:    more,
+	}
+	if more {
+		data.Subject = fmt.Sprintf("gator: %v+ new posts", len(posts))
+	}
+
+	var lastFeed uuid.UUID
+	for _, post := range posts {
+		if len(data.Sections) == 0 || post.FeedID != lastFeed {
+			data.Sections = append(data.Sections, digestSection{FeedName: post.FeedName})
+			lastFeed = post.FeedID
+		}
+		meta := []string{post.PublishedAt.Format(time.DateOnly)}
+		if post.Author != "" {
+			meta = append(meta, post.Author)
+		}
+		section := &data.Sections[len(data.Sections)-1]
+		section.Posts = append(section.Posts, digestPost{
+			Title:   post.Title,
+			Url:     post.Url,
+			Meta:    strings.Join(meta, " · "),
+			Summary: excerpt(stripHTML(post.Description), DIGEST_SUMMARY_LENGTH),
+		})
+	}
+
+	var text, html bytes.Buffer
+	if err := digestTextTemplate.Execute(&text, data); err != nil {
+		return network.Mail{}, fmt.Errorf("error rendering digest: %w", err)
+	}
+	if err := digestHTMLTemplate.Execute(&html, data); err != nil {
+		return network.Mail{}, fmt.Errorf("error rendering digest: %w", err)
+	}
+
+	return network.Mail{
+		From:    s.Config.SMTP.From,
+		To:      subscription.Email,
+		Subject: data.Subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// Send everyone's digest every interval while agg runs
+func startDigestSchedule(s *State, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := sendDigests(s, uuid.Nil, false); err != nil {
+				fmt.Printf("Error sending digests: %v\n", err)
+			}
+		}
+	}()
+}
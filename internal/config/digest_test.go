@@ -0,0 +1,275 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/network"
+)
+
+// What an smtp client sent in one session
+type smtpEnvelope struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// Accept smtp sessions on a local port and report what each one sent. Just
+// enough of the protocol for net/smtp without TLS or authentication.
+func startSMTPSink(t *testing.T) (int, <-chan smtpEnvelope) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting smtp sink: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan smtpEnvelope, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			serveSMTPSession(conn, received)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, received
+}
+
+func serveSMTPSession(conn net.Conn, received chan<- smtpEnvelope) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	text := textproto.NewConn(conn)
+	var envelope smtpEnvelope
+	text.PrintfLine("220 sink ready")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			text.PrintfLine("250-sink\r\n250 8BITMIME")
+		case "MAIL":
+			envelope.From = arg
+			text.PrintfLine("250 ok")
+		case "RCPT":
+			envelope.To = append(envelope.To, arg)
+			text.PrintfLine("250 ok")
+		case "DATA":
+			text.PrintfLine("354 end with <CRLF>.<CRLF>")
+			envelope.Data, err = io.ReadAll(text.DotReader())
+			if err != nil {
+				return
+			}
+			text.PrintfLine("250 queued")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			received <- envelope
+			return
+		default:
+			text.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+// Wait for the next mail the sink receives
+func receiveMail(t *testing.T, received <-chan smtpEnvelope) smtpEnvelope {
+	t.Helper()
+	select {
+	case envelope := <-received:
+		return envelope
+	case <-time.After(5 * time.Second):
+		t.Fatal("the smtp sink received no mail")
+		return smtpEnvelope{}
+	}
+}
+
+func TestDigestMailReachesSMTPServer(t *testing.T) {
+	port, received := startSMTPSink(t)
+	s := &State{Config: &Config{SMTP: SMTPConfig{
+		Host: "127.0.0.1",
+		Port: port,
+		From: "Gator <gator@example.com>",
+		TLS:  network.SMTP_TLS_NONE,
+	}}}
+
+	subscription := database.GetDigestSubscriptionsRow{UserID: uuid.New(), Name: "alice", Email: "alice@example.com"}
+	blog, news := uuid.New(), uuid.New()
+	posts := []database.GetDigestPostsForUserRow{
+		{ID: uuid.New(), Title: "Rust or Go", Url: "https://blog.example.com/1", Description: "<p>A <b>long</b> look</p>", PublishedAt: time.Now(), FeedID: blog, FeedName: "Blog"},
+		{ID: uuid.New(), Title: "Café opens", Url: "https://news.example.com/2", Author: "Bob", PublishedAt: time.Now(), FeedID: news, FeedName: "News"},
+	}
+
+	message, err := renderDigest(s, subscription, posts, true)
+	if err != nil {
+		t.Fatalf("renderDigest: %v", err)
+	}
+	opts, err := s.Config.SMTP.options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := network.SendMail(opts, message); err != nil {
+		t.Fatalf("SendMail: %v", err)
+	}
+
+	envelope := receiveMail(t, received)
+	// net/smtp adds BODY=8BITMIME when the server supports it
+	if !strings.HasPrefix(envelope.From, "FROM:<gator@example.com>") {
+		t.Errorf("MAIL %v, want FROM:<gator@example.com>", envelope.From)
+	}
+	if len(envelope.To) != 1 || envelope.To[0] != "TO:<alice@example.com>" {
+		t.Errorf("RCPT %v, want TO:<alice@example.com>", envelope.To)
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(envelope.Data))
+	if err != nil {
+		t.Fatalf("error parsing the mail: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "gator: 2+ new posts" {
+		t.Errorf("subject %q (%v), want %q", subject, err, "gator: 2+ new posts")
+	}
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type %v (%v), want multipart/alternative", mediaType, err)
+	}
+
+	// The reader undoes the quoted-printable encoding of each part
+	parts := map[string]string{}
+	var order []string
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("error reading mail part: %v", err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("error reading %v part: %v", partType, err)
+		}
+		parts[partType] = string(body)
+		order = append(order, partType)
+	}
+	if fmt.Sprint(order) != "[text/plain text/html]" {
+		t.Fatalf("parts %v, want the text part before the html part", order)
+	}
+
+	for _, want := range []string{"Blog", "Rust or Go", "https://blog.example.com/1", "News", "Café opens", "Bob", "come with the next digest"} {
+		if !strings.Contains(parts["text/plain"], want) {
+			t.Errorf("text part doesn't contain %q:\n%v", want, parts["text/plain"])
+		}
+	}
+	for _, want := range []string{`href="https://news.example.com/2"`, "Café opens", "come with the next digest"} {
+		if !strings.Contains(parts["text/html"], want) {
+			t.Errorf("html part doesn't contain %q:\n%v", want, parts["text/html"])
+		}
+	}
+	if strings.Contains(parts["text/plain"], "<b>") {
+		t.Errorf("text part still contains html:\n%v", parts["text/plain"])
+	}
+}
+
+func TestDigestPostsPastTheLimitGoWithTheNextDigest(t *testing.T) {
+	s := newTestState(t)
+	port, received := startSMTPSink(t)
+	s.Config.SMTP = SMTPConfig{Host: "127.0.0.1", Port: port, From: "gator@example.com", TLS: network.SMTP_TLS_NONE}
+
+	alice := createTestUser(t, s, "alice")
+	if err := s.Db.UpsertDigestSubscription(context.Background(), database.UpsertDigestSubscriptionParams{
+		UserID:    alice.ID,
+		CreatedAt: time.Now().Add(-time.Hour),
+		UpdatedAt: time.Now().Add(-time.Hour),
+		Email:     "alice@example.com",
+	}); err != nil {
+		t.Fatalf("error subscribing: %v", err)
+	}
+	titles := make([]string, DIGEST_POST_LIMIT+1)
+	for i := range titles {
+		titles[i] = fmt.Sprintf("Post %v", i)
+	}
+	createTestFeed(t, s, alice, "Blog", "https://blog.example.com/feed", titles...)
+	if _, err := captureStdout(t, func() error {
+		return FollowHandler(s, Command{Name: "follow", Arguments: []string{"https://blog.example.com/feed"}}, alice)
+	}); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+
+	sendAndCount := func() int {
+		t.Helper()
+		if _, err := captureStdout(t, func() error { return sendDigests(s, alice.ID, false) }); err != nil {
+			t.Fatalf("sending digest: %v", err)
+		}
+		receiveMail(t, received)
+		digests, err := s.Db.GetDigestsForUser(context.Background(), database.GetDigestsForUserParams{UserID: alice.ID, Limit: 1})
+		if err != nil || len(digests) == 0 {
+			t.Fatalf("no digest recorded: %v", err)
+		}
+		return int(digests[0].PostCount)
+	}
+
+	if count := sendAndCount(); count != DIGEST_POST_LIMIT {
+		t.Errorf("first digest has %v posts, want %v", count, DIGEST_POST_LIMIT)
+	}
+	if count := sendAndCount(); count != 1 {
+		t.Errorf("second digest has %v posts, want the 1 left out of the first", count)
+	}
+}
+
+func TestDigestSendsOnlyNewUnreadPostsOnce(t *testing.T) {
+	s := newTestState(t)
+	port, received := startSMTPSink(t)
+	s.Config.SMTP = SMTPConfig{Host: "127.0.0.1", Port: port, From: "gator@example.com", TLS: network.SMTP_TLS_NONE}
+
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed", "Stored before subscribing")
+	other := createTestFeed(t, s, alice, "Rust Blog", "https://rust.example.com/feed")
+	runAs(t, s, alice, FollowHandler, "follow", feed.Url)
+	runAs(t, s, alice, FilterHandler, "filter", "add", "keyword", "crypto")
+	runAs(t, s, alice, DigestHandler, "digest", "subscribe", "alice@example.com")
+
+	createTestPost(t, s, feed, database.CreatePostParams{Title: "Go post one", Url: feed.Url + "/one"})
+	createTestPost(t, s, feed, database.CreatePostParams{Title: "Go post two", Url: feed.Url + "/two"})
+	createTestPost(t, s, feed, database.CreatePostParams{Title: "Crypto and Go"})
+	createTestPost(t, s, other, database.CreatePostParams{Title: "Rust post one"})
+	runAs(t, s, alice, ReadHandler, "read", feed.Url+"/two")
+
+	// Read, muted, unfollowed and older posts are left out
+	output := runAs(t, s, alice, DigestHandler, "digest", "--dry-run")
+	expectTitles(t, output, []string{"Go post one"}, []string{"Go post two", "Crypto and Go", "Rust post one", "Stored before subscribing"})
+
+	runAs(t, s, alice, DigestHandler, "digest")
+	receiveMail(t, received)
+	digests, err := s.Db.GetDigestsForUser(context.Background(), database.GetDigestsForUserParams{UserID: alice.ID, Limit: 1})
+	if err != nil || len(digests) != 1 || digests[0].PostCount != 1 {
+		t.Fatalf("recorded digests = %+v (%v), want one with 1 post", digests, err)
+	}
+
+	if output := runAs(t, s, alice, DigestHandler, "digest"); !strings.Contains(output, "No new posts") {
+		t.Errorf("the second digest wasn't empty:\n%v", output)
+	}
+	select {
+	case <-received:
+		t.Error("an empty digest was mailed")
+	default:
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/network"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/secrets"
+)
+
+// Feed Auth Handler
+// feedauth <feed_url>                          shows the credentials, redacted
+// feedauth <feed_url> basic <username> <password>
+// feedauth <feed_url> bearer <token>
+// feedauth <feed_url> header <name> <value>
+// feedauth <feed_url> query <name> <value>
+// feedauth <feed_url> clear
+func FeedAuthHandler(s *State, cmd Command, user database.User) error {
+	// early exit with error if command arguments are empty
+	if len(cmd.Arguments) == 0 {
+		return fmt.Errorf("you need to provide the feed url to set credentials for")
+	}
+
+	feed, err := s.Db.GetFeedByUrl(context.Background(), cmd.Arguments[0])
+	if err != nil {
+		return fmt.Errorf("error fetching feed: %w", err)
+	}
+
+	// Only the user who added the feed can see or change its credentials
+	if !feed.UserID.Valid || feed.UserID.UUID != user.ID {
+		return fmt.Errorf("only the user who added the feed can manage its credentials")
+	}
+
+	auth, err := loadFeedAuth(s, feed)
+	if err != nil {
+		return err
+	}
+
+	// Show the current credentials
+	if len(cmd.Arguments) == 1 {
+		fmt.Printf("Credentials for %v: %v\n", feed.Name, auth)
+		return nil
+	}
+
+	args := cmd.Arguments[2:]
+	switch cmd.Arguments[1] {
+	case "basic":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: feedauth <feed_url> basic <username> <password>")
+		}
+		auth.Username = args[0]
+		auth.Password = args[1]
+	case "bearer":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: feedauth <feed_url> bearer <token>")
+		}
+		auth.BearerToken = args[0]
+	case "header":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: feedauth <feed_url> header <name> <value>")
+		}
+		if auth.Headers == nil {
+			auth.Headers = make(map[string]string)
+		}
+		auth.Headers[args[0]] = args[1]
+	case "query":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: feedauth <feed_url> query <name> <value>")
+		}
+		if auth.Query == nil {
+			auth.Query = make(map[string]string)
+		}
+		auth.Query[args[0]] = args[1]
+	case "clear":
+		auth = &network.FeedAuth{}
+	default:
+		return fmt.Errorf("unknown credential type %v, expected basic, bearer, header, query or clear", cmd.Arguments[1])
+	}
+
+	if err := saveFeedAuth(s, feed, auth); err != nil {
+		return err
+	}
+
+	fmt.Printf("Credentials for %v: %v\n", feed.Name, auth)
+	return nil
+}
+
+// Decrypt the credentials stored for the feed. Feeds without credentials return an empty FeedAuth.
+func loadFeedAuth(s *State, feed database.Feed) (*network.FeedAuth, error) {
+	auth := &network.FeedAuth{}
+	if len(feed.AuthSettings) == 0 {
+		return auth, nil
+	}
+
+	key, err := secrets.ParseKey(s.Config.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("error loading credentials for %v: %w", feed.Name, err)
+	}
+
+	plaintext, err := secrets.Decrypt(key, feed.AuthSettings)
+	if err != nil {
+		return nil, fmt.Errorf("error loading credentials for %v: %w", feed.Name, err)
+	}
+
+	if err := json.Unmarshal(plaintext, auth); err != nil {
+		return nil, fmt.Errorf("error parsing credentials for %v: %w", feed.Name, err)
+	}
+
+	return auth, nil
+}
+
+// Encrypt and store the credentials for the feed. Empty credentials clear the column.
+func saveFeedAuth(s *State, feed database.Feed, auth *network.FeedAuth) error {
+	var encrypted []byte
+	if !auth.IsEmpty() {
+		key, err := secrets.ParseKey(s.Config.EncryptionKey)
+		if err != nil {
+			return fmt.Errorf("encryption_key must be set in config to store credentials: %w", err)
+		}
+
+		plaintext, err := json.Marshal(auth)
+		if err != nil {
+			return fmt.Errorf("error marshalling credentials: %w", err)
+		}
+
+		encrypted, err = secrets.Encrypt(key, plaintext)
+		if err != nil {
+			return fmt.Errorf("error encrypting credentials: %w", err)
+		}
+	}
+
+	params := database.UpdateFeedAuthSettingsParams{
+		AuthSettings: encrypted,
+		UpdatedAt:    time.Now(),
+		ID:           feed.ID,
+	}
+	if err := s.Db.UpdateFeedAuthSettings(context.Background(), params); err != nil {
+		return fmt.Errorf("error saving credentials: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Feed Handler
+// feed rename <feed> <new name>
+// feed set-url <feed> <new url>
+// feed delete <feed> [--force] [--yes] [--dry-run]
+// feed transfer <feed> <username>
+// Feeds are given by url or by name. Only the user who added a feed or an admin can change it.
+func FeedHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	force := fs.Bool("force", false, "delete the feed even though others follow it or saved its posts")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	dryRun := fs.Bool("dry-run", false, "print what would be deleted without deleting it")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(args) < 2 {
+		return fmt.Errorf("usage: feed rename|set-url|delete|transfer <feed> ...")
+	}
+
+	feed, err := findFeed(s, args[1])
+	if err != nil {
+		return err
+	}
+	if err := requireFeedOwner(user, feed); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "rename":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: feed rename <feed> <new name>")
+		}
+		return renameFeed(s, feed, strings.Join(args[2:], " "))
+	case "set-url":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: feed set-url <feed> <new url>")
+		}
+		return setFeedURL(s, feed, args[2])
+	case "delete":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: feed delete <feed> [--force] [--yes] [--dry-run]")
+		}
+		return deleteFeed(s, user, feed, *force, *yes, *dryRun)
+	case "transfer":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: feed transfer <feed> <username>")
+		}
+		return transferFeed(s, feed, args[2])
+	default:
+		return fmt.Errorf("unknown subcommand %v, expected rename, set-url, delete or transfer", args[0])
+	}
+}
+
+// Find a feed by its url, or by its name when only one feed has it
+func findFeed(s *State, ref string) (database.Feed, error) {
+	feed, err := s.Db.GetFeedByUrl(context.Background(), ref)
+	if err == nil {
+		return feed, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return database.Feed{}, fmt.Errorf("error fetching feed: %w", err)
+	}
+
+	feeds, err := s.Db.GetFeedsWithUsername(context.Background())
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("error fetching feeds: %w", err)
+	}
+	var matches []database.GetFeedsWithUsernameRow
+	for _, row := range feeds {
+		if strings.EqualFold(row.Name, ref) {
+			matches = append(matches, row)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return database.Feed{}, fmt.Errorf("no feed with the url or name %v", ref)
+	case 1:
+		return s.Db.GetFeedByUrl(context.Background(), matches[0].Url)
+	default:
+		return database.Feed{}, fmt.Errorf("several feeds are named %v, use the url instead", ref)
+	}
+}
+
+// Only the user who added a feed and admins may change it. Feeds whose owner
+// was deleted can only be changed by admins.
+func requireFeedOwner(user database.User, feed database.Feed) error {
+	if user.Role == constants.ROLE_ADMIN {
+		return nil
+	}
+	if !feed.UserID.Valid || feed.UserID.UUID != user.ID {
+		return fmt.Errorf("only the user who added %v or an admin can change it", feed.Name)
+	}
+	return nil
+}
+
+// Change a feed's name
+func renameFeed(s *State, feed database.Feed, name string) error {
+	if err := s.Db.RenameFeed(context.Background(), database.RenameFeedParams{
+		Name:      name,
+		UpdatedAt: time.Now(),
+		ID:        feed.ID,
+	}); err != nil {
+		return fmt.Errorf("error renaming feed: %w", err)
+	}
+
+	fmt.Printf("Renamed %v to %v\n", feed.Name, name)
+	return nil
+}
+
+// Point a feed at a new url. Fetch state belongs to the old url, so it is
+// reset, and any WebSub subscription for the old url is cancelled.
+func setFeedURL(s *State, feed database.Feed, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid feed url %v, expected an http or https url", rawURL)
+	}
+
+	err = s.Db.UpdateFeedUrl(context.Background(), database.UpdateFeedUrlParams{
+		Url:       rawURL,
+		UpdatedAt: time.Now(),
+		ID:        feed.ID,
+	})
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == constants.ERR_CODE_UNIQUE_CONSTRAINT_VIOLATION {
+		return fmt.Errorf("another feed already uses %v", rawURL)
+	}
+	if err != nil {
+		return fmt.Errorf("error updating feed url: %w", err)
+	}
+
+	if err := unsubscribeFeedFromHub(s, feed); err != nil {
+		return err
+	}
+
+	fmt.Printf("%v now fetches %v\n", feed.Name, rawURL)
+	return nil
+}
+
+// Delete a feed with its posts. Feeds others follow, or whose posts others
+// saved, are only deleted with --force. Saved posts are never removed with
+// their post, so they are deleted explicitly here once the user agreed.
+func deleteFeed(s *State, user database.User, feed database.Feed, force bool, yes bool, dryRun bool) error {
+	followers, err := s.Db.GetFollowerNamesForFeed(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching followers: %w", err)
+	}
+	savers, err := s.Db.GetSaverNamesForFeed(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching users who saved posts: %w", err)
+	}
+
+	posts, err := s.Db.CountPostsForFeed(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("error counting posts: %w", err)
+	}
+	saved, err := s.Db.CountSavedPostsForFeed(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("error counting saved posts: %w", err)
+	}
+	fmt.Printf("Deleting %v removes:\n", feed.Name)
+	printRowCounts([]rowCount{
+		{"feeds", 1},
+		{"posts", posts},
+		{"feed_follows", int64(len(followers))},
+		{"saved_posts", saved},
+	})
+
+	othersFollowing := otherNames(followers, user.Name)
+	othersSaving := otherNames(savers, user.Name)
+	if len(othersFollowing) > 0 {
+		fmt.Printf("Warning: %v also follow %v\n", strings.Join(othersFollowing, ", "), feed.Name)
+	}
+	if len(othersSaving) > 0 {
+		fmt.Printf("Warning: %v saved posts of %v\n", strings.Join(othersSaving, ", "), feed.Name)
+	}
+	if (len(othersFollowing) > 0 || len(othersSaving) > 0) && !force && !dryRun {
+		return fmt.Errorf("others follow %v or saved its posts, pass --force to delete it anyway", feed.Name)
+	}
+	if dryRun {
+		return nil
+	}
+
+	if err := confirmAction(fmt.Sprintf("Delete feed %v?", feed.Name), yes); err != nil {
+		return err
+	}
+	if err := backupDatabase(s, "feed-delete-"+feed.Name); err != nil {
+		return err
+	}
+
+	if err := unsubscribeFeedFromHub(s, feed); err != nil {
+		return err
+	}
+
+	if err := s.Db.DeleteSavedPostsForFeed(context.Background(), feed.ID); err != nil {
+		return fmt.Errorf("error deleting saved posts: %w", err)
+	}
+	if err := s.Db.DeleteFeed(context.Background(), feed.ID); err != nil {
+		return fmt.Errorf("error deleting feed: %w", err)
+	}
+
+	fmt.Printf("Deleted feed %v\n", feed.Name)
+	return nil
+}
+
+// Names other than the user's own
+func otherNames(names []string, own string) []string {
+	var others []string
+	for _, name := range names {
+		if name != own {
+			others = append(others, name)
+		}
+	}
+	return others
+}
+
+// Cancel the feed's WebSub subscription, if it has one
+func unsubscribeFeedFromHub(s *State, feed database.Feed) error {
+	subscription, err := s.Db.GetWebsubSubscriptionByFeed(context.Background(), feed.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching websub subscription: %w", err)
+	}
+	return unsubscribeFromHub(s, subscription)
+}
+
+// Make another user the owner of a feed
+func transferFeed(s *State, feed database.Feed, username string) error {
+	owner, err := s.Db.GetUser(context.Background(), username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %v not found", username)
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching user: %w", err)
+	}
+
+	if err := s.Db.SetFeedOwner(context.Background(), database.SetFeedOwnerParams{
+		UserID:    uuid.NullUUID{UUID: owner.ID, Valid: true},
+		UpdatedAt: time.Now(),
+		ID:        feed.ID,
+	}); err != nil {
+		return fmt.Errorf("error transferring feed: %w", err)
+	}
+
+	fmt.Printf("%v now belongs to %v\n", feed.Name, owner.Name)
+	return nil
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Run feed delete as the user
+func deleteFeedCommand(t *testing.T, s *State, user database.User, args ...string) error {
+	t.Helper()
+	_, err := captureStdout(t, func() error {
+		return FeedHandler(s, Command{Name: "feed", Arguments: append([]string{"delete"}, args...)}, user)
+	})
+	return err
+}
+
+func TestFeedDeleteKeepsOthersSavedPostsWithoutForce(t *testing.T) {
+	s := newTestState(t)
+	s.Config.BackupDir = t.TempDir()
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	feed := createTestFeed(t, s, alice, "Blog", "https://blog.example.com/feed", "First", "Second")
+
+	if _, err := captureStdout(t, func() error {
+		return StarHandler(s, Command{Name: "star", Arguments: []string{"https://blog.example.com/feed/0"}}, bob)
+	}); err != nil {
+		t.Fatalf("star: %v", err)
+	}
+
+	err := deleteFeedCommand(t, s, alice, "Blog", "--yes")
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("feed delete with another user's star returned %v, want a --force error", err)
+	}
+	starred, err := s.Db.GetStarredPostsForUser(context.Background(), bob.ID)
+	if err != nil || len(starred) != 1 {
+		t.Fatalf("bob has %v starred posts (%v), want 1", len(starred), err)
+	}
+
+	// The star still protects the post from being deleted on its own
+	if err := s.Db.DeleteFeed(context.Background(), feed.ID); err == nil {
+		t.Fatal("deleting the feed in the database removed a saved post")
+	}
+
+	if err := deleteFeedCommand(t, s, alice, "Blog", "--yes", "--force"); err != nil {
+		t.Fatalf("feed delete --force: %v", err)
+	}
+	counts, err := s.Db.GetTableCounts(context.Background())
+	if err != nil {
+		t.Fatalf("error counting rows: %v", err)
+	}
+	if counts.Feeds != 0 || counts.Posts != 0 || counts.SavedPosts != 0 {
+		t.Errorf("after feed delete --force %v feeds, %v posts and %v saved posts are left, want none", counts.Feeds, counts.Posts, counts.SavedPosts)
+	}
+}
+
+// Run a feed subcommand as the user
+func feedCommand(t *testing.T, s *State, user database.User, args ...string) error {
+	t.Helper()
+	_, err := captureStdout(t, func() error {
+		return FeedHandler(s, Command{Name: "feed", Arguments: args}, user)
+	})
+	return err
+}
+
+func TestFeedChangesNeedTheOwner(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	createTestFeed(t, s, alice, "Blog", "https://blog.example.com/feed", "First")
+	createTestFeed(t, s, bob, "Other", "https://other.example.com/feed")
+
+	if err := feedCommand(t, s, bob, "rename", "Blog", "Mine"); err == nil {
+		t.Error("bob renamed alice's feed")
+	}
+	if err := feedCommand(t, s, alice, "rename", "Blog", "Alice's Blog"); err != nil {
+		t.Fatalf("feed rename: %v", err)
+	}
+
+	for _, rawURL := range []string{"ftp://blog.example.com/feed", "https://other.example.com/feed"} {
+		if err := feedCommand(t, s, alice, "set-url", "Alice's Blog", rawURL); err == nil {
+			t.Errorf("feed set-url %v was accepted", rawURL)
+		}
+	}
+	if err := feedCommand(t, s, alice, "set-url", "Alice's Blog", "https://blog.example.com/atom"); err != nil {
+		t.Fatalf("feed set-url: %v", err)
+	}
+	feed, err := s.Db.GetFeedByUrl(context.Background(), "https://blog.example.com/atom")
+	if err != nil || feed.Name != "Alice's Blog" {
+		t.Fatalf("feed after rename and set-url = %+v, %v", feed, err)
+	}
+
+	// After a transfer only the new owner may change it
+	if err := feedCommand(t, s, alice, "transfer", feed.Url, "bob"); err != nil {
+		t.Fatalf("feed transfer: %v", err)
+	}
+	if err := feedCommand(t, s, alice, "rename", feed.Url, "Still mine"); err == nil {
+		t.Error("alice renamed a feed after transferring it")
+	}
+	if err := feedCommand(t, s, bob, "rename", feed.Url, "Bob's Blog"); err != nil {
+		t.Errorf("the new owner couldn't rename the feed: %v", err)
+	}
+}
+
+func TestDeletingTheOwnerKeepsTheirFeeds(t *testing.T) {
+	s := newTestState(t)
+	s.Config.BackupDir = t.TempDir()
+	admin := makeTestAdmin(t, s, createTestUser(t, s, "admin"))
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	feed := createTestFeed(t, s, alice, "Blog", "https://blog.example.com/feed", "First")
+	runAs(t, s, bob, FollowHandler, "follow", feed.Url)
+
+	runAs(t, s, admin, UserHandler, "user", "delete", "alice", "--yes")
+
+	kept, err := s.Db.GetFeedByUrl(context.Background(), feed.Url)
+	if err != nil {
+		t.Fatalf("the feed went with its owner: %v", err)
+	}
+	if kept.UserID.Valid {
+		t.Errorf("the feed still names its deleted owner %v", kept.UserID.UUID)
+	}
+	expectTitles(t, browseTitles(t, s, bob), []string{"First"}, nil)
+
+	// Ownerless feeds are left to admins
+	if err := feedCommand(t, s, bob, "rename", feed.Url, "Bob's Blog"); err == nil {
+		t.Error("a member renamed a feed without an owner")
+	}
+	if err := feedCommand(t, s, admin, "transfer", feed.Url, "bob"); err != nil {
+		t.Fatalf("feed transfer by an admin: %v", err)
+	}
+	if err := feedCommand(t, s, bob, "rename", feed.Url, "Bob's Blog"); err != nil {
+		t.Errorf("the new owner couldn't rename the transferred feed: %v", err)
+	}
+}
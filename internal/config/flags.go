@@ -0,0 +1,59 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Create a flag set for a command. Usage output is left to the caller.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// Parse flags which may appear before, between or after positional arguments.
+// Returns the positional arguments in order.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("error parsing %v flags: %w", fs.Name(), err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// Parse a date given on the command line, e.g. "2024-01-31" or an RFC3339 timestamp
+func parseFlagDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	formats := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+	}
+	for _, format := range formats {
+		if parsed, err := time.ParseInLocation(format, value, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %v, expected e.g. 2006-01-02 or 2006-01-02T15:04:05Z", value)
+}
+
+// Reject --limit values below 1, which Postgres refuses or which return nothing
+func checkFlagLimit(limit int) error {
+	if limit < 1 {
+		return fmt.Errorf("--limit must be at least 1, got %v", limit)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		args       []string
+		positional []string
+		all        bool
+		before     string
+	}{
+		{nil, nil, false, ""},
+		{[]string{"10"}, []string{"10"}, false, ""},
+		{[]string{"--all", "10"}, []string{"10"}, true, ""},
+		{[]string{"10", "--all"}, []string{"10"}, true, ""},
+		{[]string{"a", "--before", "2024-01-31", "b"}, []string{"a", "b"}, false, "2024-01-31"},
+		{[]string{"-all=true", "a", "-before=x"}, []string{"a"}, true, "x"},
+		// Everything after -- is positional
+		{[]string{"--", "--all"}, []string{"--all"}, false, ""},
+	}
+	for _, test := range tests {
+		fs := newFlagSet("test")
+		all := fs.Bool("all", false, "")
+		before := fs.String("before", "", "")
+		positional, err := parseFlags(fs, test.args)
+		if err != nil {
+			t.Fatalf("parseFlags(%q): %v", test.args, err)
+		}
+		if !slices.Equal(positional, test.positional) || *all != test.all || *before != test.before {
+			t.Errorf("parseFlags(%q) = %q, all %v, before %q, want %q, all %v, before %q",
+				test.args, positional, *all, *before, test.positional, test.all, test.before)
+		}
+	}
+
+	for _, args := range [][]string{{"--unknown"}, {"a", "--before"}, {"--all=maybe"}} {
+		fs := newFlagSet("test")
+		fs.Bool("all", false, "")
+		fs.String("before", "", "")
+		if _, err := parseFlags(fs, args); err == nil {
+			t.Errorf("parseFlags(%q) was accepted", args)
+		}
+	}
+}
+
+func TestParseFlagDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2024-01-31", time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local)},
+		{" 2024-01-31 ", time.Date(2024, 1, 31, 0, 0, 0, 0, time.Local)},
+		{"2024-01-31 08:30", time.Date(2024, 1, 31, 8, 30, 0, 0, time.Local)},
+		{"2024-01-31 08:30:15", time.Date(2024, 1, 31, 8, 30, 15, 0, time.Local)},
+		{"2024-01-31T08:30:15", time.Date(2024, 1, 31, 8, 30, 15, 0, time.Local)},
+		{"2024-01-31T08:30:15Z", time.Date(2024, 1, 31, 8, 30, 15, 0, time.UTC)},
+		{"2024-01-31T08:30:15+02:00", time.Date(2024, 1, 31, 6, 30, 15, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		got, err := parseFlagDate(test.value)
+		if err != nil {
+			t.Errorf("parseFlagDate(%q): %v", test.value, err)
+			continue
+		}
+		if !got.Equal(test.want) {
+			t.Errorf("parseFlagDate(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+
+	for _, value := range []string{"", "yesterday", "31/01/2024", "2024-13-01", "2024-01-31T25:00:00"} {
+		if _, err := parseFlagDate(value); err == nil {
+			t.Errorf("parseFlagDate(%q) was accepted", value)
+		}
+	}
+}
+
+func TestCheckFlagLimit(t *testing.T) {
+	tests := []struct {
+		limit int
+		ok    bool
+	}{
+		{-1, false},
+		{0, false},
+		{1, true},
+		{500, true},
+	}
+	for _, test := range tests {
+		if err := checkFlagLimit(test.limit); (err == nil) != test.ok {
+			t.Errorf("checkFlagLimit(%v) = %v, want ok %v", test.limit, err, test.ok)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Folder Handler
+// folder add <name> [--parent <name>]
+// folder rename <name> <new name>
+// folder remove <name>
+// folder assign <feed> <folder>
+// folder unassign <feed>
+// Folders nest one level deep. Removing a folder removes its subfolders and leaves their feeds unfiled.
+func FolderHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	parent := fs.String("parent", "", "folder to create the new folder in")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("usage: folder add|rename|remove|assign|unassign")
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: folder add <name> [--parent <name>]")
+		}
+		return addFolder(s, user, strings.Join(args[1:], " "), *parent)
+	case "rename":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: folder rename <name> <new name>")
+		}
+		return renameFolder(s, user, args[1], args[2])
+	case "remove":
+		if len(args) < 2 {
+			return fmt.Errorf("usage: folder remove <name>")
+		}
+		return removeFolder(s, user, strings.Join(args[1:], " "))
+	case "assign":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: folder assign <feed> <folder>")
+		}
+		folder, err := findFolder(s, user, strings.Join(args[2:], " "))
+		if err != nil {
+			return err
+		}
+		return assignFolder(s, user, args[1], uuid.NullUUID{UUID: folder.ID, Valid: true}, folder.Name)
+	case "unassign":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: folder unassign <feed>")
+		}
+		return assignFolder(s, user, args[1], uuid.NullUUID{}, "")
+	default:
+		return fmt.Errorf("unknown subcommand %v, expected add, rename, remove, assign or unassign", args[0])
+	}
+}
+
+// Create a folder for the user, optionally inside a top level folder
+func addFolder(s *State, user database.User, name string, parentName string) error {
+	params := database.CreateFolderParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      name,
+	}
+
+	if parentName != "" {
+		parent, err := findFolder(s, user, parentName)
+		if err != nil {
+			return err
+		}
+		if parent.ParentID.Valid {
+			return fmt.Errorf("folder %v is already a subfolder, folders only nest one level", parent.Name)
+		}
+		params.ParentID = uuid.NullUUID{UUID: parent.ID, Valid: true}
+	}
+
+	folder, err := s.Db.CreateFolder(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error creating folder: %w", err)
+	}
+
+	fmt.Printf("Created folder %v\n", folder.Name)
+	return nil
+}
+
+// Rename a folder of the user
+func renameFolder(s *State, user database.User, name string, newName string) error {
+	folder, err := findFolder(s, user, name)
+	if err != nil {
+		return err
+	}
+
+	params := database.RenameFolderParams{
+		Name:      newName,
+		UpdatedAt: time.Now(),
+		ID:        folder.ID,
+	}
+	if err := s.Db.RenameFolder(context.Background(), params); err != nil {
+		return fmt.Errorf("error renaming folder: %w", err)
+	}
+
+	fmt.Printf("Renamed folder %v to %v\n", name, newName)
+	return nil
+}
+
+// Delete a folder of the user
+func removeFolder(s *State, user database.User, name string) error {
+	folder, err := findFolder(s, user, name)
+	if err != nil {
+		return err
+	}
+
+	if err := s.Db.DeleteFolder(context.Background(), folder.ID); err != nil {
+		return fmt.Errorf("error deleting folder: %w", err)
+	}
+
+	fmt.Printf("Removed folder %v\n", folder.Name)
+	return nil
+}
+
+// Move a followed feed into a folder, or out of every folder when folderID is null
+func assignFolder(s *State, user database.User, feedRef string, folderID uuid.NullUUID, folderName string) error {
+	feedID, err := findFollowedFeed(s, user, feedRef)
+	if err != nil {
+		return err
+	}
+
+	params := database.SetFeedFollowFolderParams{
+		FolderID:  folderID,
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feedID,
+	}
+	if _, err := s.Db.SetFeedFollowFolder(context.Background(), params); err != nil {
+		return fmt.Errorf("error moving feed: %w", err)
+	}
+
+	if folderID.Valid {
+		fmt.Printf("Moved %v to %v\n", feedRef, folderName)
+	} else {
+		fmt.Printf("Moved %v out of its folder\n", feedRef)
+	}
+	return nil
+}
+
+// Get a folder of the user by name
+func findFolder(s *State, user database.User, name string) (database.Folder, error) {
+	params := database.GetFolderByNameParams{
+		UserID: user.ID,
+		Name:   name,
+	}
+	folder, err := s.Db.GetFolderByName(context.Background(), params)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Folder{}, inputError{fmt.Errorf("no folder named %v", name)}
+	}
+	if err != nil {
+		return database.Folder{}, fmt.Errorf("error getting folder: %w", err)
+	}
+	return folder, nil
+}
+
+// Print the followed feeds grouped by folder, unfiled feeds first
+func printFollowingByFolder(folders []database.Folder, feedFollows []database.GetFeedFollowsForUserRow) {
+	followsByFolder := map[uuid.UUID][]database.GetFeedFollowsForUserRow{}
+	unreadByFolder := map[uuid.UUID]int64{}
+	for _, feedFollow := range feedFollows {
+		if !feedFollow.FolderID.Valid {
+			fmt.Printf("  * %v\n", followingLine(feedFollow))
+			continue
+		}
+		followsByFolder[feedFollow.FolderID.UUID] = append(followsByFolder[feedFollow.FolderID.UUID], feedFollow)
+		unreadByFolder[feedFollow.FolderID.UUID] += feedFollow.UnreadCount
+	}
+
+	// A top level folder also counts the unread posts of its subfolders
+	children := map[uuid.UUID][]database.Folder{}
+	for _, folder := range folders {
+		if folder.ParentID.Valid {
+			children[folder.ParentID.UUID] = append(children[folder.ParentID.UUID], folder)
+		}
+	}
+
+	for _, folder := range folders {
+		if folder.ParentID.Valid {
+			continue
+		}
+		unread := unreadByFolder[folder.ID]
+		for _, child := range children[folder.ID] {
+			unread += unreadByFolder[child.ID]
+		}
+
+		fmt.Printf("%v (%v unread)\n", folder.Name, unread)
+		for _, feedFollow := range followsByFolder[folder.ID] {
+			fmt.Printf("  * %v\n", followingLine(feedFollow))
+		}
+		for _, child := range children[folder.ID] {
+			fmt.Printf("  %v (%v unread)\n", child.Name, unreadByFolder[child.ID])
+			for _, feedFollow := range followsByFolder[child.ID] {
+				fmt.Printf("    * %v\n", followingLine(feedFollow))
+			}
+		}
+	}
+}
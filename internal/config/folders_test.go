@@ -0,0 +1,77 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFoldersGroupFollowsAndScopeBrowse(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	goFeed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed", "Go post one", "Go post two")
+	rustFeed := createTestFeed(t, s, alice, "Rust Blog", "https://rust.example.com/feed", "Rust post one")
+	newsFeed := createTestFeed(t, s, alice, "News", "https://news.example.com/feed", "News post one")
+	for _, feed := range []string{goFeed.Url, rustFeed.Url, newsFeed.Url} {
+		runAs(t, s, alice, FollowHandler, "follow", feed)
+	}
+
+	runAs(t, s, alice, FolderHandler, "folder", "add", "Tech")
+	runAs(t, s, alice, FolderHandler, "folder", "add", "Languages", "--parent", "Tech")
+	runAs(t, s, alice, FolderHandler, "folder", "assign", goFeed.Url, "Languages")
+	runAs(t, s, alice, FolderHandler, "folder", "assign", rustFeed.Url, "Tech")
+
+	// Folders nest one level
+	if _, err := captureStdout(t, func() error {
+		return FolderHandler(s, Command{Name: "folder", Arguments: []string{"add", "Go", "--parent", "Languages"}}, alice)
+	}); err == nil {
+		t.Error("a folder was created inside a subfolder")
+	}
+
+	// A top level folder counts the unread posts of its subfolders
+	following := runAs(t, s, alice, FollowingHandler, "following")
+	for _, line := range []string{"Tech (3 unread)", "  Languages (2 unread)"} {
+		if !strings.Contains(following, line+"\n") {
+			t.Errorf("following should contain %q, got:\n%v", line, following)
+		}
+	}
+
+	expectTitles(t, browseTitles(t, s, alice, "--folder", "Tech"),
+		[]string{"Go post one", "Go post two", "Rust post one"}, []string{"News post one"})
+	expectTitles(t, browseTitles(t, s, alice, "--folder", "Languages"),
+		[]string{"Go post one", "Go post two"}, []string{"Rust post one", "News post one"})
+
+	runAs(t, s, alice, FolderHandler, "folder", "rename", "Languages", "Code")
+	expectTitles(t, browseTitles(t, s, alice, "--folder", "Code"), []string{"Go post one"}, []string{"Rust post one"})
+
+	// Removing a folder removes its subfolders and leaves their feeds followed but unfiled
+	runAs(t, s, alice, FolderHandler, "folder", "remove", "Tech")
+	if _, err := captureStdout(t, func() error {
+		return BrowseHandler(s, Command{Name: "browse", Arguments: []string{"--folder", "Code"}}, alice)
+	}); err == nil {
+		t.Error("browse --folder found the removed subfolder")
+	}
+	following = runAs(t, s, alice, FollowingHandler, "following")
+	for _, line := range []string{"  * Go Blog (2 unread)", "  * Rust Blog (1 unread)", "  * News (1 unread)"} {
+		if !strings.Contains(following, "\n"+line+"\n") {
+			t.Errorf("following should list %q unfiled, got:\n%v", line, following)
+		}
+	}
+	if strings.Contains(following, "Tech (") || strings.Contains(following, "Code (") {
+		t.Errorf("following still shows the removed folders:\n%v", following)
+	}
+	expectTitles(t, browseTitles(t, s, alice), []string{"Go post one", "Rust post one", "News post one"}, nil)
+}
+
+func TestFoldersArePerUser(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	runAs(t, s, alice, FolderHandler, "folder", "add", "Tech")
+
+	if _, err := captureStdout(t, func() error {
+		return FolderHandler(s, Command{Name: "folder", Arguments: []string{"remove", "Tech"}}, bob)
+	}); err == nil {
+		t.Error("bob removed alice's folder")
+	}
+	runAs(t, s, bob, FolderHandler, "folder", "add", "Tech")
+}
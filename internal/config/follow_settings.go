@@ -0,0 +1,124 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Change the overrides of a followed feed
+// follow edit <feed> [--name <name>] [--priority <n>] [--notify on|off] [--match <regex>]
+// An empty --name or --match clears the override.
+func editFeedFollow(s *State, user database.User, args []string) error {
+	fs := newFlagSet("follow edit")
+	name := fs.String("name", "", "display name of the feed")
+	priority := fs.Int("priority", 0, "feeds with a higher priority are listed first")
+	notify := fs.String("notify", "on", "on or off, whether saved searches alert on the feed")
+	match := fs.String("match", "", "only show posts whose title or description match this regex")
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("usage: follow edit <feed> [--name <name>] [--priority <n>] [--notify on|off] [--match <regex>]")
+	}
+
+	feedID, err := findFollowedFeed(s, user, args[0])
+	if err != nil {
+		return err
+	}
+	feedFollows, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting feed follows: %w", err)
+	}
+	var current database.GetFeedFollowsForUserRow
+	for _, feedFollow := range feedFollows {
+		if feedFollow.FeedID == feedID {
+			current = feedFollow
+		}
+	}
+
+	// Only the flags given on the command line change
+	params := database.UpdateFeedFollowSettingsParams{
+		DisplayName: current.DisplayName,
+		Priority:    current.Priority,
+		Notify:      current.Notify,
+		MatchFilter: current.MatchFilter,
+		UpdatedAt:   time.Now(),
+		UserID:      user.ID,
+		FeedID:      feedID,
+	}
+	var visitErr error
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "name":
+			params.DisplayName = sql.NullString{String: *name, Valid: *name != ""}
+		case "priority":
+			params.Priority = int32(*priority)
+		case "notify":
+			switch strings.ToLower(*notify) {
+			case "on":
+				params.Notify = true
+			case "off":
+				params.Notify = false
+			default:
+				visitErr = fmt.Errorf("unknown --notify %v, expected on or off", *notify)
+			}
+		case "match":
+			if *match != "" {
+				if err := validateMatchFilter(s, *match); err != nil {
+					visitErr = err
+				}
+			}
+			params.MatchFilter = sql.NullString{String: *match, Valid: *match != ""}
+		}
+	})
+	if visitErr != nil {
+		return visitErr
+	}
+
+	if _, err := s.Db.UpdateFeedFollowSettings(context.Background(), params); err != nil {
+		return fmt.Errorf("error updating feed follow: %w", err)
+	}
+
+	// Read the follow back, a cleared name falls back to the feed's own name
+	feedFollows, err = s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting feed follows: %w", err)
+	}
+	for _, feedFollow := range feedFollows {
+		if feedFollow.FeedID == feedID {
+			fmt.Printf("Updated %v\n", followingLine(feedFollow))
+		}
+	}
+	return nil
+}
+
+// Check the "show only" regex of a follow, which Postgres evaluates like a regex mute filter
+func validateMatchFilter(s *State, pattern string) error {
+	if _, err := s.Db.ValidateRegex(context.Background(), pattern); err != nil {
+		return fmt.Errorf("invalid regex %v: %w", pattern, err)
+	}
+	return nil
+}
+
+// Describe a followed feed with its unread count and overrides
+func followingLine(feedFollow database.GetFeedFollowsForUserRow) string {
+	line := fmt.Sprintf("%v (%v unread)", feedFollow.FeedName, feedFollow.UnreadCount)
+	if feedFollow.Priority != 0 {
+		line += fmt.Sprintf(" [priority %v]", feedFollow.Priority)
+	}
+	if !feedFollow.Notify {
+		line += " [notifications off]"
+	}
+	if feedFollow.MatchFilter.Valid {
+		line += fmt.Sprintf(" [only %v]", feedFollow.MatchFilter.String)
+	}
+	return line
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+func TestFollowEditChangesBrowse(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	goFeed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed", "Go 1.24 released", "Go generics tips", "Go team offsite photos")
+	rustFeed := createTestFeed(t, s, alice, "Rust Blog", "https://rust.example.com/feed", "Rust 2024 edition")
+	runAs(t, s, alice, FollowHandler, "follow", goFeed.Url)
+	runAs(t, s, alice, FollowHandler, "follow", rustFeed.Url)
+
+	runAs(t, s, alice, FollowHandler, "follow", "edit", goFeed.Url, "--name", "Golang", "--match", "released|generics")
+	output := browseTitles(t, s, alice)
+	expectTitles(t, output, []string{"Golang: Go 1.24 released", "Golang: Go generics tips"}, []string{"Go team offsite photos", "Go Blog"})
+
+	// Priority sorts ahead of newer posts from other feeds
+	runAs(t, s, alice, FollowHandler, "follow", "edit", "Rust Blog", "--priority", "5")
+	output = browseTitles(t, s, alice, "--sort", "priority")
+	if rust, golang := strings.Index(output, "Rust 2024 edition"), strings.Index(output, "Go 1.24 released"); rust < 0 || golang < 0 || rust > golang {
+		t.Errorf("browse --sort priority should list the Rust post first, got:\n%v", output)
+	}
+
+	// Only the given flags change, and empty values clear the overrides
+	runAs(t, s, alice, FollowHandler, "follow", "edit", "Golang", "--match", "")
+	following := runAs(t, s, alice, FollowingHandler, "following")
+	for _, line := range []string{"* Golang (3 unread)\n", "* Rust Blog (1 unread) [priority 5]\n"} {
+		if !strings.Contains(following, line) {
+			t.Errorf("following should contain %q, got:\n%v", line, following)
+		}
+	}
+	output = runAs(t, s, alice, FollowHandler, "follow", "edit", "Golang", "--name", "")
+	if !strings.Contains(output, "Updated Go Blog (3 unread)") {
+		t.Errorf("clearing the name should print the feed's own name, got:\n%v", output)
+	}
+	expectTitles(t, browseTitles(t, s, alice), []string{"Go Blog: Go team offsite photos"}, []string{"Golang"})
+
+	if _, err := captureStdout(t, func() error {
+		return FollowHandler(s, Command{Name: "follow", Arguments: []string{"edit", goFeed.Url, "--match", "("}}, alice)
+	}); err == nil {
+		t.Error("an invalid match regex was accepted")
+	}
+	// Postgres word boundaries aren't valid in Go's regex syntax but are accepted
+	runAs(t, s, alice, FollowHandler, "follow", "edit", goFeed.Url, "--match", `\mgenerics\M`)
+}
+
+func TestFollowNotifyOffSilencesAlerts(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed")
+	runAs(t, s, alice, FollowHandler, "follow", feed.Url)
+	runAs(t, s, alice, SavedSearchesHandler, "searches", "add", "generics", "generics")
+	runAs(t, s, alice, FollowHandler, "follow", "edit", feed.Url, "--notify", "off")
+
+	post := createTestPost(t, s, feed, database.CreatePostParams{Title: "Generics in practice"})
+	output, _ := captureStdout(t, func() error {
+		evaluateSavedSearches(s, []uuid.UUID{post.ID})
+		return nil
+	})
+	if strings.Contains(output, "Alert ") {
+		t.Errorf("a feed with notifications off alerted:\n%v", output)
+	}
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Built in post formats
+const FORMAT_COMPACT = "compact"
+const FORMAT_DETAILED = "detailed"
+
+// Width descriptions are wrapped at
+const WRAP_WIDTH = 76
+
+// Maximum length of a description excerpt
+const EXCERPT_LENGTH = 280
+
+// Post as exposed to the output formats, including user supplied templates,
+// e.g. --format '{{.Published}} {{.Title}} {{.Url}}'
+type PostView struct {
+	ID          uuid.UUID
+	ShortID     string
+	Title       string
+	Url         string
+	FeedName    string
+	PublishedAt time.Time
+	// Relative publication time, e.g. "3 hours ago"
+	Published string
+	// Description with html removed
+	Description string
+	// Shortened description
+	Excerpt string
+}
+
+// Create the view of a post
+func newPostView(id uuid.UUID, title string, url string, description string, publishedAt time.Time, feedName string) PostView {
+	text := stripHTML(description)
+	return PostView{
+		ID:          id,
+		ShortID:     shortPostID(id),
+		Title:       title,
+		Url:         url,
+		FeedName:    feedName,
+		PublishedAt: publishedAt,
+		Published:   relativeTime(publishedAt, time.Now()),
+		Description: text,
+		Excerpt:     excerpt(text, EXCERPT_LENGTH),
+	}
+}
+
+// Writes posts in one of the formats
+type postFormatter func(w io.Writer, post PostView) error
+
+// Get the formatter for a --format value. Anything other than the built in
+// formats is parsed as a text/template.
+func newPostFormatter(format string) (postFormatter, error) {
+	switch format {
+	case "", FORMAT_DETAILED:
+		return writeDetailedPost, nil
+	case FORMAT_COMPACT:
+		return writeCompactPost, nil
+	}
+
+	tmpl, err := template.New("post").Funcs(template.FuncMap{
+		"wrap": wrapText,
+		"date": func(t time.Time) string { return t.Format(time.DateTime) },
+	}).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing format template: %w", err)
+	}
+
+	return func(w io.Writer, post PostView) error {
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, post); err != nil {
+			return fmt.Errorf("error executing format template: %w", err)
+		}
+		output := sb.String()
+		if !strings.HasSuffix(output, "\n") {
+			output += "\n"
+		}
+		_, err := io.WriteString(w, output)
+		return err
+	}, nil
+}
+
+// One line per post
+func writeCompactPost(w io.Writer, post PostView) error {
+	_, err := fmt.Fprintf(w, " * [%v] %-14v %v: %v\n", post.ShortID, post.Published, post.FeedName, post.Title)
+	return err
+}
+
+// Title, feed, dates, link and excerpt of the post
+func writeDetailedPost(w io.Writer, post PostView) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " * [%v] %v\n", post.ShortID, post.Title)
+	fmt.Fprintf(&sb, "   %v | %v (%v)\n", post.FeedName, post.Published, post.PublishedAt.Local().Format("Mon, 02 Jan 2006 15:04"))
+	fmt.Fprintf(&sb, "   %v\n", post.Url)
+	if post.Excerpt != "" {
+		for _, line := range strings.Split(wrapText(post.Excerpt, WRAP_WIDTH), "\n") {
+			fmt.Fprintf(&sb, "   %v\n", line)
+		}
+	}
+	sb.WriteString("\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+var (
+	scriptPattern     = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	blockTagPattern   = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/h[1-6])[^>]*>`)
+	tagPattern        = regexp.MustCompile(`(?s)<[^>]*>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// Remove html tags and entities, collapsing whitespace
+func stripHTML(text string) string {
+	text = scriptPattern.ReplaceAllString(text, " ")
+	text = blockTagPattern.ReplaceAllString(text, " ")
+	text = tagPattern.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(text, " "))
+}
+
+// Shorten the text to at most max characters, cutting at a word boundary
+func excerpt(text string, max int) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:max])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "..."
+}
+
+// Wrap the text at word boundaries so that lines are at most width characters
+func wrapText(text string, width int) string {
+	var lines []string
+	var line strings.Builder
+	for _, word := range strings.Fields(text) {
+		if line.Len() > 0 && utf8.RuneCountInString(line.String())+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteString(" ")
+		}
+		line.WriteString(word)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Describe the time relative to now, e.g. "5 minutes ago"
+func relativeTime(t time.Time, now time.Time) string {
+	diff := now.Sub(t)
+	suffix := "ago"
+	if diff < 0 {
+		diff = -diff
+		suffix = "from now"
+	}
+
+	units := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		count := int(diff / unit.duration)
+		if count == 0 {
+			continue
+		}
+		if count == 1 {
+			return fmt.Sprintf("1 %v %v", unit.name, suffix)
+		}
+		return fmt.Sprintf("%v %vs %v", count, unit.name, suffix)
+	}
+	return "just now"
+}
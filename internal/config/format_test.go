@@ -0,0 +1,143 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		html string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"<p>One</p><p>Two</p>", "One Two"},
+		{"line<br>break<BR/>again", "line break again"},
+		{"<ul><li>a</li><li>b</li></ul>", "a b"},
+		{"<h1>Title</h1>Body", "Title Body"},
+		{`<b>bold</b> and <a href="x">link</a>`, "bold and link"},
+		{"before<script>alert('x')</script>after", "before after"},
+		{"<STYLE type=\"text/css\">p { color: red }\n</STYLE>text", "text"},
+		{"Tom &amp; Jerry &lt;3 &eacute;t&#233;", "Tom & Jerry <3 été"},
+		{"  lots \n\t of   space  ", "lots of space"},
+	}
+	for _, test := range tests {
+		if got := stripHTML(test.html); got != test.want {
+			t.Errorf("stripHTML(%q) = %q, want %q", test.html, got, test.want)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		text string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly ten", 11, "exactly ten"},
+		{"the quick brown fox", 12, "the quick..."},
+		{"one, two, three", 10, "one, two..."},
+		{"unbreakable", 5, "unbre..."},
+		// Cut by runes, not bytes
+		{"café crème brûlée", 13, "café crème..."},
+		{"日本語のテキスト", 4, "日本語の..."},
+	}
+	for _, test := range tests {
+		got := excerpt(test.text, test.max)
+		if got != test.want {
+			t.Errorf("excerpt(%q, %v) = %q, want %q", test.text, test.max, got, test.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("excerpt(%q, %v) cut a character in half: %q", test.text, test.max, got)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"", 10, ""},
+		{"one two three four", 9, "one two\nthree\nfour"},
+		{"one two three four", 100, "one two three four"},
+		{"averyveryverylongword fits", 5, "averyveryverylongword\nfits"},
+		{"été été été", 7, "été été\nété"},
+	}
+	for _, test := range tests {
+		if got := wrapText(test.text, test.width); got != test.want {
+			t.Errorf("wrapText(%q, %v) = %q, want %q", test.text, test.width, got, test.want)
+		}
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{5 * time.Minute, "5 minutes ago"},
+		{25 * time.Hour, "1 day ago"},
+		{15 * 24 * time.Hour, "2 weeks ago"},
+		{400 * 24 * time.Hour, "1 year ago"},
+		{-3 * time.Hour, "3 hours from now"},
+	}
+	for _, test := range tests {
+		if got := relativeTime(now.Add(-test.ago), now); got != test.want {
+			t.Errorf("relativeTime(%v ago) = %q, want %q", test.ago, got, test.want)
+		}
+	}
+}
+
+func TestPostFormatters(t *testing.T) {
+	id := uuid.MustParse("0123abcd-0000-4000-8000-000000000000")
+	published := time.Now().Add(-2 * time.Hour)
+	post := newPostView(id, "Hello & welcome", "https://blog.example.com/1", "<p>Some <b>bold</b> words</p>", published, "Blog")
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{FORMAT_COMPACT, []string{" * [0123abcd] 2 hours ago    Blog: Hello & welcome\n"}},
+		{FORMAT_DETAILED, []string{" * [0123abcd] Hello & welcome\n", "   Blog | 2 hours ago (", "   https://blog.example.com/1\n", "   Some bold words\n"}},
+		{"", []string{" * [0123abcd] Hello & welcome\n"}},
+		// Templates are text, nothing is escaped, and a newline is added when missing
+		{"{{.ShortID}} {{.Title}} {{.Description}}", []string{"0123abcd Hello & welcome Some bold words\n"}},
+		{"{{.Title}}\n", []string{"Hello & welcome\n"}},
+	}
+	for _, test := range tests {
+		format, err := newPostFormatter(test.format)
+		if err != nil {
+			t.Fatalf("newPostFormatter(%q): %v", test.format, err)
+		}
+		var sb strings.Builder
+		if err := format(&sb, post); err != nil {
+			t.Fatalf("format %q: %v", test.format, err)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(sb.String(), want) {
+				t.Errorf("format %q wrote %q, want it to contain %q", test.format, sb.String(), want)
+			}
+		}
+	}
+
+	if _, err := newPostFormatter("{{.Title"); err == nil {
+		t.Error("an unterminated template was accepted")
+	}
+	format, err := newPostFormatter("{{.Missing}}")
+	if err != nil {
+		t.Fatalf("newPostFormatter: %v", err)
+	}
+	if err := format(&strings.Builder{}, post); err == nil {
+		t.Error("a template using an unknown field ran without an error")
+	}
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+const DEFAULT_FILTER_TEST_LIMIT = 10
+
+// Filter Handler
+// filter add <kind> <pattern>
+// filter list
+// filter remove <id>
+// filter test <kind> <pattern> [--limit <count>]
+// Kinds are keyword, regex (on title and description), author, domain and category.
+func FilterHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	limit := fs.Int("limit", DEFAULT_FILTER_TEST_LIMIT, "number of posts to preview")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+	if err := checkFlagLimit(*limit); err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("usage: filter add|list|remove|test")
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: filter add <kind> <pattern>")
+		}
+		return addMuteFilter(s, user, args[1], strings.Join(args[2:], " "))
+	case "list":
+		return listMuteFilters(s, user)
+	case "remove":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: filter remove <id>")
+		}
+		return removeMuteFilter(s, user, args[1])
+	case "test":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: filter test <kind> <pattern> [--limit <count>]")
+		}
+		return testMuteFilter(s, user, args[1], strings.Join(args[2:], " "), *limit)
+	default:
+		return fmt.Errorf("unknown subcommand %v, expected add, list, remove or test", args[0])
+	}
+}
+
+// Mute the posts matching a filter for the user
+func addMuteFilter(s *State, user database.User, kind string, pattern string) error {
+	kind, pattern, err := validateMuteFilter(s, kind, pattern)
+	if err != nil {
+		return err
+	}
+
+	params := database.CreateMuteFilterParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Kind:      kind,
+		Pattern:   pattern,
+	}
+	filter, err := s.Db.CreateMuteFilter(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error saving filter: %w", err)
+	}
+
+	fmt.Printf("Muting %v %v (%v)\n", filter.Kind, filter.Pattern, shortPostID(filter.ID))
+	return nil
+}
+
+// Print the mute filters of the user
+func listMuteFilters(s *State, user database.User) error {
+	filters, err := s.Db.GetMuteFiltersForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching filters: %w", err)
+	}
+
+	fmt.Println("Mute filters:")
+	for _, filter := range filters {
+		fmt.Printf("  * %v %v: %v\n", shortPostID(filter.ID), filter.Kind, filter.Pattern)
+	}
+	return nil
+}
+
+// Delete a mute filter of the user by its id or id prefix
+func removeMuteFilter(s *State, user database.User, ref string) error {
+	filters, err := s.Db.GetMuteFiltersForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching filters: %w", err)
+	}
+
+	var matched []database.MuteFilter
+	for _, filter := range filters {
+		if strings.HasPrefix(filter.ID.String(), strings.ToLower(ref)) {
+			matched = append(matched, filter)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return fmt.Errorf("filter %v not found", ref)
+	case 1:
+	default:
+		return fmt.Errorf("filter id %v is ambiguous, provide more characters", ref)
+	}
+
+	params := database.DeleteMuteFilterParams{
+		UserID: user.ID,
+		ID:     matched[0].ID,
+	}
+	if _, err := s.Db.DeleteMuteFilter(context.Background(), params); err != nil {
+		return fmt.Errorf("error deleting filter: %w", err)
+	}
+
+	fmt.Printf("Removed filter %v %v\n", matched[0].Kind, matched[0].Pattern)
+	return nil
+}
+
+// Preview the followed posts a filter would hide, without saving it
+func testMuteFilter(s *State, user database.User, kind string, pattern string, limit int) error {
+	kind, pattern, err := validateMuteFilter(s, kind, pattern)
+	if err != nil {
+		return err
+	}
+
+	params := database.GetPostsMatchingMuteFilterParams{
+		UserID:    user.ID,
+		Kind:      kind,
+		Pattern:   pattern,
+		PostLimit: int32(limit),
+	}
+	posts, err := s.Db.GetPostsMatchingMuteFilter(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error testing filter: %w", err)
+	}
+
+	if len(posts) == 0 {
+		fmt.Printf("No followed posts match %v %v\n", kind, pattern)
+		return nil
+	}
+
+	fmt.Printf("Recent posts muted by %v %v:\n", kind, pattern)
+	format, err := newPostFormatter(FORMAT_COMPACT)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		view := newPostView(post.ID, post.Title, post.Url, post.Description, post.PublishedAt, post.FeedName)
+		if err := format(os.Stdout, view); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check the kind and pattern of a mute filter, normalising the pattern where needed
+func validateMuteFilter(s *State, kind string, pattern string) (string, string, error) {
+	kind = strings.ToLower(kind)
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return "", "", fmt.Errorf("filter pattern can't be empty")
+	}
+
+	switch kind {
+	case constants.MUTE_KIND_KEYWORD, constants.MUTE_KIND_AUTHOR, constants.MUTE_KIND_CATEGORY:
+		return kind, pattern, nil
+	case constants.MUTE_KIND_DOMAIN:
+		// Accept a full url as well as a bare host name
+		if _, host, found := strings.Cut(pattern, "://"); found {
+			pattern = host
+		}
+		pattern = strings.SplitN(pattern, "/", 2)[0]
+		pattern = strings.TrimPrefix(strings.SplitN(pattern, ":", 2)[0], "www.")
+		return kind, strings.ToLower(pattern), nil
+	case constants.MUTE_KIND_REGEX:
+		// Postgres evaluates the filter, so the pattern is checked in its regex dialect
+		if _, err := s.Db.ValidateRegex(context.Background(), pattern); err != nil {
+			return "", "", fmt.Errorf("invalid regex %v: %w", pattern, err)
+		}
+		return kind, pattern, nil
+	default:
+		return "", "", fmt.Errorf("unknown filter kind %v, expected keyword, regex, author, domain or category", kind)
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+func TestMuteFiltersHidePosts(t *testing.T) {
+	s := newTestState(t)
+	owner := createTestUser(t, s, "owner")
+	feed := createTestFeed(t, s, owner, "Mixed Blog", "https://blog.example.com/feed")
+	createTestPost(t, s, feed, database.CreatePostParams{Title: "Crypto prices today", Description: "Markets"})
+	createTestPost(t, s, feed, database.CreatePostParams{Title: "Sponsored: a new laptop", Description: "Advertisement"})
+	createTestPost(t, s, feed, database.CreatePostParams{Title: "Weekly links", Author: "Spam Bot"})
+	createTestPost(t, s, feed, database.CreatePostParams{Title: "Mirrored story", Url: "https://www.mirror.example.net/story"})
+	createTestPost(t, s, feed, database.CreatePostParams{Title: "Election coverage", Categories: []string{"Politics", "News"}})
+	createTestPost(t, s, feed, database.CreatePostParams{Title: "Go generics", Description: "Type parameters"})
+	all := []string{"Crypto prices today", "Sponsored: a new laptop", "Weekly links", "Mirrored story", "Election coverage", "Go generics"}
+
+	tests := []struct {
+		kind    string
+		pattern string
+		muted   string
+	}{
+		{"keyword", "CRYPTO", "Crypto prices today"},
+		{"regex", "^sponsored:", "Sponsored: a new laptop"},
+		{"author", "spam bot", "Weekly links"},
+		{"domain", "https://mirror.example.net/", "Mirrored story"},
+		{"category", "politics", "Election coverage"},
+	}
+	for _, test := range tests {
+		t.Run(test.kind, func(t *testing.T) {
+			reader := createTestUser(t, s, "reader-"+test.kind)
+			runAs(t, s, reader, FollowHandler, "follow", feed.Url)
+			runAs(t, s, reader, FilterHandler, "filter", "add", test.kind, test.pattern)
+
+			var shown []string
+			for _, title := range all {
+				if title != test.muted {
+					shown = append(shown, title)
+				}
+			}
+			expectTitles(t, browseTitles(t, s, reader), shown, []string{test.muted})
+
+			// The preview lists what the filter hides
+			preview := runAs(t, s, reader, FilterHandler, "filter", "test", test.kind, test.pattern)
+			if !strings.Contains(preview, test.muted) {
+				t.Errorf("filter test %v %v doesn't list %q:\n%v", test.kind, test.pattern, test.muted, preview)
+			}
+		})
+	}
+
+	// Filters are per user
+	runAs(t, s, owner, FollowHandler, "follow", feed.Url)
+	expectTitles(t, browseTitles(t, s, owner), all, nil)
+}
+
+func TestMuteFiltersHideSearchResults(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed", "Generics in practice", "Generics and crypto")
+	runAs(t, s, alice, FollowHandler, "follow", feed.Url)
+	runAs(t, s, alice, FilterHandler, "filter", "add", "keyword", "crypto")
+
+	output := runAs(t, s, alice, SearchHandler, "search", "generics")
+	expectTitles(t, output, []string{"Generics in practice"}, []string{"Generics and crypto"})
+
+	output = runAs(t, s, alice, FilterHandler, "filter", "list")
+	id := strings.Fields(strings.TrimPrefix(strings.Split(output, "\n")[1], "  * "))[0]
+	runAs(t, s, alice, FilterHandler, "filter", "remove", id)
+	output = runAs(t, s, alice, SearchHandler, "search", "generics")
+	expectTitles(t, output, []string{"Generics in practice", "Generics and crypto"}, nil)
+}
+
+func TestMuteFiltersRejectInvalidPatterns(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	for _, args := range [][]string{
+		{"add", "regex", "("},
+		{"add", "keyword", " "},
+		{"add", "colour", "red"},
+	} {
+		if _, err := captureStdout(t, func() error {
+			return FilterHandler(s, Command{Name: "filter", Arguments: args}, alice)
+		}); err == nil {
+			t.Errorf("filter %v was accepted", strings.Join(args, " "))
+		}
+	}
+
+	// Postgres word boundaries aren't valid in Go's regex syntax but are accepted
+	runAs(t, s, alice, FilterHandler, "filter", "add", "regex", `\mcrypto\M`)
+}
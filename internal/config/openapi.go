@@ -0,0 +1,149 @@
+package config
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Path parameters in a route, e.g. {name}
+var pathParamPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// Build the OpenAPI 3 description of the routes. Schemas are derived from the
+// sample bodies with reflection, so they follow the json views.
+func openAPIDescription(routes []apiRoute) map[string]any {
+	paths := map[string]map[string]any{}
+	for _, route := range routes {
+		var parameters []any
+		for _, match := range pathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+			parameters = append(parameters, map[string]any{
+				"name":     match[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+		for _, param := range route.Query {
+			parameters = append(parameters, map[string]any{
+				"name":        param.Name,
+				"in":          "query",
+				"description": param.Description,
+				"schema":      map[string]any{"type": param.Type},
+			})
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]any{"description": http.StatusText(status)}
+		if route.Response != nil {
+			success["content"] = jsonContent(route.Response)
+		}
+
+		operation := map[string]any{
+			"summary": route.Summary,
+			"responses": map[string]any{
+				strconv.Itoa(status): success,
+				"default": map[string]any{
+					"description": "Error",
+					"content":     jsonContent(apiErrorBody{}),
+				},
+			},
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if route.Body != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(route.Body),
+			}
+		}
+
+		if paths[route.Path] == nil {
+			paths[route.Path] = map[string]any{}
+		}
+		paths[route.Path][strings.ToLower(route.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "gator",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		// Tokens are only optional on servers started with --insecure
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"token": map[string]any{"type": "http", "scheme": "bearer", "description": "api token created with gator token create"},
+			},
+		},
+		"security": []map[string][]string{{"token": {}}},
+	}
+}
+
+// Json media type with the schema of a sample value
+func jsonContent(sample any) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{"schema": jsonSchema(reflect.TypeOf(sample))},
+	}
+}
+
+// Json schema of a go type, following encoding/json's rules for the types used by the views
+func jsonSchema(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(uuid.UUID{}):
+		return map[string]any{"type": "string", "format": "uuid"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := jsonSchema(t.Elem())
+		schema["nullable"] = true
+		return schema
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Struct:
+		properties := map[string]any{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = jsonSchema(field.Type)
+			if !strings.Contains(options, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Post sort orders
+const SORT_PUBLISHED = "published"
+const SORT_FETCHED = "fetched"
+const SORT_PRIORITY = "priority"
+
+// A mistake in what the user asked for, such as an invalid date or a feed they
+// don't follow, as opposed to an error reading the database
+type inputError struct {
+	err error
+}
+
+func (e inputError) Error() string {
+	return e.err.Error()
+}
+
+func (e inputError) Unwrap() error {
+	return e.err
+}
+
+// Filters, order and position of a listing of followed posts.
+// Shared by browse and the api so both read the same options the same way.
+type postQuery struct {
+	All         bool
+	SinceFollow bool
+	Feed        string
+	Folder      string
+	Since       string
+	Until       string
+	Author      string
+	Category    string
+	Sort        string
+	After       string
+	Limit       int
+}
+
+// Resolve the query into the parameters of GetPostsForUser
+func (q postQuery) params(s *State, user database.User) (database.GetPostsForUserParams, error) {
+	params := database.GetPostsForUserParams{
+		UserID:      user.ID,
+		UnreadOnly:  !q.All,
+		SinceFollow: q.SinceFollow,
+		PostLimit:   int32(q.Limit),
+	}
+
+	// Sorting
+	switch q.Sort {
+	case "", SORT_PUBLISHED:
+	case SORT_FETCHED:
+		params.SortByFetched = true
+	case SORT_PRIORITY:
+		params.SortByPriority = true
+	default:
+		return params, inputError{fmt.Errorf("unknown sort %v, expected published, fetched or priority", q.Sort)}
+	}
+
+	// Filters
+	if q.Feed != "" {
+		feedID, err := findFollowedFeed(s, user, q.Feed)
+		if err != nil {
+			return params, err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feedID, Valid: true}
+	}
+	if q.Folder != "" {
+		folder, err := findFolder(s, user, q.Folder)
+		if err != nil {
+			return params, err
+		}
+		params.FolderID = uuid.NullUUID{UUID: folder.ID, Valid: true}
+	}
+	if q.Since != "" {
+		parsed, err := parseFlagDate(q.Since)
+		if err != nil {
+			return params, inputError{err}
+		}
+		params.Since = sql.NullTime{Time: parsed, Valid: true}
+	}
+	if q.Until != "" {
+		parsed, err := parseFlagDate(q.Until)
+		if err != nil {
+			return params, inputError{err}
+		}
+		params.Until = sql.NullTime{Time: parsed, Valid: true}
+	}
+	if q.Author != "" {
+		params.Author = sql.NullString{String: q.Author, Valid: true}
+	}
+	if q.Category != "" {
+		params.Category = sql.NullString{String: q.Category, Valid: true}
+	}
+
+	// Continue after the last post of the previous page
+	if q.After != "" {
+		cursor, err := parsePostCursor(q.After)
+		if err != nil {
+			return params, inputError{err}
+		}
+		params.AfterTime = sql.NullTime{Time: cursor.Time, Valid: true}
+		if params.SortByPriority {
+			params.AfterPriority = cursor.Priority
+		}
+		params.AfterID = uuid.NullUUID{UUID: cursor.ID, Valid: true}
+	}
+
+	return params, nil
+}
+
+// Cursor of the page after posts, empty when posts is not a full page
+func nextPostCursor(params database.GetPostsForUserParams, posts []database.GetPostsForUserRow) string {
+	if len(posts) == 0 || len(posts) < int(params.PostLimit) {
+		return ""
+	}
+
+	last := posts[len(posts)-1]
+	cursor := postCursor{Time: last.PublishedAt, ID: last.ID}
+	if params.SortByFetched {
+		cursor.Time = last.CreatedAt
+	}
+	if params.SortByPriority {
+		cursor.Priority = last.FeedPriority
+	}
+	return cursor.String()
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Minimum length of a post id prefix accepted on the command line
+const MIN_POST_ID_PREFIX = 8
+
+// Read Handler. Marks a post as read and prints its link.
+func ReadHandler(s *State, cmd Command, user database.User) error {
+	// early exit with error if command arguments are empty
+	if len(cmd.Arguments) == 0 {
+		return fmt.Errorf("you need to provide the post id or url to mark as read")
+	}
+
+	post, err := findPost(s, cmd.Arguments[0])
+	if err != nil {
+		return err
+	}
+
+	params := database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+		ReadAt: time.Now(),
+	}
+	if err := s.Db.MarkPostRead(context.Background(), params); err != nil {
+		return fmt.Errorf("error marking post as read: %w", err)
+	}
+
+	// Reading a post takes it off the read later queue
+	unsaveParams := database.UnsavePostParams{
+		UserID: user.ID,
+		PostID: post.ID,
+		Kind:   constants.SAVED_KIND_LATER,
+	}
+	if _, err := s.Db.UnsavePost(context.Background(), unsaveParams); err != nil {
+		return fmt.Errorf("error removing post from read later: %w", err)
+	}
+
+	fmt.Printf("Marked as read: %v\n", post.Title)
+	fmt.Printf("  %v\n", post.Url)
+	return nil
+}
+
+// Unread Handler. Marks a post as unread again.
+func UnreadHandler(s *State, cmd Command, user database.User) error {
+	// early exit with error if command arguments are empty
+	if len(cmd.Arguments) == 0 {
+		return fmt.Errorf("you need to provide the post id or url to mark as unread")
+	}
+
+	post, err := findPost(s, cmd.Arguments[0])
+	if err != nil {
+		return err
+	}
+
+	params := database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	}
+	result, err := s.Db.MarkPostUnread(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error marking post as unread: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error marking post as unread: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("post %v was not read", post.Title)
+	}
+
+	fmt.Printf("Marked as unread: %v\n", post.Title)
+	return nil
+}
+
+// Mark Read Handler
+// mark-read --feed <url> [--before <date>]
+func MarkReadHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	feedUrl := fs.String("feed", "", "url of the feed to mark as read")
+	before := fs.String("before", "", "only mark posts published before this date")
+	if _, err := parseFlags(fs, cmd.Arguments); err != nil {
+		return err
+	}
+
+	if *feedUrl == "" {
+		return fmt.Errorf("usage: mark-read --feed <url> [--before <date>]")
+	}
+
+	// Without --before every post of the feed is marked
+	publishedBefore := time.Now()
+	if *before != "" {
+		parsed, err := parseFlagDate(*before)
+		if err != nil {
+			return err
+		}
+		publishedBefore = parsed
+	}
+
+	feed, err := s.Db.GetFeedByUrl(context.Background(), *feedUrl)
+	if err != nil {
+		return fmt.Errorf("error fetching feed: %w", err)
+	}
+
+	params := database.MarkFeedPostsReadParams{
+		UserID:      user.ID,
+		ReadAt:      time.Now(),
+		FeedID:      feed.ID,
+		PublishedAt: publishedBefore,
+	}
+	count, err := s.Db.MarkFeedPostsRead(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error marking posts as read: %w", err)
+	}
+
+	fmt.Printf("Marked %v posts from %v as read\n", count, feed.Name)
+	return nil
+}
+
+// Mark All Read Handler. Marks every post of the followed feeds as read.
+func MarkAllReadHandler(s *State, cmd Command, user database.User) error {
+	params := database.MarkAllPostsReadParams{
+		UserID: user.ID,
+		ReadAt: time.Now(),
+	}
+	count, err := s.Db.MarkAllPostsRead(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error marking posts as read: %w", err)
+	}
+
+	fmt.Printf("Marked %v posts as read\n", count)
+	return nil
+}
+
+// Find a post by its url, id or an unambiguous id prefix as shown by browse
+func findPost(s *State, ref string) (database.Post, error) {
+	if strings.Contains(ref, "://") {
+		post, err := s.Db.GetPostByUrl(context.Background(), ref)
+		if err != nil {
+			return database.Post{}, fmt.Errorf("error fetching post: %w", err)
+		}
+		return post, nil
+	}
+
+	if _, err := uuid.Parse(ref); err != nil && len(ref) < MIN_POST_ID_PREFIX {
+		return database.Post{}, fmt.Errorf("post id must be at least %v characters", MIN_POST_ID_PREFIX)
+	}
+
+	// Only hex digits and dashes, so the prefix can't carry LIKE wildcards
+	prefix := strings.ToLower(ref)
+	if strings.Trim(prefix, "0123456789abcdef-") != "" {
+		return database.Post{}, fmt.Errorf("invalid post id %v", ref)
+	}
+
+	posts, err := s.Db.GetPostsByIdPrefix(context.Background(), prefix)
+	if err != nil {
+		return database.Post{}, fmt.Errorf("error fetching post: %w", err)
+	}
+
+	switch len(posts) {
+	case 0:
+		return database.Post{}, fmt.Errorf("post %v not found", ref)
+	case 1:
+		return posts[0], nil
+	default:
+		return database.Post{}, fmt.Errorf("post id %v is ambiguous, provide more characters", ref)
+	}
+}
+
+// Short id shown next to posts
+func shortPostID(id uuid.UUID) string {
+	return id.String()[:MIN_POST_ID_PREFIX]
+}
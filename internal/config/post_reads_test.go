@@ -0,0 +1,95 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+func TestFindPostRejectsBadIdsBeforeQuerying(t *testing.T) {
+	// The database isn't reached, so the state can be empty
+	for _, ref := range []string{"abc", "1234567", "zzzzzzzz", "1234567%", "1234_678"} {
+		if _, err := findPost(&State{}, ref); err == nil {
+			t.Errorf("findPost(%q) was accepted", ref)
+		}
+	}
+}
+
+func TestFindPostByIdPrefix(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "Blog", "https://blog.example.com/feed")
+	for i, id := range []string{"aaaaaaaa-0000-4000-8000-000000000000", "aaaaaaaa-1111-4000-8000-000000000000"} {
+		if _, err := s.Db.CreatePost(context.Background(), database.CreatePostParams{
+			ID:          uuid.MustParse(id),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			Title:       id,
+			Url:         feed.Url + "/" + string(rune('a'+i)),
+			PublishedAt: time.Now(),
+			FeedID:      feed.ID,
+			Categories:  []string{},
+		}); err != nil {
+			t.Fatalf("error creating post: %v", err)
+		}
+	}
+
+	if _, err := findPost(s, "aaaaaaaa"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("an ambiguous prefix returned %v", err)
+	}
+	for _, ref := range []string{"AAAAAAAA-1", "aaaaaaaa-1111-4000-8000-000000000000", feed.Url + "/b"} {
+		post, err := findPost(s, ref)
+		if err != nil || post.Title != "aaaaaaaa-1111-4000-8000-000000000000" {
+			t.Errorf("findPost(%q) = %v, %v", ref, post.Title, err)
+		}
+	}
+	if _, err := findPost(s, "bbbbbbbb"); err == nil {
+		t.Error("an unknown prefix was found")
+	}
+}
+
+func TestReadStateChangesBrowseAndUnreadCounts(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "Blog", "https://blog.example.com/feed", "Post 0", "Post 1", "Post 2", "Post 3")
+	other := createTestFeed(t, s, alice, "Other", "https://other.example.com/feed", "Other 0")
+	runAs(t, s, alice, FollowHandler, "follow", feed.Url)
+	runAs(t, s, alice, FollowHandler, "follow", other.Url)
+
+	expectCounts := func(lines ...string) {
+		t.Helper()
+		following := runAs(t, s, alice, FollowingHandler, "following")
+		for _, line := range lines {
+			if !strings.Contains(following, line) {
+				t.Errorf("following should contain %q, got:\n%v", line, following)
+			}
+		}
+	}
+
+	runAs(t, s, alice, ReadHandler, "read", feed.Url+"/0")
+	expectTitles(t, browseTitles(t, s, alice), []string{"Post 1", "Other 0"}, []string{"Post 0"})
+	expectCounts("Blog (3 unread)", "Other (1 unread)")
+
+	runAs(t, s, alice, UnreadHandler, "unread", feed.Url+"/0")
+	expectTitles(t, browseTitles(t, s, alice), []string{"Post 0"}, nil)
+	if _, err := captureStdout(t, func() error {
+		return UnreadHandler(s, Command{Name: "unread", Arguments: []string{feed.Url + "/0"}}, alice)
+	}); err == nil || !strings.Contains(err.Error(), "was not read") {
+		t.Errorf("un-reading an unread post returned %v", err)
+	}
+
+	// Posts are published a minute apart, so this marks posts 2 and 3
+	before := time.Now().Add(-90 * time.Second).Format(time.RFC3339)
+	runAs(t, s, alice, MarkReadHandler, "mark-read", "--feed", feed.Url, "--before", before)
+	expectTitles(t, browseTitles(t, s, alice), []string{"Post 0", "Post 1"}, []string{"Post 2", "Post 3"})
+	expectCounts("Blog (2 unread)")
+
+	runAs(t, s, alice, MarkAllReadHandler, "mark-all-read")
+	expectTitles(t, browseTitles(t, s, alice), nil, []string{"Post 0", "Post 1", "Other 0"})
+	expectTitles(t, browseTitles(t, s, alice, "--all"), []string{"Post 0", "Post 3", "Other 0"}, nil)
+	expectCounts("Blog (0 unread)", "Other (0 unread)")
+}
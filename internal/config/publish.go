@@ -0,0 +1,322 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/network"
+)
+
+const DEFAULT_PUBLISH_LIMIT = 50
+
+// Published feed formats
+const PUBLISH_FORMAT_RSS = "rss"
+const PUBLISH_FORMAT_ATOM = "atom"
+
+// Which posts a published feed holds. The zero value is the whole timeline.
+type publishSource struct {
+	Folder  string
+	Search  string
+	Starred bool
+}
+
+// Looking up a folder or saved search that doesn't exist
+var errPublishSourceNotFound = errors.New("publish source not found")
+
+// Publish Handler
+// publish [--format rss|atom] [--output <file>] [--url <public url>]
+// publish --listen <addr> [--insecure]
+// Pick the posts with --folder <name>, --search <saved search> or --starred, the timeline otherwise.
+// The server answers /rss and /atom, taking folder, search, starred and limit query parameters.
+// Feed readers pass an api token of the user as the basic auth password;
+// --insecure also serves requests without one.
+func PublishHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	format := fs.String("format", PUBLISH_FORMAT_RSS, "rss or atom")
+	output := fs.String("output", "", "file to write, standard output by default")
+	publicURL := fs.String("url", "", "url the feed is published at")
+	listen := fs.String("listen", "", "serve the feeds on this address instead, e.g. :8082")
+	insecure := fs.Bool("insecure", false, "with --listen, serve requests without an api token")
+	folder := fs.String("folder", "", "publish the posts of a folder")
+	search := fs.String("search", "", "publish the posts matching a saved search")
+	starred := fs.Bool("starred", false, "publish the starred posts")
+	limit := fs.Int("limit", DEFAULT_PUBLISH_LIMIT, "number of posts to publish")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+	if err := checkFlagLimit(*limit); err != nil {
+		return err
+	}
+
+	if len(args) != 0 {
+		return fmt.Errorf("usage: publish [--folder <name>|--search <name>|--starred] [--format rss|atom] [--output <file>] [--listen <addr> [--insecure]]")
+	}
+
+	if *listen != "" {
+		if *insecure {
+			fmt.Printf("Warning: --insecure lets anyone reaching the server read %v's feeds\n", user.Name)
+		}
+		return servePublishedFeeds(s, user, *listen, !*insecure)
+	}
+
+	source := publishSource{Folder: *folder, Search: *search, Starred: *starred}
+	feed, err := buildPublishedFeed(s, user, source, *limit)
+	if err != nil {
+		return err
+	}
+
+	// Feeds need a link, fall back to where the file is written
+	feed.SelfURL = *publicURL
+	if feed.SelfURL == "" && *output != "" {
+		path, err := filepath.Abs(*output)
+		if err != nil {
+			return fmt.Errorf("error resolving output path: %w", err)
+		}
+		feed.SelfURL = "file://" + filepath.ToSlash(path)
+	}
+	if feed.SelfURL == "" {
+		feed.SelfURL = feed.ID
+	}
+
+	var buffer bytes.Buffer
+	if err := renderPublishedFeed(&buffer, *format, feed); err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err := os.Stdout.Write(buffer.Bytes())
+		return err
+	}
+	if err := os.WriteFile(*output, buffer.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error writing %v: %w", *output, err)
+	}
+	fmt.Printf("Published %v posts to %v\n", len(feed.Items), *output)
+	return nil
+}
+
+// Collect the posts of a source into a feed
+func buildPublishedFeed(s *State, user database.User, source publishSource, limit int) (network.PublishedFeed, error) {
+	var items []network.PublishedItem
+	var title, key string
+
+	switch {
+	case source.Starred:
+		title = fmt.Sprintf("%v's starred posts", user.Name)
+		key = "starred"
+		starred, err := s.Db.GetStarredPostsForUser(context.Background(), user.ID)
+		if err != nil {
+			return network.PublishedFeed{}, fmt.Errorf("error fetching starred posts: %w", err)
+		}
+		for _, post := range starred {
+			if len(items) == limit {
+				break
+			}
+			items = append(items, newPublishedItem(post.ID, post.Title, post.Url, post.Description, post.Content, post.Author, post.Categories, post.PublishedAt))
+		}
+	case source.Search != "":
+		savedSearch, err := s.Db.GetSavedSearchByName(context.Background(), database.GetSavedSearchByNameParams{
+			UserID: user.ID,
+			Name:   source.Search,
+		})
+		if err != nil {
+			return network.PublishedFeed{}, fmt.Errorf("%w: no saved search named %v", errPublishSourceNotFound, source.Search)
+		}
+		title = fmt.Sprintf("%v's search %v", user.Name, savedSearch.Name)
+		key = "search:" + savedSearch.ID.String()
+		posts, err := s.Db.GetPostsForQuery(context.Background(), database.GetPostsForQueryParams{
+			UserID:    user.ID,
+			QueryText: savedSearch.Query,
+			PostLimit: int32(limit),
+		})
+		if err != nil {
+			return network.PublishedFeed{}, fmt.Errorf("error fetching posts: %w", err)
+		}
+		for _, post := range posts {
+			items = append(items, newPublishedItem(post.ID, post.Title, post.Url, post.Description, post.Content, post.Author, post.Categories, post.PublishedAt))
+		}
+	default:
+		params := database.GetPostsForUserParams{
+			UserID:    user.ID,
+			PostLimit: int32(limit),
+		}
+		title = fmt.Sprintf("%v's timeline", user.Name)
+		key = "timeline"
+		if source.Folder != "" {
+			folder, err := findFolder(s, user, source.Folder)
+			if err != nil {
+				return network.PublishedFeed{}, fmt.Errorf("%w: %w", errPublishSourceNotFound, err)
+			}
+			params.FolderID = uuid.NullUUID{UUID: folder.ID, Valid: true}
+			title = fmt.Sprintf("%v's %v", user.Name, folder.Name)
+			key = "folder:" + folder.ID.String()
+		}
+		timeline, err := s.Db.GetPostsForUser(context.Background(), params)
+		if err != nil {
+			return network.PublishedFeed{}, fmt.Errorf("error fetching posts: %w", err)
+		}
+		for _, post := range timeline {
+			items = append(items, newPublishedItem(post.ID, post.Title, post.Url, post.Description, post.Content, post.Author, post.Categories, post.PublishedAt))
+		}
+	}
+
+	// Ids are derived from the user and source, so they survive renames and restarts
+	feed := network.PublishedFeed{
+		ID:      publishedID(uuid.NewSHA1(user.ID, []byte(key))),
+		Title:   "gator: " + title,
+		Author:  user.Name,
+		Updated: user.CreatedAt,
+		Items:   items,
+	}
+	for _, item := range items {
+		if item.Published.After(feed.Updated) {
+			feed.Updated = item.Published
+		}
+	}
+	return feed, nil
+}
+
+// Create a published item from a stored post
+func newPublishedItem(id uuid.UUID, title string, url string, description string, content string, author string, categories []string, publishedAt time.Time) network.PublishedItem {
+	return network.PublishedItem{
+		ID:          publishedID(id),
+		Title:       title,
+		Link:        url,
+		Description: description,
+		Content:     content,
+		Author:      author,
+		Categories:  categories,
+		Published:   publishedAt,
+	}
+}
+
+// Stable identifier of a published feed or post
+func publishedID(id uuid.UUID) string {
+	return "urn:uuid:" + id.String()
+}
+
+// Render a feed in the given format
+func renderPublishedFeed(w io.Writer, format string, feed network.PublishedFeed) error {
+	switch format {
+	case PUBLISH_FORMAT_RSS:
+		return network.RenderRSS(w, feed)
+	case PUBLISH_FORMAT_ATOM:
+		return network.RenderAtom(w, feed)
+	default:
+		return fmt.Errorf("unknown format %v, expected rss or atom", format)
+	}
+}
+
+// Handler answering /rss and /atom with the user's feeds
+func publishedFeedsHandler(s *State, user database.User, requireToken bool) http.Handler {
+	mux := http.NewServeMux()
+	for _, format := range []string{PUBLISH_FORMAT_RSS, PUBLISH_FORMAT_ATOM} {
+		mux.HandleFunc("/"+format, func(w http.ResponseWriter, r *http.Request) {
+			servePublishedFeed(s, user, format, w, r)
+		})
+	}
+	return authenticateRequests(s, requireToken, mux)
+}
+
+// Serve the user's feeds over http until interrupted
+func servePublishedFeeds(s *State, user database.User, addr string, requireToken bool) error {
+
+	// Listen before announcing so that address errors are reported
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error starting publish server: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           publishedFeedsHandler(s, user, requireToken),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Publishing %v's feeds on http://%v/rss and /atom\n", user.Name, listener.Addr())
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("error serving feeds: %w", err)
+	}
+	return nil
+}
+
+// Answer a request for a published feed
+func servePublishedFeed(s *State, user database.User, format string, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if tokenUser, ok := requestTokenUser(r); ok && tokenUser.ID != user.ID {
+		http.Error(w, "the api token can't access these feeds", http.StatusForbidden)
+		return
+	}
+
+	query := r.URL.Query()
+	source := publishSource{
+		Folder:  query.Get("folder"),
+		Search:  query.Get("search"),
+		Starred: query.Get("starred") == "true" || query.Get("starred") == "1",
+	}
+	limit := DEFAULT_PUBLISH_LIMIT
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	feed, err := buildPublishedFeed(s, user, source, limit)
+	if errors.Is(err, errPublishSourceNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		fmt.Printf("Error publishing feed: %v\n", err)
+		http.Error(w, "error building feed", http.StatusInternalServerError)
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	feed.SelfURL = scheme + "://" + r.Host + r.URL.RequestURI()
+
+	var buffer bytes.Buffer
+	if err := renderPublishedFeed(&buffer, format, feed); err != nil {
+		fmt.Printf("Error publishing feed: %v\n", err)
+		http.Error(w, "error rendering feed", http.StatusInternalServerError)
+		return
+	}
+
+	contentType := network.RSS_CONTENT_TYPE
+	if format == PUBLISH_FORMAT_ATOM {
+		contentType = network.ATOM_CONTENT_TYPE
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Last-Modified", feed.Updated.UTC().Format(http.TimeFormat))
+	w.Write(buffer.Bytes())
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+func TestPublishListenerRejectsRequestsWithoutToken(t *testing.T) {
+	alice := database.User{ID: uuid.New(), Name: "alice"}
+	handler := publishedFeedsHandler(&State{}, alice, true)
+
+	for _, target := range []string{"/rss", "/atom", "/rss?starred=true"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", target, nil))
+		if recorder.Code != http.StatusUnauthorized {
+			t.Errorf("GET %v without a token answered %v, want %v", target, recorder.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestPublishedIdsAreStable(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed", "Go post one", "Go post two")
+	runAs(t, s, alice, FollowHandler, "follow", feed.Url)
+	runAs(t, s, alice, StarHandler, "star", feed.Url+"/0")
+
+	timeline, err := buildPublishedFeed(s, alice, publishSource{}, DEFAULT_PUBLISH_LIMIT)
+	if err != nil {
+		t.Fatalf("build timeline: %v", err)
+	}
+	again, err := buildPublishedFeed(s, alice, publishSource{}, DEFAULT_PUBLISH_LIMIT)
+	if err != nil {
+		t.Fatalf("build timeline again: %v", err)
+	}
+	starred, err := buildPublishedFeed(s, alice, publishSource{Starred: true}, DEFAULT_PUBLISH_LIMIT)
+	if err != nil {
+		t.Fatalf("build starred: %v", err)
+	}
+
+	if timeline.ID != again.ID || timeline.ID == starred.ID {
+		t.Errorf("feed ids are %v, %v and starred %v, want the timeline id stable and different from starred", timeline.ID, again.ID, starred.ID)
+	}
+	if len(timeline.Items) != 2 || len(again.Items) != 2 || len(starred.Items) != 1 {
+		t.Fatalf("built %v, %v and %v items, want 2, 2 and 1", len(timeline.Items), len(again.Items), len(starred.Items))
+	}
+	for i := range timeline.Items {
+		if timeline.Items[i].ID != again.Items[i].ID {
+			t.Errorf("item %v id changed from %v to %v", i, timeline.Items[i].ID, again.Items[i].ID)
+		}
+	}
+	// A post has the same id in every feed it's published in
+	if starred.Items[0].ID != timeline.Items[0].ID {
+		t.Errorf("the starred post's id is %v, want %v", starred.Items[0].ID, timeline.Items[0].ID)
+	}
+}
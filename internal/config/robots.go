@@ -0,0 +1,90 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Robots Handler
+// robots <feed_url> ignore   fetches the feed regardless of robots.txt
+// robots <feed_url> obey     respects robots.txt again
+func RobotsHandler(s *State, cmd Command, user database.User) error {
+	// early exit with error if command arguments are not enough
+	if len(cmd.Arguments) < 2 {
+		return fmt.Errorf("usage: robots <feed_url> ignore|obey")
+	}
+
+	feed, err := s.Db.GetFeedByUrl(context.Background(), cmd.Arguments[0])
+	if err != nil {
+		return fmt.Errorf("error fetching feed: %w", err)
+	}
+
+	// Only the user who added the feed can override robots.txt
+	if !feed.UserID.Valid || feed.UserID.UUID != user.ID {
+		return fmt.Errorf("only the user who added the feed can override robots.txt")
+	}
+
+	var ignore bool
+	switch cmd.Arguments[1] {
+	case "ignore":
+		ignore = true
+	case "obey":
+		ignore = false
+	default:
+		return fmt.Errorf("unknown option %v, expected ignore or obey", cmd.Arguments[1])
+	}
+
+	params := database.UpdateFeedIgnoreRobotsParams{
+		IgnoreRobots: ignore,
+		UpdatedAt:    time.Now(),
+		ID:           feed.ID,
+	}
+	if err := s.Db.UpdateFeedIgnoreRobots(context.Background(), params); err != nil {
+		return fmt.Errorf("error updating feed: %w", err)
+	}
+
+	if ignore {
+		fmt.Printf("%v will be fetched regardless of robots.txt\n", feed.Name)
+	} else {
+		fmt.Printf("%v will respect robots.txt\n", feed.Name)
+	}
+	return nil
+}
+
+// Check robots.txt for the feed and wait for the host's crawl delay.
+// Returns false when the feed is blocked and records it on the feed.
+func checkRobots(s *State, feed database.Feed) (bool, error) {
+	allowed := true
+	if !feed.IgnoreRobots {
+		var err error
+		allowed, err = s.Client.Robots.Allowed(context.Background(), feed.Url)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// Record the change in the robots status
+	if allowed == feed.RobotsBlocked {
+		params := database.MarkFeedRobotsBlockedParams{
+			RobotsBlocked: !allowed,
+			UpdatedAt:     time.Now(),
+			ID:            feed.ID,
+		}
+		if err := s.Db.MarkFeedRobotsBlocked(context.Background(), params); err != nil {
+			return false, fmt.Errorf("error marking feed robots status: %w", err)
+		}
+	}
+
+	if !allowed || feed.IgnoreRobots {
+		return allowed, nil
+	}
+
+	if err := s.Client.Robots.Wait(context.Background(), feed.Url); err != nil {
+		return false, fmt.Errorf("error waiting for crawl delay: %w", err)
+	}
+
+	return true, nil
+}
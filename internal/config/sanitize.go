@@ -0,0 +1,202 @@
+package config
+
+import (
+	"html/template"
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Elements kept when rendering post content, with the attributes they may keep
+var allowedElements = map[atom.Atom][]string{
+	atom.A:          {"href", "title"},
+	atom.Abbr:       {"title"},
+	atom.B:          nil,
+	atom.Blockquote: nil,
+	atom.Br:         nil,
+	atom.Caption:    nil,
+	atom.Code:       nil,
+	atom.Dd:         nil,
+	atom.Del:        nil,
+	atom.Div:        nil,
+	atom.Dl:         nil,
+	atom.Dt:         nil,
+	atom.Em:         nil,
+	atom.Figcaption: nil,
+	atom.Figure:     nil,
+	atom.H1:         nil,
+	atom.H2:         nil,
+	atom.H3:         nil,
+	atom.H4:         nil,
+	atom.H5:         nil,
+	atom.H6:         nil,
+	atom.Hr:         nil,
+	atom.I:          nil,
+	atom.Img:        {"src", "alt", "title", "width", "height"},
+	atom.Ins:        nil,
+	atom.Li:         nil,
+	atom.Ol:         nil,
+	atom.P:          nil,
+	atom.Pre:        nil,
+	atom.Q:          nil,
+	atom.S:          nil,
+	atom.Small:      nil,
+	atom.Span:       nil,
+	atom.Strong:     nil,
+	atom.Sub:        nil,
+	atom.Sup:        nil,
+	atom.Table:      nil,
+	atom.Tbody:      nil,
+	atom.Td:         {"colspan", "rowspan"},
+	atom.Tfoot:      nil,
+	atom.Th:         {"colspan", "rowspan"},
+	atom.Thead:      nil,
+	atom.Tr:         nil,
+	atom.U:          nil,
+	atom.Ul:         nil,
+}
+
+// Elements dropped together with everything inside them
+var droppedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Math:     true,
+	atom.Form:     true,
+	atom.Select:   true,
+	atom.Textarea: true,
+	atom.Head:     true,
+	atom.Title:    true,
+}
+
+// Clean feed html for display. Only allowlisted elements and attributes are
+// kept, links may only use http, https or mailto and relative links are
+// resolved against the post's url. Anything else is dropped, keeping its text.
+func sanitizeHTML(content string, base string) template.HTML {
+	baseURL, _ := url.Parse(base)
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+
+	var out strings.Builder
+	// Depth inside a dropped element, whose content is skipped
+	dropped := 0
+	// Open allowed elements, so that unclosed ones can be closed at the end
+	var open []atom.Atom
+
+	for {
+		tokenType := tokenizer.Next()
+		// End of input, or input the tokenizer gave up on
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedElements[token.DataAtom] {
+				if tokenType == html.StartTagToken && !isVoidElement(token.DataAtom) {
+					dropped++
+				}
+				continue
+			}
+			if dropped > 0 {
+				continue
+			}
+			allowedAttrs, ok := allowedElements[token.DataAtom]
+			if !ok {
+				continue
+			}
+
+			out.WriteString("<" + token.DataAtom.String())
+			for _, attr := range token.Attr {
+				if attr.Namespace != "" || !slices.Contains(allowedAttrs, attr.Key) {
+					continue
+				}
+				value := attr.Val
+				if attr.Key == "href" || attr.Key == "src" {
+					var ok bool
+					if value, ok = safeURL(baseURL, value); !ok {
+						continue
+					}
+				}
+				out.WriteString(" " + attr.Key + `="` + html.EscapeString(value) + `"`)
+			}
+			if token.DataAtom == atom.A {
+				out.WriteString(` rel="noopener noreferrer nofollow" target="_blank"`)
+			}
+			if token.DataAtom == atom.Img {
+				out.WriteString(` loading="lazy" referrerpolicy="no-referrer"`)
+			}
+			out.WriteString(">")
+
+			if tokenType == html.StartTagToken && !isVoidElement(token.DataAtom) {
+				open = append(open, token.DataAtom)
+			}
+		case html.EndTagToken:
+			if droppedElements[token.DataAtom] {
+				if dropped > 0 {
+					dropped--
+				}
+				continue
+			}
+			if dropped > 0 {
+				continue
+			}
+			// Close the element if it is open, along with any unclosed children
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != token.DataAtom {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					out.WriteString("</" + open[j].String() + ">")
+				}
+				open = open[:i]
+				break
+			}
+		case html.TextToken:
+			if dropped > 0 {
+				continue
+			}
+			out.WriteString(html.EscapeString(token.Data))
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		out.WriteString("</" + open[i].String() + ">")
+	}
+	return template.HTML(out.String())
+}
+
+// Resolve a link and accept it only for safe schemes
+func safeURL(base *url.URL, value string) (string, bool) {
+	parsed, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		parsed = base.ResolveReference(parsed)
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https", "mailto":
+		return parsed.String(), true
+	default:
+		return "", false
+	}
+}
+
+// Elements which never have content or an end tag
+func isVoidElement(a atom.Atom) bool {
+	switch a {
+	case atom.Area, atom.Base, atom.Br, atom.Col, atom.Embed, atom.Hr, atom.Img, atom.Input,
+		atom.Link, atom.Meta, atom.Source, atom.Track, atom.Wbr:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package config
+
+import "testing"
+
+func TestSanitizeHTML(t *testing.T) {
+	const base = "https://blog.example.com/posts/1"
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"allowed elements", "<p>Hello <b>bold</b> <em>world</em></p>", "<p>Hello <b>bold</b> <em>world</em></p>"},
+		{"text is escaped", "Tom &amp; Jerry &lt;3", "Tom &amp; Jerry &lt;3"},
+		{"unknown elements keep their text", "<font color=red>red</font> <custom-tag>x</custom-tag>", "red x"},
+		{"scripts and styles are dropped", "a<script>alert(1)</script><style>p{}</style>b", "ab"},
+		{"nested dropped elements", "a<svg><g><script>x</script></g>inside</svg>b", "ab"},
+		{"comments are dropped", "a<!-- secret -->b", "ab"},
+		{"event handlers are dropped", `<p onclick="alert(1)" style="color:red">x</p>`, "<p>x</p>"},
+		{"links open safely", `<a href="https://go.dev/" onclick="x">Go</a>`, `<a href="https://go.dev/" rel="noopener noreferrer nofollow" target="_blank">Go</a>`},
+		{"relative links are resolved", `<a href="../2">next</a>`, `<a href="https://blog.example.com/2" rel="noopener noreferrer nofollow" target="_blank">next</a>`},
+		{"javascript links lose the href", `<a href="javascript:alert(1)">x</a>`, `<a rel="noopener noreferrer nofollow" target="_blank">x</a>`},
+		{"obfuscated schemes lose the href", `<a href=" JaVaScRiPt:alert(1)">x</a>`, `<a rel="noopener noreferrer nofollow" target="_blank">x</a>`},
+		{"data images lose the src", `<img src="data:image/png;base64,AAAA" alt="x">`, `<img alt="x" loading="lazy" referrerpolicy="no-referrer">`},
+		{"images are resolved", `<img src="/a.png" width="10">`, `<img src="https://blog.example.com/a.png" width="10" loading="lazy" referrerpolicy="no-referrer">`},
+		{"attribute values are escaped", `<abbr title="&quot;quoted&quot; &lt;b&gt;">x</abbr>`, `<abbr title="&#34;quoted&#34; &lt;b&gt;">x</abbr>`},
+		{"unclosed elements are closed", "<ul><li><b>one", "<ul><li><b>one</b></li></ul>"},
+		{"stray end tags are ignored", "</div>a</b>", "a"},
+		{"end tags close unclosed children", "<p><b>a</p>b", "<p><b>a</b></p>b"},
+	}
+	for _, test := range tests {
+		if got := string(sanitizeHTML(test.html, base)); got != test.want {
+			t.Errorf("%v: sanitizeHTML(%q) = %q, want %q", test.name, test.html, got, test.want)
+		}
+	}
+}
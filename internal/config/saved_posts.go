@@ -0,0 +1,154 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Star Handler
+func StarHandler(s *State, cmd Command, user database.User) error {
+	// early exit with error if command arguments are empty
+	if len(cmd.Arguments) == 0 {
+		return fmt.Errorf("you need to provide the post id or url to star")
+	}
+
+	post, err := savePost(s, user, cmd.Arguments[0], constants.SAVED_KIND_STAR)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Starred: %v\n", post.Title)
+	return nil
+}
+
+// Unstar Handler
+func UnstarHandler(s *State, cmd Command, user database.User) error {
+	// early exit with error if command arguments are empty
+	if len(cmd.Arguments) == 0 {
+		return fmt.Errorf("you need to provide the post id or url to unstar")
+	}
+
+	post, err := unsavePost(s, user, cmd.Arguments[0], constants.SAVED_KIND_STAR)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Unstarred: %v\n", post.Title)
+	return nil
+}
+
+// Saved Handler. Lists the starred posts, most recently starred first.
+func SavedHandler(s *State, cmd Command, user database.User) error {
+	posts, err := s.Db.GetStarredPostsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching starred posts: %w", err)
+	}
+
+	fmt.Println("Starred posts : ")
+	for _, post := range posts {
+		fmt.Printf(" * [%v] %v\n", shortPostID(post.ID), post.Title)
+		fmt.Printf("   starred %v\n", post.SavedAt.Format(time.DateTime))
+	}
+	return nil
+}
+
+// Later Handler
+// later                    lists the read later queue, oldest first
+// later <post>             adds the post to the queue
+// later --remove <post>    removes the post from the queue
+func LaterHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	remove := fs.Bool("remove", false, "remove the post from the queue")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	// List the queue
+	if len(args) == 0 {
+		if *remove {
+			return fmt.Errorf("you need to provide the post id or url to remove")
+		}
+
+		posts, err := s.Db.GetReadLaterPostsForUser(context.Background(), user.ID)
+		if err != nil {
+			return fmt.Errorf("error fetching read later posts: %w", err)
+		}
+
+		fmt.Println("Read later : ")
+		for _, post := range posts {
+			fmt.Printf(" * [%v] %v\n", shortPostID(post.ID), post.Title)
+			fmt.Printf("   saved %v\n", post.SavedAt.Format(time.DateTime))
+		}
+		return nil
+	}
+
+	if *remove {
+		post, err := unsavePost(s, user, args[0], constants.SAVED_KIND_LATER)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed from read later: %v\n", post.Title)
+		return nil
+	}
+
+	post, err := savePost(s, user, args[0], constants.SAVED_KIND_LATER)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved for later: %v\n", post.Title)
+	return nil
+}
+
+// Save the post referenced on the command line
+func savePost(s *State, user database.User, ref string, kind string) (database.Post, error) {
+	post, err := findPost(s, ref)
+	if err != nil {
+		return database.Post{}, err
+	}
+
+	params := database.SavePostParams{
+		UserID:  user.ID,
+		PostID:  post.ID,
+		Kind:    kind,
+		SavedAt: time.Now(),
+	}
+	if err := s.Db.SavePost(context.Background(), params); err != nil {
+		return database.Post{}, fmt.Errorf("error saving post: %w", err)
+	}
+
+	return post, nil
+}
+
+// Remove the post referenced on the command line from the saved posts
+func unsavePost(s *State, user database.User, ref string, kind string) (database.Post, error) {
+	post, err := findPost(s, ref)
+	if err != nil {
+		return database.Post{}, err
+	}
+
+	params := database.UnsavePostParams{
+		UserID: user.ID,
+		PostID: post.ID,
+		Kind:   kind,
+	}
+	result, err := s.Db.UnsavePost(context.Background(), params)
+	if err != nil {
+		return database.Post{}, fmt.Errorf("error removing saved post: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return database.Post{}, fmt.Errorf("error removing saved post: %w", err)
+	}
+	if rowsAffected == 0 {
+		return database.Post{}, fmt.Errorf("post %v was not saved", post.Title)
+	}
+
+	return post, nil
+}
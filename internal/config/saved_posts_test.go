@@ -0,0 +1,68 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStarAndUnstar(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	feed := createTestFeed(t, s, alice, "Blog", "https://blog.example.com/feed", "First", "Second")
+
+	runAs(t, s, alice, StarHandler, "star", feed.Url+"/0")
+	// Starring twice keeps one star
+	runAs(t, s, alice, StarHandler, "star", feed.Url+"/0")
+	saved := runAs(t, s, alice, SavedHandler, "saved")
+	if strings.Count(saved, "First") != 1 || strings.Contains(saved, "Second") {
+		t.Errorf("saved should list First once, got:\n%v", saved)
+	}
+	if strings.Contains(runAs(t, s, bob, SavedHandler, "saved"), "First") {
+		t.Error("alice's star shows up in bob's saved posts")
+	}
+
+	runAs(t, s, alice, UnstarHandler, "unstar", feed.Url+"/0")
+	if strings.Contains(runAs(t, s, alice, SavedHandler, "saved"), "First") {
+		t.Error("saved still lists the unstarred post")
+	}
+	if _, err := captureStdout(t, func() error {
+		return UnstarHandler(s, Command{Name: "unstar", Arguments: []string{feed.Url + "/0"}}, alice)
+	}); err == nil || !strings.Contains(err.Error(), "was not saved") {
+		t.Errorf("unstarring a post that isn't starred returned %v", err)
+	}
+}
+
+func TestReadingAPostTakesItOffReadLater(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "Blog", "https://blog.example.com/feed", "First", "Second")
+
+	runAs(t, s, alice, LaterHandler, "later", feed.Url+"/0")
+	runAs(t, s, alice, LaterHandler, "later", feed.Url+"/1")
+	runAs(t, s, alice, StarHandler, "star", feed.Url+"/0")
+	queue := runAs(t, s, alice, LaterHandler, "later")
+	if !strings.Contains(queue, "First") || !strings.Contains(queue, "Second") {
+		t.Fatalf("read later should list both posts, got:\n%v", queue)
+	}
+
+	// Reading leaves the star alone
+	runAs(t, s, alice, ReadHandler, "read", feed.Url+"/0")
+	queue = runAs(t, s, alice, LaterHandler, "later")
+	if strings.Contains(queue, "First") || !strings.Contains(queue, "Second") {
+		t.Errorf("read later should only list Second after reading First, got:\n%v", queue)
+	}
+	if !strings.Contains(runAs(t, s, alice, SavedHandler, "saved"), "First") {
+		t.Error("reading a post removed its star")
+	}
+
+	runAs(t, s, alice, LaterHandler, "later", "--remove", feed.Url+"/1")
+	if strings.Contains(runAs(t, s, alice, LaterHandler, "later"), "Second") {
+		t.Error("later --remove left the post in the queue")
+	}
+	if _, err := captureStdout(t, func() error {
+		return LaterHandler(s, Command{Name: "later", Arguments: []string{"--remove", feed.Url + "/1"}}, alice)
+	}); err == nil || !strings.Contains(err.Error(), "was not saved") {
+		t.Errorf("removing a post that isn't queued returned %v", err)
+	}
+}
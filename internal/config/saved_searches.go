@@ -0,0 +1,233 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/network"
+)
+
+// Notification targets of saved searches
+const NOTIFY_STDOUT = "stdout"
+const NOTIFY_FILE_PREFIX = "file:"
+const NOTIFY_WEBHOOK_PREFIX = "webhook:"
+
+// Saved Searches Handler
+// searches add <name> <query> [--notify <target>]
+// searches list
+// searches remove <name>
+// searches run <name>
+// Targets are "stdout" (the agg output), "file:<path>" or "webhook:<url>".
+// Only admins may use file and webhook targets.
+func SavedSearchesHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	notify := fs.String("notify", NOTIFY_STDOUT, "stdout, file:<path> or webhook:<url>")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("usage: searches add|list|remove|run")
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: searches add <name> <query> [--notify <target>]")
+		}
+		return addSavedSearch(s, user, args[1], strings.Join(args[2:], " "), *notify)
+	case "list":
+		return listSavedSearches(s, user)
+	case "remove":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: searches remove <name>")
+		}
+		return removeSavedSearch(s, user, args[1])
+	case "run":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: searches run <name>")
+		}
+		savedSearch, err := s.Db.GetSavedSearchByName(context.Background(), database.GetSavedSearchByNameParams{
+			UserID: user.ID,
+			Name:   args[1],
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching saved search: %w", err)
+		}
+		return printSearchResults(s, database.SearchPostsParams{
+			QueryText:    savedSearch.Query,
+			FollowedOnly: true,
+			UserID:       user.ID,
+			ResultLimit:  DEFAULT_SEARCH_LIMIT,
+		})
+	default:
+		return fmt.Errorf("unknown subcommand %v, expected add, list, remove or run", args[0])
+	}
+}
+
+// Save a search for the user
+func addSavedSearch(s *State, user database.User, name string, query string, target string) error {
+	if err := validateNotifyTarget(user, target); err != nil {
+		return err
+	}
+
+	params := database.CreateSavedSearchParams{
+		ID:           uuid.New(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		UserID:       user.ID,
+		Name:         name,
+		Query:        query,
+		NotifyTarget: target,
+	}
+	savedSearch, err := s.Db.CreateSavedSearch(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error saving search: %w", err)
+	}
+
+	fmt.Printf("Saved search %v: %v (notify %v)\n", savedSearch.Name, savedSearch.Query, savedSearch.NotifyTarget)
+	return nil
+}
+
+// Print the saved searches of the user
+func listSavedSearches(s *State, user database.User) error {
+	savedSearches, err := s.Db.GetSavedSearchesForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching saved searches: %w", err)
+	}
+
+	fmt.Println("Saved searches:")
+	for _, savedSearch := range savedSearches {
+		fmt.Printf("  * %v: %v (notify %v)\n", savedSearch.Name, savedSearch.Query, savedSearch.NotifyTarget)
+	}
+	return nil
+}
+
+// Delete a saved search of the user
+func removeSavedSearch(s *State, user database.User, name string) error {
+	params := database.DeleteSavedSearchParams{
+		UserID: user.ID,
+		Name:   name,
+	}
+	result, err := s.Db.DeleteSavedSearch(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error deleting saved search: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error deleting saved search: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no saved search named %v", name)
+	}
+
+	fmt.Printf("Removed saved search %v\n", name)
+	return nil
+}
+
+// Check the notification target of a saved search. Files are written and
+// webhooks are called by agg, so only admins may use them, and webhooks must
+// point at public addresses.
+func validateNotifyTarget(user database.User, target string) error {
+	switch {
+	case target == NOTIFY_STDOUT:
+		return nil
+	case strings.HasPrefix(target, NOTIFY_FILE_PREFIX) && len(target) > len(NOTIFY_FILE_PREFIX):
+		return requireAdmin(user)
+	case strings.HasPrefix(target, NOTIFY_WEBHOOK_PREFIX+"http://"), strings.HasPrefix(target, NOTIFY_WEBHOOK_PREFIX+"https://"):
+		if err := requireAdmin(user); err != nil {
+			return err
+		}
+		webhookURL, err := url.Parse(strings.TrimPrefix(target, NOTIFY_WEBHOOK_PREFIX))
+		if err != nil || webhookURL.Hostname() == "" {
+			return fmt.Errorf("invalid webhook url %v", strings.TrimPrefix(target, NOTIFY_WEBHOOK_PREFIX))
+		}
+		return network.CheckPublicHost(context.Background(), webhookURL.Hostname())
+	default:
+		return fmt.Errorf("invalid notification target %v, expected stdout, file:<path> or webhook:<url>", target)
+	}
+}
+
+// Match newly inserted posts against every saved search and notify once per post
+func evaluateSavedSearches(s *State, postIDs []uuid.UUID) {
+	if len(postIDs) == 0 {
+		return
+	}
+
+	matches, err := s.Db.GetSavedSearchMatches(context.Background(), postIDs)
+	if err != nil {
+		fmt.Printf("Error evaluating saved searches: %v\n", err)
+		return
+	}
+
+	for _, match := range matches {
+		// Only the first time a post matches a search is recorded
+		params := database.RecordSavedSearchMatchParams{
+			SavedSearchID: match.SavedSearchID,
+			PostID:        match.PostID,
+			MatchedAt:     time.Now(),
+		}
+		recorded, err := s.Db.RecordSavedSearchMatch(context.Background(), params)
+		if err != nil {
+			fmt.Printf("Error recording saved search match: %v\n", err)
+			continue
+		}
+		if recorded == 0 {
+			continue
+		}
+
+		if err := notifySavedSearchMatch(s, match); err != nil {
+			fmt.Printf("Error notifying %v of saved search %v: %v\n", match.UserName, match.SavedSearchName, err)
+		}
+	}
+}
+
+// Send the alert for a match to the saved search's target
+func notifySavedSearchMatch(s *State, match database.GetSavedSearchMatchesRow) error {
+	message := fmt.Sprintf("[%v] %v matched in %v: %v %v", match.SavedSearchName, match.UserName, match.FeedName, match.PostTitle, match.PostUrl)
+
+	// Targets saved before only admins could add them, or whose owner is no longer an admin
+	if strings.HasPrefix(match.NotifyTarget, NOTIFY_FILE_PREFIX) || strings.HasPrefix(match.NotifyTarget, NOTIFY_WEBHOOK_PREFIX) {
+		if match.UserRole != constants.ROLE_ADMIN {
+			return fmt.Errorf("only admins can notify %v, change the target of the saved search", match.NotifyTarget)
+		}
+	}
+
+	switch target := match.NotifyTarget; {
+	case strings.HasPrefix(target, NOTIFY_FILE_PREFIX):
+		file, err := os.OpenFile(strings.TrimPrefix(target, NOTIFY_FILE_PREFIX), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("error opening alert file: %w", err)
+		}
+		defer file.Close()
+
+		line := fmt.Sprintf("%v %v\n", time.Now().Format(time.RFC3339), message)
+		if _, err := file.WriteString(line); err != nil {
+			return fmt.Errorf("error writing alert file: %w", err)
+		}
+		return nil
+	case strings.HasPrefix(target, NOTIFY_WEBHOOK_PREFIX):
+		// "text" is understood by Slack and Mattermost style incoming webhooks
+		payload := map[string]string{
+			"text":   message,
+			"search": match.SavedSearchName,
+			"user":   match.UserName,
+			"feed":   match.FeedName,
+			"title":  match.PostTitle,
+			"url":    match.PostUrl,
+		}
+		return s.Client.PostJSON(context.Background(), strings.TrimPrefix(target, NOTIFY_WEBHOOK_PREFIX), payload)
+	default:
+		fmt.Printf("Alert %v\n", message)
+		return nil
+	}
+}
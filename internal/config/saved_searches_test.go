@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+func TestSavedSearchAlertsOncePerPost(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	goFeed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed")
+	rustFeed := createTestFeed(t, s, alice, "Rust Blog", "https://rust.example.com/feed")
+
+	runAs(t, s, alice, FollowHandler, "follow", goFeed.Url)
+	runAs(t, s, alice, SavedSearchesHandler, "searches", "add", "generics", "generics")
+
+	matching := createTestPost(t, s, goFeed, database.CreatePostParams{Title: "Generics in practice"})
+	other := createTestPost(t, s, goFeed, database.CreatePostParams{Title: "Garbage collector tuning"})
+	unfollowed := createTestPost(t, s, rustFeed, database.CreatePostParams{Title: "Generics in Rust"})
+	postIDs := []uuid.UUID{matching.ID, other.ID, unfollowed.ID}
+
+	output, _ := captureStdout(t, func() error {
+		evaluateSavedSearches(s, postIDs)
+		return nil
+	})
+	if strings.Count(output, "Alert ") != 1 || !strings.Contains(output, "Generics in practice") {
+		t.Errorf("first evaluation should alert only the followed matching post, got:\n%v", output)
+	}
+
+	// Posts seen again, e.g. after being pushed and then polled, don't alert twice
+	output, _ = captureStdout(t, func() error {
+		evaluateSavedSearches(s, postIDs)
+		return nil
+	})
+	if strings.Contains(output, "Alert ") {
+		t.Errorf("second evaluation alerted again:\n%v", output)
+	}
+}
+
+func TestSavedSearchFileTargetsNeedAnAdmin(t *testing.T) {
+	s := newTestState(t)
+	member := createTestUser(t, s, "member")
+	admin := makeTestAdmin(t, s, createTestUser(t, s, "admin"))
+	feed := createTestFeed(t, s, admin, "Go Blog", "https://go.example.com/feed")
+	alerts := filepath.Join(t.TempDir(), "alerts.log")
+
+	if _, err := captureStdout(t, func() error {
+		return SavedSearchesHandler(s, Command{Name: "searches", Arguments: []string{"add", "generics", "generics", "--notify", NOTIFY_FILE_PREFIX + alerts}}, member)
+	}); err == nil {
+		t.Error("a member saved a search writing to a file")
+	}
+
+	runAs(t, s, admin, FollowHandler, "follow", feed.Url)
+	runAs(t, s, admin, SavedSearchesHandler, "searches", "add", "generics", "generics", "--notify", NOTIFY_FILE_PREFIX+alerts)
+
+	post := createTestPost(t, s, feed, database.CreatePostParams{Title: "Generics in practice"})
+	captureStdout(t, func() error {
+		evaluateSavedSearches(s, []uuid.UUID{post.ID})
+		return nil
+	})
+
+	content, err := os.ReadFile(alerts)
+	if err != nil {
+		t.Fatalf("error reading alert file: %v", err)
+	}
+	if !strings.Contains(string(content), "Generics in practice") {
+		t.Errorf("alert file doesn't mention the post:\n%s", content)
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Number of search results shown by default
+const DEFAULT_SEARCH_LIMIT = 10
+
+// Search Handler
+// search <query> [--following] [--feed <url|name>] [--limit <n>].
+// The query supports "quoted phrases", OR and -excluded words.
+func SearchHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	following := fs.Bool("following", false, "only search the followed feeds")
+	feedRef := fs.String("feed", "", "only search this feed, by url or followed feed name")
+	limit := fs.Int("limit", DEFAULT_SEARCH_LIMIT, "maximum number of results")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+	if err := checkFlagLimit(*limit); err != nil {
+		return err
+	}
+
+	query := strings.TrimSpace(strings.Join(args, " "))
+	if query == "" {
+		return fmt.Errorf("you need to provide a search query")
+	}
+
+	params := database.SearchPostsParams{
+		QueryText:    query,
+		FollowedOnly: *following,
+		UserID:       user.ID,
+		ResultLimit:  int32(*limit),
+	}
+
+	if *feedRef != "" {
+		feedID, err := findSearchFeed(s, user, *feedRef)
+		if err != nil {
+			return err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feedID, Valid: true}
+	}
+
+	return printSearchResults(s, params)
+}
+
+// Run the search and print the results
+func printSearchResults(s *State, params database.SearchPostsParams) error {
+	query := params.QueryText
+	results, err := s.Db.SearchPosts(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error searching posts: %w", err)
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No posts found for %v\n", strconv.Quote(query))
+		return nil
+	}
+
+	fmt.Printf("Posts matching %v : \n", strconv.Quote(query))
+	for _, result := range results {
+		fmt.Printf(" * [%v] %v\n", shortPostID(result.ID), result.Title)
+		fmt.Printf("   %v | %v (%v)\n", result.FeedName, relativeTime(result.PublishedAt, time.Now()), result.PublishedAt.Local().Format("Mon, 02 Jan 2006 15:04"))
+		fmt.Printf("   %v\n", result.Url)
+		if snippet := stripHTML(result.Snippet); snippet != "" {
+			for _, line := range strings.Split(wrapText(snippet, WRAP_WIDTH), "\n") {
+				fmt.Printf("   %v\n", line)
+			}
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// Find the feed to search by url, or by name among the followed feeds
+func findSearchFeed(s *State, user database.User, ref string) (uuid.UUID, error) {
+	if feed, err := s.Db.GetFeedByUrl(context.Background(), ref); err == nil {
+		return feed.ID, nil
+	}
+	return findFollowedFeed(s, user, ref)
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Titles of the search results in order
+func searchTitles(t *testing.T, s *State, params database.SearchPostsParams) []string {
+	t.Helper()
+	if params.ResultLimit == 0 {
+		params.ResultLimit = DEFAULT_SEARCH_LIMIT
+	}
+	results, err := s.Db.SearchPosts(context.Background(), params)
+	if err != nil {
+		t.Fatalf("search %q: %v", params.QueryText, err)
+	}
+	titles := []string{}
+	for _, result := range results {
+		titles = append(titles, result.Title)
+	}
+	return titles
+}
+
+func TestSearchMatchesAndRanksPosts(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed")
+	createTestPost(t, s, feed, database.CreatePostParams{
+		Title:       "Release notes",
+		Description: "What changed in the compiler",
+		Content:     "Generics are faster to compile now.",
+	})
+	createTestPost(t, s, feed, database.CreatePostParams{
+		Title:       "Generics in practice",
+		Description: "Writing generic containers",
+	})
+	createTestPost(t, s, feed, database.CreatePostParams{
+		Title:       "Garbage collector tuning",
+		Description: "Lower latency for large heaps",
+	})
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// Title matches rank above content matches, and words are stemmed
+		{"generic", []string{"Generics in practice", "Release notes"}},
+		{`"large heaps"`, []string{"Garbage collector tuning"}},
+		{`"heaps large"`, []string{}},
+		{"generics -compiler", []string{"Generics in practice"}},
+		{"rust", []string{}},
+	}
+	for _, test := range tests {
+		got := searchTitles(t, s, database.SearchPostsParams{QueryText: test.query, UserID: alice.ID})
+		if len(got) != len(test.want) {
+			t.Errorf("search %q = %q, want %q", test.query, got, test.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("search %q = %q, want %q", test.query, got, test.want)
+				break
+			}
+		}
+	}
+
+	if got := searchTitles(t, s, database.SearchPostsParams{QueryText: "generics OR latency", UserID: alice.ID}); len(got) != 3 {
+		t.Errorf("search %q = %q, want every post", "generics OR latency", got)
+	}
+}
+
+func TestSearchFollowingAndFeedScopes(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	goFeed := createTestFeed(t, s, bob, "Go Blog", "https://go.example.com/feed", "Go concurrency patterns")
+	createTestFeed(t, s, bob, "Rust Blog", "https://rust.example.com/feed", "Rust concurrency patterns")
+
+	if _, err := captureStdout(t, func() error {
+		return FollowHandler(s, Command{Name: "follow", Arguments: []string{goFeed.Url}}, alice)
+	}); err != nil {
+		t.Fatalf("follow: %v", err)
+	}
+
+	all := searchTitles(t, s, database.SearchPostsParams{QueryText: "concurrency", UserID: alice.ID})
+	if len(all) != 2 {
+		t.Errorf("search over every feed = %q, want both posts", all)
+	}
+
+	following := searchTitles(t, s, database.SearchPostsParams{QueryText: "concurrency", UserID: alice.ID, FollowedOnly: true})
+	if strings.Join(following, ",") != "Go concurrency patterns" {
+		t.Errorf("search --following = %q, want only the followed feed's post", following)
+	}
+
+	output, err := captureStdout(t, func() error {
+		return SearchHandler(s, Command{Name: "search", Arguments: []string{"concurrency", "--feed", "Go Blog"}}, alice)
+	})
+	if err != nil {
+		t.Fatalf("search --feed: %v", err)
+	}
+	expectTitles(t, output, []string{"Go concurrency patterns"}, []string{"Rust concurrency patterns"})
+
+	if _, err := captureStdout(t, func() error {
+		return SearchHandler(s, Command{Name: "search", Arguments: []string{"concurrency", "--limit", "0"}}, alice)
+	}); err == nil {
+		t.Error("search --limit 0 was accepted")
+	}
+}
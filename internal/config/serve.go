@@ -0,0 +1,99 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Address gator serve listens on by default, local only
+const DEFAULT_SERVE_ADDR = "localhost:8080"
+
+// Time given to open requests when the server stops
+const SERVE_SHUTDOWN_TIMEOUT = 10 * time.Second
+
+// Serve Handler
+// serve [--addr <host:port>] [--insecure]
+// Serves the html interface and the json api under /api, described at
+// /api/openapi.json, until interrupted. Every request needs an api token,
+// as a bearer token or basic auth password, and acts as the token's user.
+// --insecure lets requests without a token act as the user in their path.
+func ServeHandler(s *State, cmd Command) error {
+	fs := newFlagSet(cmd.Name)
+	addr := fs.String("addr", DEFAULT_SERVE_ADDR, "address to listen on")
+	insecure := fs.Bool("insecure", false, "accept requests without an api token, trusting the user named in the path")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 0 {
+		return fmt.Errorf("usage: serve [--addr <host:port>] [--insecure]")
+	}
+
+	if *insecure {
+		fmt.Println("Warning: --insecure lets anyone reaching the server read and change any user's data")
+	}
+
+	mux := http.NewServeMux()
+	registerAPIRoutes(s, mux)
+	registerWebRoutes(s, mux)
+
+	// Listen before announcing so that address errors are reported
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fmt.Errorf("error starting server: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           logRequests(authenticateRequests(s, !*insecure, mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Stop accepting requests on interrupt and let the open ones finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	shutdownErr := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		fmt.Println("Shutting down")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), SERVE_SHUTDOWN_TIMEOUT)
+		defer cancel()
+		shutdownErr <- server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Printf("Serving on http://%v\n", listener.Addr())
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return fmt.Errorf("error serving: %w", err)
+	}
+	if err := <-shutdownErr; err != nil {
+		return fmt.Errorf("error shutting down: %w", err)
+	}
+	return nil
+}
+
+// Response writer remembering the status code for the request log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Print a line per request with its status and duration
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		fmt.Printf("%v %v %v %v %v\n", start.Format(time.DateTime), r.Method, r.URL.RequestURI(), recorder.status, time.Since(start).Round(time.Microsecond))
+	})
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+const DEFAULT_INBOX_LIMIT = 20
+
+// Share Handler
+// share <post> <username> [note]
+// share --follows on|off
+// Recommends a post to another user, it shows up in their inbox. With
+// --follows, lets others copy the user's follow list or hides it again.
+func ShareHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	follows := fs.String("follows", "", "on lets others copy your follow list, off hides it")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+
+	if *follows != "" {
+		if len(args) != 0 {
+			return fmt.Errorf("usage: share --follows on|off")
+		}
+		return setFollowsPublic(s, user, *follows)
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("usage: share <post> <username> [note]")
+	}
+
+	post, err := findPost(s, args[0])
+	if err != nil {
+		return err
+	}
+
+	recipient, err := s.Db.GetUser(context.Background(), args[1])
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %v not found", args[1])
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching user: %w", err)
+	}
+	if recipient.ID == user.ID {
+		return fmt.Errorf("you can't share a post with yourself")
+	}
+
+	var note sql.NullString
+	if text := strings.TrimSpace(strings.Join(args[2:], " ")); text != "" {
+		note = sql.NullString{String: text, Valid: true}
+	}
+
+	_, err = s.Db.CreateShare(context.Background(), database.CreateShareParams{
+		ID:         uuid.New(),
+		CreatedAt:  time.Now(),
+		PostID:     post.ID,
+		FromUserID: user.ID,
+		ToUserID:   recipient.ID,
+		Note:       note,
+	})
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == constants.ERR_CODE_UNIQUE_CONSTRAINT_VIOLATION {
+		return fmt.Errorf("you already shared this post with %v", recipient.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("error sharing post: %w", err)
+	}
+
+	fmt.Printf("Shared %v with %v\n", post.Title, recipient.Name)
+	return nil
+}
+
+// Inbox Handler
+// inbox [--limit <n>]
+// Lists the posts others shared with the user, newest first.
+func InboxHandler(s *State, cmd Command, user database.User) error {
+	fs := newFlagSet(cmd.Name)
+	limit := fs.Int("limit", DEFAULT_INBOX_LIMIT, "number of shares to show")
+	args, err := parseFlags(fs, cmd.Arguments)
+	if err != nil {
+		return err
+	}
+	if err := checkFlagLimit(*limit); err != nil {
+		return err
+	}
+
+	if len(args) != 0 {
+		return fmt.Errorf("usage: inbox [--limit <n>]")
+	}
+
+	shares, err := s.Db.GetSharesForUser(context.Background(), database.GetSharesForUserParams{
+		ToUserID: user.ID,
+		Limit:    int32(*limit),
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching shared posts: %w", err)
+	}
+
+	now := time.Now()
+	fmt.Println("Shared with you : ")
+	for _, share := range shares {
+		read := ""
+		if share.IsRead {
+			read = " (read)"
+		}
+		fmt.Printf(" * [%v] %v%v\n", shortPostID(share.PostID), share.Title, read)
+		fmt.Printf("   from %v, %v\n", share.FromName, relativeTime(share.CreatedAt, now))
+		if share.Note.Valid {
+			fmt.Printf("   \"%v\"\n", share.Note.String)
+		}
+		fmt.Printf("   %v\n", share.Url)
+	}
+	return nil
+}
+
+// Make the user's follow list visible to others, or hide it again
+func setFollowsPublic(s *State, user database.User, value string) error {
+	var public bool
+	switch value {
+	case "on":
+		public = true
+	case "off":
+		public = false
+	default:
+		return fmt.Errorf("--follows must be on or off")
+	}
+
+	if err := s.Db.SetUserFollowsPublic(context.Background(), database.SetUserFollowsPublicParams{
+		FollowsPublic: public,
+		UpdatedAt:     time.Now(),
+		ID:            user.ID,
+	}); err != nil {
+		return fmt.Errorf("error updating follow list visibility: %w", err)
+	}
+
+	if public {
+		fmt.Printf("Others can now copy your follow list with gator follow --from-user %v\n", user.Name)
+	} else {
+		fmt.Println("Your follow list is private")
+	}
+	return nil
+}
+
+// Follow every feed another user follows, if their follow list is public.
+// Feeds the user already follows are reported and skipped.
+func followFromUser(s *State, user database.User, name string, dryRun bool) error {
+	other, err := s.Db.GetUser(context.Background(), name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("user %v not found", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error fetching user: %w", err)
+	}
+	if other.ID == user.ID {
+		return fmt.Errorf("you can't copy your own follow list")
+	}
+	if !other.FollowsPublic {
+		return fmt.Errorf("%v hasn't made their follow list public", other.Name)
+	}
+
+	theirs, err := s.Db.GetFeedFollowsForUser(context.Background(), other.ID)
+	if err != nil {
+		return fmt.Errorf("error getting feed follows: %w", err)
+	}
+	mine, err := s.Db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("error getting feed follows: %w", err)
+	}
+	following := map[uuid.UUID]bool{}
+	for _, feedFollow := range mine {
+		following[feedFollow.FeedID] = true
+	}
+
+	var followed, skipped []database.GetFeedFollowsForUserRow
+	for _, feedFollow := range theirs {
+		if following[feedFollow.FeedID] {
+			skipped = append(skipped, feedFollow)
+			continue
+		}
+		if !dryRun {
+			_, err := s.Db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now(),
+				UpdatedAt: time.Now(),
+				UserID:    user.ID,
+				FeedID:    feedFollow.FeedID,
+			})
+			// Followed in the meantime
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == constants.ERR_CODE_UNIQUE_CONSTRAINT_VIOLATION {
+				skipped = append(skipped, feedFollow)
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("error following %v: %w", feedFollow.FeedUrl, err)
+			}
+		}
+		followed = append(followed, feedFollow)
+	}
+
+	verb := "Followed"
+	if dryRun {
+		verb = "Would follow"
+	}
+	fmt.Printf("%v %v of %v's %v feeds:\n", verb, len(followed), other.Name, len(theirs))
+	for _, feedFollow := range followed {
+		fmt.Printf("  * %v (%v)\n", feedFollow.FeedName, feedFollow.FeedUrl)
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("Already following %v:\n", len(skipped))
+		for _, feedFollow := range skipped {
+			fmt.Printf("  * %v (%v)\n", feedFollow.FeedName, feedFollow.FeedUrl)
+		}
+	}
+	return nil
+}
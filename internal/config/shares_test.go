@@ -0,0 +1,79 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSharedPostsReachTheInbox(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed", "Go post one")
+	post := "https://go.example.com/feed/0"
+
+	runAs(t, s, alice, ShareHandler, "share", post, "bob", "worth", "a", "read")
+	if _, err := captureStdout(t, func() error {
+		return ShareHandler(s, Command{Name: "share", Arguments: []string{post, "bob"}}, alice)
+	}); err == nil || !strings.Contains(err.Error(), "already shared") {
+		t.Errorf("sharing the post twice returned %v", err)
+	}
+	if _, err := captureStdout(t, func() error {
+		return ShareHandler(s, Command{Name: "share", Arguments: []string{post, "alice"}}, alice)
+	}); err == nil {
+		t.Error("alice shared a post with alice")
+	}
+
+	// Shares show up whether or not bob follows the feed
+	inbox := runAs(t, s, bob, InboxHandler, "inbox")
+	for _, line := range []string{"Go post one\n", "from alice", `"worth a read"`} {
+		if !strings.Contains(inbox, line) {
+			t.Errorf("inbox should contain %q, got:\n%v", line, inbox)
+		}
+	}
+	if strings.Contains(runAs(t, s, alice, InboxHandler, "inbox"), "Go post one") {
+		t.Error("the share shows up in the sender's inbox")
+	}
+
+	runAs(t, s, bob, ReadHandler, "read", post)
+	if inbox := runAs(t, s, bob, InboxHandler, "inbox"); !strings.Contains(inbox, "Go post one (read)") {
+		t.Errorf("inbox doesn't mark the read share:\n%v", inbox)
+	}
+}
+
+func TestFollowFromUserNeedsAPublicList(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	bob := createTestUser(t, s, "bob")
+	goFeed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed", "Go post one")
+	rustFeed := createTestFeed(t, s, alice, "Rust Blog", "https://rust.example.com/feed", "Rust post one")
+	runAs(t, s, alice, FollowHandler, "follow", goFeed.Url)
+	runAs(t, s, alice, FollowHandler, "follow", rustFeed.Url)
+	runAs(t, s, bob, FollowHandler, "follow", goFeed.Url)
+
+	if _, err := captureStdout(t, func() error {
+		return FollowHandler(s, Command{Name: "follow", Arguments: []string{"--from-user", "alice"}}, bob)
+	}); err == nil {
+		t.Error("bob copied a private follow list")
+	}
+
+	runAs(t, s, alice, ShareHandler, "share", "--follows", "on")
+	output := runAs(t, s, bob, FollowHandler, "follow", "--from-user", "alice", "--dry-run")
+	if !strings.Contains(output, "Would follow 1 of alice's 2 feeds") {
+		t.Errorf("follow --from-user --dry-run printed:\n%v", output)
+	}
+	expectTitles(t, browseTitles(t, s, bob), []string{"Go post one"}, []string{"Rust post one"})
+
+	output = runAs(t, s, bob, FollowHandler, "follow", "--from-user", "alice")
+	if !strings.Contains(output, "Followed 1 of alice's 2 feeds") || !strings.Contains(output, "Already following 1") {
+		t.Errorf("follow --from-user printed:\n%v", output)
+	}
+	expectTitles(t, browseTitles(t, s, bob), []string{"Go post one", "Rust post one"}, nil)
+
+	runAs(t, s, alice, ShareHandler, "share", "--follows", "off")
+	if _, err := captureStdout(t, func() error {
+		return FollowHandler(s, Command{Name: "follow", Arguments: []string{"--from-user", "alice"}}, bob)
+	}); err == nil {
+		t.Error("bob copied the follow list after alice hid it")
+	}
+}
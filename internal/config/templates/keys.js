@@ -0,0 +1,35 @@
+// j/k select the next or previous post, o or enter opens it, s stars it,
+// m toggles read, v opens the original and u goes back to the list
+(function () {
+  var posts = Array.prototype.slice.call(document.querySelectorAll(".post"));
+  var current = -1;
+  function select(index) {
+    if (posts.length === 0) return;
+    index = Math.max(0, Math.min(posts.length - 1, index));
+    if (current >= 0) posts[current].classList.remove("selected");
+    current = index;
+    posts[current].classList.add("selected");
+    posts[current].scrollIntoView({ block: "nearest" });
+  }
+  function click(selector) {
+    var scope = current >= 0 ? posts[current] : document;
+    var target = scope.querySelector(selector);
+    if (target) target.click();
+  }
+  document.addEventListener("keydown", function (event) {
+    if (event.ctrlKey || event.metaKey || event.altKey) return;
+    var tag = event.target.tagName;
+    if (tag === "INPUT" || tag === "TEXTAREA" || tag === "SELECT") return;
+    switch (event.key) {
+      case "j": select(current + 1); break;
+      case "k": select(current - 1); break;
+      case "o": case "Enter": click("a.open"); break;
+      case "s": click("button.star"); break;
+      case "m": click("button.read"); break;
+      case "v": click("a.original"); break;
+      case "u": var back = document.querySelector("a.back"); if (back) back.click(); break;
+      default: return;
+    }
+    event.preventDefault();
+  });
+})();
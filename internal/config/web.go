@@ -0,0 +1,492 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+// Number of posts on a page of the html timeline
+const WEB_PAGE_SIZE = 30
+
+// Timeline filters of the html interface
+const WEB_FILTER_UNREAD = "unread"
+const WEB_FILTER_ALL = "all"
+const WEB_FILTER_STARRED = "starred"
+
+//go:embed templates/*.html
+var webTemplateFiles embed.FS
+
+// Keyboard shortcuts of the pages, served as a file so that the content
+// security policy can forbid inline scripts
+//
+//go:embed templates/keys.js
+var webKeysScript []byte
+
+// Page templates, each parsed together with the layout
+var webTemplates = map[string]*template.Template{}
+
+func init() {
+	for _, page := range []string{"index", "timeline", "article", "feeds"} {
+		webTemplates[page] = template.Must(template.ParseFS(webTemplateFiles, "templates/layout.html", "templates/"+page+".html"))
+	}
+}
+
+// A post in the html timeline
+type webPost struct {
+	PostView
+	Read    bool
+	Starred bool
+}
+
+// Register the pages of the html interface on the mux
+func registerWebRoutes(s *State, mux *http.ServeMux) {
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		webIndex(s, w, r)
+	})
+	mux.HandleFunc("GET /keys.js", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		w.Write(webKeysScript)
+	})
+	mux.HandleFunc("GET /u/{name}", func(w http.ResponseWriter, r *http.Request) {
+		webTimeline(s, w, r)
+	})
+	mux.HandleFunc("GET /u/{name}/posts/{post_id}", func(w http.ResponseWriter, r *http.Request) {
+		webArticle(s, w, r)
+	})
+	mux.HandleFunc("POST /u/{name}/posts/{post_id}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		webPostAction(s, w, r)
+	})
+	mux.HandleFunc("GET /u/{name}/feeds", func(w http.ResponseWriter, r *http.Request) {
+		webFeeds(s, w, r, "")
+	})
+	mux.HandleFunc("POST /u/{name}/follows", func(w http.ResponseWriter, r *http.Request) {
+		webFollow(s, w, r)
+	})
+	mux.HandleFunc("POST /u/{name}/follows/{feed_id}/delete", func(w http.ResponseWriter, r *http.Request) {
+		webUnfollow(s, w, r)
+	})
+}
+
+// Render a page, reporting template errors as a server error
+func renderWebPage(w http.ResponseWriter, page string, data map[string]any) {
+	var buffer strings.Builder
+	if err := webTemplates[page].ExecuteTemplate(&buffer, "layout", data); err != nil {
+		fmt.Printf("Error rendering %v page: %v\n", page, err)
+		http.Error(w, "error rendering page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// Article content is sanitized, the policy is a second line of defence
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; img-src * data:; style-src 'unsafe-inline'; script-src 'self'; frame-ancestors 'none'")
+	w.Write([]byte(buffer.String()))
+}
+
+// Report an error on a page
+func webError(w http.ResponseWriter, err error) {
+	var apiErr apiError
+	if errors.As(err, &apiErr) {
+		http.Error(w, apiErr.Message, apiErr.Status)
+		return
+	}
+	fmt.Printf("Error serving page: %v\n", err)
+	http.Error(w, "internal error", http.StatusInternalServerError)
+}
+
+// Forms are only accepted from pages of the same server. Browsers send an
+// Origin with every form post, so posts without Origin or Referer, which
+// can't be told apart from forged ones, are rejected too.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	if origin == "" {
+		return false
+	}
+	parsed, err := url.Parse(origin)
+	return err == nil && parsed.Host != "" && parsed.Host == r.Host
+}
+
+// GET /, lists the users only on --insecure servers, otherwise it opens the
+// token user's timeline
+func webIndex(s *State, w http.ResponseWriter, r *http.Request) {
+	if tokenUser, ok := requestTokenUser(r); ok {
+		http.Redirect(w, r, "/u/"+url.PathEscape(tokenUser.Name), http.StatusSeeOther)
+		return
+	}
+
+	users, err := s.Db.GetUsers(r.Context())
+	if err != nil {
+		webError(w, fmt.Errorf("error fetching users: %w", err))
+		return
+	}
+	renderWebPage(w, "index", map[string]any{"Title": "Users", "Users": users})
+}
+
+// GET /u/{name}?filter=unread|all|starred&feed=<url>&after=<cursor>
+func webTimeline(s *State, w http.ResponseWriter, r *http.Request) {
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		webError(w, err)
+		return
+	}
+
+	values := r.URL.Query()
+	filter := values.Get("filter")
+	if filter == "" {
+		filter = WEB_FILTER_UNREAD
+	}
+	feedRef := values.Get("feed")
+
+	follows, err := s.Db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		webError(w, fmt.Errorf("error fetching feed follows: %w", err))
+		return
+	}
+	starred, err := s.Db.GetStarredPostsForUser(r.Context(), user.ID)
+	if err != nil {
+		webError(w, fmt.Errorf("error fetching starred posts: %w", err))
+		return
+	}
+	starredIDs := map[uuid.UUID]bool{}
+	for _, post := range starred {
+		starredIDs[post.ID] = true
+	}
+
+	var posts []webPost
+	var next string
+	heading := "Unread posts"
+	switch filter {
+	case WEB_FILTER_UNREAD, WEB_FILTER_ALL:
+		query := postQuery{
+			All:   filter == WEB_FILTER_ALL,
+			Feed:  feedRef,
+			After: values.Get("after"),
+			Limit: WEB_PAGE_SIZE,
+		}
+		params, err := query.params(s, user)
+		if err != nil {
+			webError(w, badRequest(err))
+			return
+		}
+		rows, err := s.Db.GetPostsForUser(r.Context(), params)
+		if err != nil {
+			webError(w, fmt.Errorf("error fetching posts: %w", err))
+			return
+		}
+		for _, row := range rows {
+			posts = append(posts, webPost{
+				PostView: newPostView(row.ID, row.Title, row.Url, row.Description, row.PublishedAt, row.FeedName),
+				Read:     row.IsRead,
+				Starred:  starredIDs[row.ID],
+			})
+		}
+		next = nextPostCursor(params, rows)
+		if filter == WEB_FILTER_ALL {
+			heading = "All posts"
+		}
+	case WEB_FILTER_STARRED:
+		// Starred posts are listed whole, like gator saved
+		feedNames := map[uuid.UUID]string{}
+		var feedID uuid.UUID
+		for _, follow := range follows {
+			feedNames[follow.FeedID] = follow.FeedName
+			if feedRef != "" && (follow.FeedUrl == feedRef || follow.FeedName == feedRef) {
+				feedID = follow.FeedID
+			}
+		}
+		for _, post := range starred {
+			if feedRef != "" && post.FeedID != feedID {
+				continue
+			}
+			posts = append(posts, webPost{
+				PostView: newPostView(post.ID, post.Title, post.Url, post.Description, post.PublishedAt, feedNames[post.FeedID]),
+				Starred:  true,
+			})
+		}
+		heading = "Starred posts"
+	default:
+		webError(w, badRequest(fmt.Errorf("unknown filter %v", filter)))
+		return
+	}
+
+	renderWebPage(w, "timeline", map[string]any{
+		"Title":   heading,
+		"Heading": heading,
+		"User":    user,
+		"Follows": follows,
+		"Filters": []string{WEB_FILTER_UNREAD, WEB_FILTER_ALL, WEB_FILTER_STARRED},
+		"Filter":  filter,
+		"Feed":    feedRef,
+		"Posts":   posts,
+		"Next":    next,
+		"Return":  r.URL.RequestURI(),
+	})
+}
+
+// GET /u/{name}/posts/{post_id}. Opening a post marks it as read.
+func webArticle(s *State, w http.ResponseWriter, r *http.Request) {
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		webError(w, err)
+		return
+	}
+	post, err := apiPathPost(s, r)
+	if err != nil {
+		webError(w, err)
+		return
+	}
+
+	// Opening a post doesn't change it, marking it read goes through the form
+	isRead, err := s.Db.IsPostRead(r.Context(), database.IsPostReadParams{
+		UserID: user.ID,
+		PostID: post.ID,
+	})
+	if err != nil {
+		webError(w, fmt.Errorf("error fetching read state: %w", err))
+		return
+	}
+
+	follows, err := s.Db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		webError(w, fmt.Errorf("error fetching feed follows: %w", err))
+		return
+	}
+	var feedName string
+	for _, follow := range follows {
+		if follow.FeedID == post.FeedID {
+			feedName = follow.FeedName
+		}
+	}
+
+	starred, err := s.Db.GetStarredPostsForUser(r.Context(), user.ID)
+	if err != nil {
+		webError(w, fmt.Errorf("error fetching starred posts: %w", err))
+		return
+	}
+	isStarred := false
+	for _, starredPost := range starred {
+		if starredPost.ID == post.ID {
+			isStarred = true
+		}
+	}
+
+	// Prefer the full content over the summary
+	body := post.Content
+	if strings.TrimSpace(body) == "" {
+		body = post.Description
+	}
+
+	renderWebPage(w, "article", map[string]any{
+		"Title":    post.Title,
+		"User":     user,
+		"Post":     post,
+		"FeedName": feedName,
+		"Read":     isRead,
+		"Starred":  isStarred,
+		"Body":     sanitizeHTML(body, post.Url),
+	})
+}
+
+// POST /u/{name}/posts/{post_id}/{action}, action is read, unread, star or unstar
+func webPostAction(s *State, w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "cross origin form rejected", http.StatusForbidden)
+		return
+	}
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		webError(w, err)
+		return
+	}
+	post, err := apiPathPost(s, r)
+	if err != nil {
+		webError(w, err)
+		return
+	}
+
+	if err := setPostState(r.Context(), s, user, post, r.PathValue("action")); err != nil {
+		webError(w, err)
+		return
+	}
+	redirectBack(w, r, "/u/"+url.PathEscape(user.Name))
+}
+
+// Change the read or starred state of a post, shared by the pages and the api
+func setPostState(ctx context.Context, s *State, user database.User, post database.Post, action string) error {
+	switch action {
+	case "read":
+		params := database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+			ReadAt: time.Now(),
+		}
+		if err := s.Db.MarkPostRead(ctx, params); err != nil {
+			return fmt.Errorf("error marking post as read: %w", err)
+		}
+		// Reading a post takes it off the read later queue
+		unsaveParams := database.UnsavePostParams{
+			UserID: user.ID,
+			PostID: post.ID,
+			Kind:   constants.SAVED_KIND_LATER,
+		}
+		if _, err := s.Db.UnsavePost(ctx, unsaveParams); err != nil {
+			return fmt.Errorf("error removing post from read later: %w", err)
+		}
+	case "unread":
+		params := database.MarkPostUnreadParams{
+			UserID: user.ID,
+			PostID: post.ID,
+		}
+		if _, err := s.Db.MarkPostUnread(ctx, params); err != nil {
+			return fmt.Errorf("error marking post as unread: %w", err)
+		}
+	case "star":
+		params := database.SavePostParams{
+			UserID:  user.ID,
+			PostID:  post.ID,
+			Kind:    constants.SAVED_KIND_STAR,
+			SavedAt: time.Now(),
+		}
+		if err := s.Db.SavePost(ctx, params); err != nil {
+			return fmt.Errorf("error starring post: %w", err)
+		}
+	case "unstar":
+		params := database.UnsavePostParams{
+			UserID: user.ID,
+			PostID: post.ID,
+			Kind:   constants.SAVED_KIND_STAR,
+		}
+		if _, err := s.Db.UnsavePost(ctx, params); err != nil {
+			return fmt.Errorf("error unstarring post: %w", err)
+		}
+	default:
+		return notFound("unknown action %v", action)
+	}
+	return nil
+}
+
+// Go back to the page a form was sent from. Only local paths are followed.
+func redirectBack(w http.ResponseWriter, r *http.Request, fallback string) {
+	target := r.FormValue("return")
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		target = fallback
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// GET /u/{name}/feeds
+func webFeeds(s *State, w http.ResponseWriter, r *http.Request, formError string) {
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		webError(w, err)
+		return
+	}
+
+	feeds, err := s.Db.GetFeedsWithUsername(r.Context())
+	if err != nil {
+		webError(w, fmt.Errorf("error fetching feeds: %w", err))
+		return
+	}
+	follows, err := s.Db.GetFeedFollowsForUser(r.Context(), user.ID)
+	if err != nil {
+		webError(w, fmt.Errorf("error fetching feed follows: %w", err))
+		return
+	}
+	following := map[uuid.UUID]bool{}
+	for _, follow := range follows {
+		following[follow.FeedID] = true
+	}
+
+	if formError != "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	renderWebPage(w, "feeds", map[string]any{
+		"Title":     "Feeds",
+		"User":      user,
+		"Feeds":     feeds,
+		"Following": following,
+		"Error":     formError,
+	})
+}
+
+// POST /u/{name}/follows
+func webFollow(s *State, w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "cross origin form rejected", http.StatusForbidden)
+		return
+	}
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		webError(w, err)
+		return
+	}
+
+	feedURL := strings.TrimSpace(r.FormValue("url"))
+	feed, err := s.Db.GetFeedByUrl(r.Context(), feedURL)
+	if errors.Is(err, sql.ErrNoRows) {
+		webFeeds(s, w, r, fmt.Sprintf("No feed with url %v, add it with gator addfeed first", feedURL))
+		return
+	}
+	if err != nil {
+		webError(w, fmt.Errorf("error fetching feed: %w", err))
+		return
+	}
+
+	params := database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	}
+	if _, err := s.Db.CreateFeedFollow(r.Context(), params); err != nil {
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) || pqErr.Code != constants.ERR_CODE_UNIQUE_CONSTRAINT_VIOLATION {
+			webError(w, fmt.Errorf("error creating feed follow: %w", err))
+			return
+		}
+	}
+	http.Redirect(w, r, "/u/"+url.PathEscape(user.Name)+"/feeds", http.StatusSeeOther)
+}
+
+// POST /u/{name}/follows/{feed_id}/delete
+func webUnfollow(s *State, w http.ResponseWriter, r *http.Request) {
+	if !sameOrigin(r) {
+		http.Error(w, "cross origin form rejected", http.StatusForbidden)
+		return
+	}
+	user, err := apiPathUser(s, r)
+	if err != nil {
+		webError(w, err)
+		return
+	}
+	feedID, err := uuid.Parse(r.PathValue("feed_id"))
+	if err != nil {
+		webError(w, badRequest(fmt.Errorf("invalid feed id: %w", err)))
+		return
+	}
+
+	params := database.DeleteFeedFollowParams{
+		UserID: user.ID,
+		FeedID: feedID,
+	}
+	if _, err := s.Db.DeleteFeedFollow(r.Context(), params); err != nil {
+		webError(w, fmt.Errorf("error deleting feed follow: %w", err))
+		return
+	}
+	http.Redirect(w, r, "/u/"+url.PathEscape(user.Name)+"/feeds", http.StatusSeeOther)
+}
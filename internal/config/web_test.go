@@ -0,0 +1,157 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+)
+
+func TestSameOriginFailsClosed(t *testing.T) {
+	tests := []struct {
+		name    string
+		origin  string
+		referer string
+		want    bool
+	}{
+		{"same origin", "http://gator.example.com", "", true},
+		{"same referer", "", "http://gator.example.com/u/alice", true},
+		{"other origin", "https://evil.example.com", "http://gator.example.com/u/alice", false},
+		{"other referer", "", "https://evil.example.com/page", false},
+		{"opaque origin", "null", "", false},
+		{"neither", "", "", false},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("POST", "http://gator.example.com/u/alice/follows", nil)
+		if test.origin != "" {
+			r.Header.Set("Origin", test.origin)
+		}
+		if test.referer != "" {
+			r.Header.Set("Referer", test.referer)
+		}
+		if got := sameOrigin(r); got != test.want {
+			t.Errorf("%v: sameOrigin = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWebPagesRunNoInlineScript(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	renderWebPage(recorder, "index", map[string]any{"Title": "Users"})
+
+	policy := recorder.Header().Get("Content-Security-Policy")
+	if !strings.Contains(policy, "script-src 'self'") || strings.Contains(policy, "script-src 'unsafe-inline'") {
+		t.Errorf("content security policy %q allows inline scripts", policy)
+	}
+	if body := recorder.Body.String(); strings.Contains(body, "<script>") {
+		t.Errorf("page has an inline script:\n%v", body)
+	}
+
+	mux := http.NewServeMux()
+	registerWebRoutes(&State{}, mux)
+	script := httptest.NewRecorder()
+	mux.ServeHTTP(script, httptest.NewRequest("GET", "/keys.js", nil))
+	if script.Code != http.StatusOK || !strings.Contains(script.Body.String(), "keydown") {
+		t.Errorf("GET /keys.js answered %v, want the shortcuts script", script.Code)
+	}
+}
+
+// Send a request to the web pages with the token as the basic auth password
+func webRequest(t *testing.T, handler http.Handler, token string, method string, target string, form url.Values, origin string) *httptest.ResponseRecorder {
+	t.Helper()
+	var r *http.Request
+	if form != nil {
+		r = httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		r = httptest.NewRequest(method, target, nil)
+	}
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+	r.SetBasicAuth("", token)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, r)
+	return recorder
+}
+
+func TestWebFollowAndMarkRead(t *testing.T) {
+	s := newTestState(t)
+	alice := createTestUser(t, s, "alice")
+	feed := createTestFeed(t, s, alice, "Go Blog", "https://go.example.com/feed")
+	post := createTestPost(t, s, feed, database.CreatePostParams{
+		Title:       "Go post one",
+		Description: `<p>Hello</p><script>alert("hi")</script>`,
+	})
+	token := createTestToken(t, s, alice, "browser", constants.TOKEN_SCOPE_MANAGE_FOLLOWS)
+	handler := newTestServer(s)
+	const origin = "http://example.com"
+
+	if recorder := webRequest(t, handler, token, "POST", "/u/alice/follows", url.Values{"url": {feed.Url}}, ""); recorder.Code != http.StatusForbidden {
+		t.Errorf("follow form without an origin answered %v, want %v", recorder.Code, http.StatusForbidden)
+	}
+	if recorder := webRequest(t, handler, token, "POST", "/u/alice/follows", url.Values{"url": {feed.Url}}, origin); recorder.Code != http.StatusSeeOther {
+		t.Fatalf("follow form answered %v, want %v", recorder.Code, http.StatusSeeOther)
+	}
+
+	recorder := webRequest(t, handler, token, "GET", "/u/alice", nil, "")
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "Go post one") {
+		t.Fatalf("timeline answered %v without the followed post:\n%v", recorder.Code, recorder.Body.String())
+	}
+
+	// Post bodies are sanitized and the page only runs its own script
+	recorder = webRequest(t, handler, token, "GET", "/u/alice/posts/"+post.ID.String(), nil, "")
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "<p>Hello</p>") {
+		t.Fatalf("post page answered %v without its body:\n%v", recorder.Code, recorder.Body.String())
+	}
+	if strings.Contains(recorder.Body.String(), "alert(") {
+		t.Errorf("post page kept the feed's script:\n%v", recorder.Body.String())
+	}
+	if csp := recorder.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "script-src 'self'") {
+		t.Errorf("post page sent Content-Security-Policy %q", csp)
+	}
+
+	readTarget := "/u/alice/posts/" + post.ID.String() + "/read"
+
+	// Opening the post leaves it unread, the page offers the form instead
+	if !strings.Contains(recorder.Body.String(), `action="`+readTarget+`"`) {
+		t.Errorf("post page has no form marking it read:\n%v", recorder.Body.String())
+	}
+	recorder = webRequest(t, handler, token, "GET", "/u/alice", nil, "")
+	if !strings.Contains(recorder.Body.String(), "Go post one") {
+		t.Errorf("opening the post marked it read:\n%v", recorder.Body.String())
+	}
+	readOnly := createTestToken(t, s, alice, "reader", constants.TOKEN_SCOPE_READ_ONLY)
+	if recorder := webRequest(t, handler, readOnly, "GET", "/u/alice/posts/"+post.ID.String(), nil, ""); recorder.Code != http.StatusOK {
+		t.Errorf("post page with a read-only token answered %v, want %v", recorder.Code, http.StatusOK)
+	}
+	if recorder := webRequest(t, handler, readOnly, "POST", readTarget, url.Values{}, origin); recorder.Code != http.StatusForbidden {
+		t.Errorf("read form with a read-only token answered %v, want %v", recorder.Code, http.StatusForbidden)
+	}
+
+	if recorder := webRequest(t, handler, token, "POST", readTarget, url.Values{}, ""); recorder.Code != http.StatusForbidden {
+		t.Errorf("read form without an origin answered %v, want %v", recorder.Code, http.StatusForbidden)
+	}
+	if recorder := webRequest(t, handler, token, "POST", readTarget, url.Values{"return": {"//evil.example.com"}}, origin); recorder.Code != http.StatusSeeOther || recorder.Header().Get("Location") != "/u/alice" {
+		t.Errorf("read form answered %v to %q, want %v to /u/alice", recorder.Code, recorder.Header().Get("Location"), http.StatusSeeOther)
+	}
+	recorder = webRequest(t, handler, token, "GET", "/u/alice", nil, "")
+	if strings.Contains(recorder.Body.String(), "Go post one") {
+		t.Errorf("timeline still shows the read post:\n%v", recorder.Body.String())
+	}
+	recorder = webRequest(t, handler, token, "GET", "/u/alice/posts/"+post.ID.String(), nil, "")
+	if !strings.Contains(recorder.Body.String(), "mark unread") {
+		t.Errorf("post page of a read post doesn't offer to mark it unread:\n%v", recorder.Body.String())
+	}
+
+	// Pages of other users are off limits
+	createTestUser(t, s, "bob")
+	if recorder := webRequest(t, handler, token, "GET", "/u/bob", nil, ""); recorder.Code != http.StatusForbidden {
+		t.Errorf("bob's timeline answered %v, want %v", recorder.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,323 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/constants"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/database"
+	"github.com/zawhtetnaing10/Blog-Aggregator/internal/network"
+)
+
+// Lease requested from hubs when none is configured
+const DEFAULT_WEBSUB_LEASE = 10 * 24 * time.Hour
+
+// Leases expiring within this window are renewed
+const WEBSUB_RENEW_WINDOW = 24 * time.Hour
+
+// Time before a pending or failed subscription is attempted again
+const WEBSUB_RETRY_INTERVAL = time.Hour
+
+// WebSub settings. Both the listen address and the public callback url are
+// required, since hubs need to reach the callback server from outside.
+type WebSubConfig struct {
+	// Address the callback server listens on, e.g. ":8081"
+	ListenAddr string `json:"listen_addr,omitempty"`
+	// Public base url of the callback server, e.g. "https://gator.example.com"
+	CallbackURL string `json:"callback_url,omitempty"`
+	// Lease requested from hubs as a duration string such as "240h"
+	Lease string `json:"lease,omitempty"`
+}
+
+// Whether push subscriptions are enabled
+func (c WebSubConfig) Enabled() bool {
+	return c.ListenAddr != "" && c.CallbackURL != ""
+}
+
+// Lease requested from hubs
+func (c WebSubConfig) lease() time.Duration {
+	lease, err := time.ParseDuration(c.Lease)
+	if err != nil || lease <= 0 {
+		return DEFAULT_WEBSUB_LEASE
+	}
+	return lease
+}
+
+// Callback url for the subscription
+func (c WebSubConfig) callbackFor(id uuid.UUID) string {
+	return strings.TrimSuffix(c.CallbackURL, "/") + network.WEBSUB_CALLBACK_PATH + id.String()
+}
+
+// Start the callback server in the background
+func startWebSubServer(s *State) error {
+	callback := &network.WebSubCallback{
+		Lookup: func(id string) (network.WebSubSubscription, bool) {
+			subscription, err := getWebSubSubscription(s, id)
+			if err != nil {
+				return network.WebSubSubscription{}, false
+			}
+			return network.WebSubSubscription{
+				TopicURL: subscription.TopicUrl,
+				Secret:   subscription.Secret,
+			}, true
+		},
+		OnVerified: func(id string, mode string, lease time.Duration) {
+			onWebSubVerified(s, id, mode, lease)
+		},
+		OnDenied: func(id string, reason string) {
+			onWebSubDenied(s, id, reason)
+		},
+		OnContent: func(id string, feed *network.RSSFeed) {
+			onWebSubContent(s, id, feed)
+		},
+		OnInvalidContent: func(id string, err error) {
+			onWebSubInvalidContent(s, id, err)
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(network.WEBSUB_CALLBACK_PATH, callback)
+
+	// Listen before returning so that address errors are reported
+	listener, err := net.Listen("tcp", s.Config.WebSub.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("error starting websub callback server: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("WebSub callback server stopped: %v\n", err)
+		}
+	}()
+
+	fmt.Printf("Listening for WebSub callbacks on %v\n", s.Config.WebSub.ListenAddr)
+	return nil
+}
+
+// Subscribe to the hub advertised by the fetched feed.
+// Polling continues until the hub verifies the subscription.
+func subscribeToHub(s *State, feed database.Feed, fetched *network.RSSFeed) error {
+	hubURL := fetched.HubURL()
+	if hubURL == "" {
+		return nil
+	}
+
+	topicURL := fetched.SelfURL()
+	if topicURL == "" {
+		topicURL = feed.Url
+	}
+
+	// Skip subscriptions which are active or were attempted recently
+	existing, err := s.Db.GetWebsubSubscriptionByFeed(context.Background(), feed.ID)
+	if err == nil && existing.HubUrl == hubURL && existing.TopicUrl == topicURL {
+		if existing.Status == constants.WEBSUB_STATUS_ACTIVE && existing.LeaseExpiresAt.Valid && existing.LeaseExpiresAt.Time.After(time.Now()) {
+			return nil
+		}
+		if existing.Status != constants.WEBSUB_STATUS_ACTIVE && time.Since(existing.UpdatedAt) < WEBSUB_RETRY_INTERVAL {
+			return nil
+		}
+	} else if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("error fetching subscription: %w", err)
+	}
+
+	secret, err := network.NewWebSubSecret()
+	if err != nil {
+		return err
+	}
+
+	// The secret of an existing subscription is kept on conflict
+	params := database.UpsertWebsubSubscriptionParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		FeedID:    feed.ID,
+		HubUrl:    hubURL,
+		TopicUrl:  topicURL,
+		Secret:    secret,
+		Status:    constants.WEBSUB_STATUS_PENDING,
+	}
+	subscription, err := s.Db.UpsertWebsubSubscription(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error saving subscription: %w", err)
+	}
+
+	return sendWebSubSubscribe(s, subscription)
+}
+
+// Renew the subscriptions whose leases are about to expire
+func renewWebSubLeases(s *State) {
+	expiringBefore := sql.NullTime{
+		Time:  time.Now().Add(WEBSUB_RENEW_WINDOW),
+		Valid: true,
+	}
+	subscriptions, err := s.Db.GetWebsubSubscriptionsToRenew(context.Background(), expiringBefore)
+	if err != nil {
+		fmt.Printf("Error fetching subscriptions to renew: %v\n", err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if err := sendWebSubSubscribe(s, subscription); err != nil {
+			fmt.Printf("Error renewing subscription to %v: %v\n", subscription.TopicUrl, err)
+		}
+	}
+}
+
+// Send the subscribe request, marking the subscription as failed when the
+// hub is unavailable so that the feed falls back to polling
+func sendWebSubSubscribe(s *State, subscription database.WebsubSubscription) error {
+	req := network.WebSubRequest{
+		HubURL:      subscription.HubUrl,
+		TopicURL:    subscription.TopicUrl,
+		CallbackURL: s.Config.WebSub.callbackFor(subscription.ID),
+		Secret:      subscription.Secret,
+		Lease:       s.Config.WebSub.lease(),
+	}
+
+	subscribeErr := s.Client.WebSubSubscribe(context.Background(), req)
+	if subscribeErr == nil {
+		return nil
+	}
+
+	params := database.UpdateWebsubSubscriptionStatusParams{
+		Status:    constants.WEBSUB_STATUS_FAILED,
+		UpdatedAt: time.Now(),
+		ID:        subscription.ID,
+	}
+	if err := s.Db.UpdateWebsubSubscriptionStatus(context.Background(), params); err != nil {
+		return fmt.Errorf("error marking subscription as failed: %w", err)
+	}
+
+	return subscribeErr
+}
+
+// Ask the hub to stop pushing updates and forget the subscription. The hub
+// verifies the request on the callback, which confirms unsubscribing from
+// subscriptions it doesn't know, so the row can be deleted right away.
+func unsubscribeFromHub(s *State, subscription database.WebsubSubscription) error {
+	if s.Config.WebSub.Enabled() {
+		req := network.WebSubRequest{
+			HubURL:      subscription.HubUrl,
+			TopicURL:    subscription.TopicUrl,
+			CallbackURL: s.Config.WebSub.callbackFor(subscription.ID),
+		}
+		// The hub stops on its own once deliveries fail, so carry on
+		if err := s.Client.WebSubUnsubscribe(context.Background(), req); err != nil {
+			fmt.Printf("Error unsubscribing from %v: %v\n", subscription.HubUrl, err)
+		}
+	}
+
+	if err := s.Db.DeleteWebsubSubscription(context.Background(), subscription.ID); err != nil {
+		return fmt.Errorf("error removing websub subscription: %w", err)
+	}
+	return nil
+}
+
+// Get the subscription for the id from a callback url
+func getWebSubSubscription(s *State, id string) (database.WebsubSubscription, error) {
+	parsedID, err := uuid.Parse(id)
+	if err != nil {
+		return database.WebsubSubscription{}, fmt.Errorf("invalid subscription id: %w", err)
+	}
+	return s.Db.GetWebsubSubscription(context.Background(), parsedID)
+}
+
+// Record the lease once the hub verified our intent
+func onWebSubVerified(s *State, id string, mode string, lease time.Duration) {
+	subscription, err := getWebSubSubscription(s, id)
+	if err != nil {
+		return
+	}
+
+	if mode == "unsubscribe" {
+		if err := s.Db.DeleteWebsubSubscription(context.Background(), subscription.ID); err != nil {
+			fmt.Printf("Error deleting subscription: %v\n", err)
+		}
+		return
+	}
+
+	if lease == 0 {
+		lease = s.Config.WebSub.lease()
+	}
+
+	params := database.ActivateWebsubSubscriptionParams{
+		LeaseExpiresAt: sql.NullTime{
+			Time:  time.Now().Add(lease),
+			Valid: true,
+		},
+		UpdatedAt: time.Now(),
+		ID:        subscription.ID,
+	}
+	if err := s.Db.ActivateWebsubSubscription(context.Background(), params); err != nil {
+		fmt.Printf("Error activating subscription: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Subscribed to %v until %v\n", subscription.TopicUrl, params.LeaseExpiresAt.Time.Format(time.RFC1123))
+}
+
+// Fall back to polling when the hub denied the subscription
+func onWebSubDenied(s *State, id string, reason string) {
+	subscription, err := getWebSubSubscription(s, id)
+	if err != nil {
+		return
+	}
+
+	params := database.UpdateWebsubSubscriptionStatusParams{
+		Status:    constants.WEBSUB_STATUS_DENIED,
+		UpdatedAt: time.Now(),
+		ID:        subscription.ID,
+	}
+	if err := s.Db.UpdateWebsubSubscriptionStatus(context.Background(), params); err != nil {
+		fmt.Printf("Error marking subscription as denied: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Hub denied subscription to %v: %v\n", subscription.TopicUrl, reason)
+}
+
+// Save the posts pushed by the hub
+func onWebSubContent(s *State, id string, feed *network.RSSFeed) {
+	subscription, err := getWebSubSubscription(s, id)
+	if err != nil {
+		return
+	}
+
+	if err := savePosts(s, subscription.FeedID, feed.Channel.Item); err != nil {
+		fmt.Printf("Error saving pushed posts from %v: %v\n", subscription.TopicUrl, err)
+		return
+	}
+
+	fmt.Printf("Received %v pushed posts from %v\n", len(feed.Channel.Item), subscription.TopicUrl)
+}
+
+// Fall back to polling when pushed content can't be parsed, so the posts
+// it carried are picked up by the next fetch instead of being lost
+func onWebSubInvalidContent(s *State, id string, contentErr error) {
+	subscription, err := getWebSubSubscription(s, id)
+	if err != nil {
+		return
+	}
+
+	params := database.UpdateWebsubSubscriptionStatusParams{
+		Status:    constants.WEBSUB_STATUS_FAILED,
+		UpdatedAt: time.Now(),
+		ID:        subscription.ID,
+	}
+	if err := s.Db.UpdateWebsubSubscriptionStatus(context.Background(), params); err != nil {
+		fmt.Printf("Error marking subscription as failed: %v\n", err)
+		return
+	}
+
+	fmt.Printf("Error parsing pushed content from %v, polling instead: %v\n", subscription.TopicUrl, contentErr)
+}
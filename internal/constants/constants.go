@@ -1,3 +1,29 @@
 package constants
 
 const ERR_CODE_UNIQUE_CONSTRAINT_VIOLATION = "23505"
+
+// WebSub subscription statuses
+const WEBSUB_STATUS_PENDING = "pending"
+const WEBSUB_STATUS_ACTIVE = "active"
+const WEBSUB_STATUS_FAILED = "failed"
+const WEBSUB_STATUS_DENIED = "denied"
+
+// Kinds of saved posts
+const SAVED_KIND_STAR = "star"
+const SAVED_KIND_LATER = "later"
+
+// Kinds of mute filters
+const MUTE_KIND_KEYWORD = "keyword"
+const MUTE_KIND_REGEX = "regex"
+const MUTE_KIND_AUTHOR = "author"
+const MUTE_KIND_DOMAIN = "domain"
+const MUTE_KIND_CATEGORY = "category"
+
+// Scopes of api tokens, each allowing everything the previous one does
+const TOKEN_SCOPE_READ_ONLY = "read-only"
+const TOKEN_SCOPE_MANAGE_FOLLOWS = "manage-follows"
+const TOKEN_SCOPE_ADMIN = "admin"
+
+// User roles
+const ROLE_ADMIN = "admin"
+const ROLE_MEMBER = "member"
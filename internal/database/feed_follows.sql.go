@@ -23,10 +23,10 @@ WITH inserted_feed_follow AS(
         $4,
         $5
     )
-    RETURNING id, created_at, updated_at, user_id, feed_id
+    RETURNING id, created_at, updated_at, user_id, feed_id, folder_id, display_name, priority, notify, match_filter
 )
 SELECT 
-    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, 
+    inserted_feed_follow.id, inserted_feed_follow.created_at, inserted_feed_follow.updated_at, inserted_feed_follow.user_id, inserted_feed_follow.feed_id, inserted_feed_follow.folder_id, inserted_feed_follow.display_name, inserted_feed_follow.priority, inserted_feed_follow.notify, inserted_feed_follow.match_filter, 
     users.name AS user_name, 
     feeds.name AS feed_name
 FROM inserted_feed_follow
@@ -43,13 +43,18 @@ type CreateFeedFollowParams struct {
 }
 
 type CreateFeedFollowRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	UserName  string
-	FeedName  string
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	FeedID      uuid.UUID
+	FolderID    uuid.NullUUID
+	DisplayName sql.NullString
+	Priority    int32
+	Notify      bool
+	MatchFilter sql.NullString
+	UserName    string
+	FeedName    string
 }
 
 func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowParams) (CreateFeedFollowRow, error) {
@@ -67,6 +72,11 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.FeedID,
+		&i.FolderID,
+		&i.DisplayName,
+		&i.Priority,
+		&i.Notify,
+		&i.MatchFilter,
 		&i.UserName,
 		&i.FeedName,
 	)
@@ -89,23 +99,45 @@ func (q *Queries) DeleteFeedFollow(ctx context.Context, arg DeleteFeedFollowPara
 
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT 
-    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id,
+    feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feed_follows.folder_id, feed_follows.display_name, feed_follows.priority, feed_follows.notify, feed_follows.match_filter,
     users.name as user_name,
-    feeds.name as feed_name
+    COALESCE(feed_follows.display_name, feeds.name) as feed_name,
+    feeds.url as feed_url,
+    (
+        SELECT COUNT(*) FROM posts
+        WHERE posts.feed_id = feed_follows.feed_id
+            AND NOT EXISTS (
+                SELECT 1 FROM post_reads
+                WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+            )
+            AND (
+                feed_follows.match_filter IS NULL
+                OR posts.title ~* feed_follows.match_filter
+                OR posts.description ~* feed_follows.match_filter
+            )
+    ) AS unread_count
 FROM feed_follows
 INNER JOIN users ON feed_follows.user_id = users.id
 INNER JOIN feeds ON feed_follows.feed_id = feeds.id
 WHERE feed_follows.user_id = $1
+ORDER BY feed_follows.priority DESC, feed_name
 `
 
 type GetFeedFollowsForUserRow struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FeedID    uuid.UUID
-	UserName  string
-	FeedName  string
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	FeedID      uuid.UUID
+	FolderID    uuid.NullUUID
+	DisplayName sql.NullString
+	Priority    int32
+	Notify      bool
+	MatchFilter sql.NullString
+	UserName    string
+	FeedName    string
+	FeedUrl     string
+	UnreadCount int64
 }
 
 func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]GetFeedFollowsForUserRow, error) {
@@ -123,8 +155,15 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.UpdatedAt,
 			&i.UserID,
 			&i.FeedID,
+			&i.FolderID,
+			&i.DisplayName,
+			&i.Priority,
+			&i.Notify,
+			&i.MatchFilter,
 			&i.UserName,
 			&i.FeedName,
+			&i.FeedUrl,
+			&i.UnreadCount,
 		); err != nil {
 			return nil, err
 		}
@@ -138,3 +177,83 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const getFollowerNamesForFeed = `-- name: GetFollowerNamesForFeed :many
+SELECT users.name FROM feed_follows
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.feed_id = $1
+ORDER BY users.name
+`
+
+func (q *Queries) GetFollowerNamesForFeed(ctx context.Context, feedID uuid.UUID) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowerNamesForFeed, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setFeedFollowFolder = `-- name: SetFeedFollowFolder :execresult
+UPDATE feed_follows
+SET folder_id = $1, updated_at = $2
+WHERE user_id = $3 AND feed_id = $4
+`
+
+type SetFeedFollowFolderParams struct {
+	FolderID  uuid.NullUUID
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+}
+
+func (q *Queries) SetFeedFollowFolder(ctx context.Context, arg SetFeedFollowFolderParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, setFeedFollowFolder,
+		arg.FolderID,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+	)
+}
+
+const updateFeedFollowSettings = `-- name: UpdateFeedFollowSettings :execresult
+UPDATE feed_follows
+SET display_name = $1, priority = $2, notify = $3, match_filter = $4, updated_at = $5
+WHERE user_id = $6 AND feed_id = $7
+`
+
+type UpdateFeedFollowSettingsParams struct {
+	DisplayName sql.NullString
+	Priority    int32
+	Notify      bool
+	MatchFilter sql.NullString
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	FeedID      uuid.UUID
+}
+
+func (q *Queries) UpdateFeedFollowSettings(ctx context.Context, arg UpdateFeedFollowSettingsParams) (sql.Result, error) {
+	return q.db.ExecContext(ctx, updateFeedFollowSettings,
+		arg.DisplayName,
+		arg.Priority,
+		arg.Notify,
+		arg.MatchFilter,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+	)
+}
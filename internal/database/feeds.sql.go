@@ -23,7 +23,7 @@ VALUES(
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, auth_settings, robots_blocked, ignore_robots
 `
 
 type CreateFeedParams struct {
@@ -53,12 +53,25 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.AuthSettings,
+		&i.RobotsBlocked,
+		&i.IgnoreRobots,
 	)
 	return i, err
 }
 
+const deleteFeed = `-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteFeed, id)
+	return err
+}
+
 const getFeedByUrl = `-- name: GetFeedByUrl :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, auth_settings, robots_blocked, ignore_robots FROM feeds
 WHERE url = $1
 `
 
@@ -73,13 +86,16 @@ func (q *Queries) GetFeedByUrl(ctx context.Context, url string) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.AuthSettings,
+		&i.RobotsBlocked,
+		&i.IgnoreRobots,
 	)
 	return i, err
 }
 
 const getFeedsWithUsername = `-- name: GetFeedsWithUsername :many
-SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, users.name as username 
-FROM feeds INNER JOIN users
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.auth_settings, feeds.robots_blocked, feeds.ignore_robots, users.name as username 
+FROM feeds LEFT JOIN users
 ON feeds.user_id = users.id
 `
 
@@ -91,7 +107,10 @@ type GetFeedsWithUsernameRow struct {
 	Url           string
 	UserID        uuid.NullUUID
 	LastFetchedAt sql.NullTime
-	Username      string
+	AuthSettings  []byte
+	RobotsBlocked bool
+	IgnoreRobots  bool
+	Username      sql.NullString
 }
 
 func (q *Queries) GetFeedsWithUsername(ctx context.Context) ([]GetFeedsWithUsernameRow, error) {
@@ -111,6 +130,9 @@ func (q *Queries) GetFeedsWithUsername(ctx context.Context) ([]GetFeedsWithUsern
 			&i.Url,
 			&i.UserID,
 			&i.LastFetchedAt,
+			&i.AuthSettings,
+			&i.RobotsBlocked,
+			&i.IgnoreRobots,
 			&i.Username,
 		); err != nil {
 			return nil, err
@@ -127,8 +149,12 @@ func (q *Queries) GetFeedsWithUsername(ctx context.Context) ([]GetFeedsWithUsern
 }
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.auth_settings, feeds.robots_blocked, feeds.ignore_robots FROM feeds
+LEFT JOIN websub_subscriptions ON websub_subscriptions.feed_id = feeds.id
+WHERE websub_subscriptions.id IS NULL
+    OR websub_subscriptions.status != 'active'
+    OR websub_subscriptions.lease_expires_at < NOW()
+ORDER BY feeds.last_fetched_at NULLS FIRST
 LIMIT 1
 `
 
@@ -143,6 +169,9 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.AuthSettings,
+		&i.RobotsBlocked,
+		&i.IgnoreRobots,
 	)
 	return i, err
 }
@@ -163,3 +192,105 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams
 	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.LastFetchedAt, arg.UpdatedAt, arg.ID)
 	return err
 }
+
+const markFeedRobotsBlocked = `-- name: MarkFeedRobotsBlocked :exec
+UPDATE feeds
+SET robots_blocked = $1, updated_at = $2
+WHERE id = $3
+`
+
+type MarkFeedRobotsBlockedParams struct {
+	RobotsBlocked bool
+	UpdatedAt     time.Time
+	ID            uuid.UUID
+}
+
+func (q *Queries) MarkFeedRobotsBlocked(ctx context.Context, arg MarkFeedRobotsBlockedParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedRobotsBlocked, arg.RobotsBlocked, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const renameFeed = `-- name: RenameFeed :exec
+UPDATE feeds
+SET name = $1, updated_at = $2
+WHERE id = $3
+`
+
+type RenameFeedParams struct {
+	Name      string
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) RenameFeed(ctx context.Context, arg RenameFeedParams) error {
+	_, err := q.db.ExecContext(ctx, renameFeed, arg.Name, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setFeedOwner = `-- name: SetFeedOwner :exec
+UPDATE feeds
+SET user_id = $1, updated_at = $2
+WHERE id = $3
+`
+
+type SetFeedOwnerParams struct {
+	UserID    uuid.NullUUID
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) SetFeedOwner(ctx context.Context, arg SetFeedOwnerParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedOwner, arg.UserID, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const updateFeedAuthSettings = `-- name: UpdateFeedAuthSettings :exec
+UPDATE feeds
+SET auth_settings = $1, updated_at = $2
+WHERE id = $3
+`
+
+type UpdateFeedAuthSettingsParams struct {
+	AuthSettings []byte
+	UpdatedAt    time.Time
+	ID           uuid.UUID
+}
+
+func (q *Queries) UpdateFeedAuthSettings(ctx context.Context, arg UpdateFeedAuthSettingsParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedAuthSettings, arg.AuthSettings, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const updateFeedIgnoreRobots = `-- name: UpdateFeedIgnoreRobots :exec
+UPDATE feeds
+SET ignore_robots = $1, updated_at = $2
+WHERE id = $3
+`
+
+type UpdateFeedIgnoreRobotsParams struct {
+	IgnoreRobots bool
+	UpdatedAt    time.Time
+	ID           uuid.UUID
+}
+
+func (q *Queries) UpdateFeedIgnoreRobots(ctx context.Context, arg UpdateFeedIgnoreRobotsParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedIgnoreRobots, arg.IgnoreRobots, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const updateFeedUrl = `-- name: UpdateFeedUrl :exec
+UPDATE feeds
+SET url = $1, last_fetched_at = NULL, robots_blocked = false, updated_at = $2
+WHERE id = $3
+`
+
+type UpdateFeedUrlParams struct {
+	Url       string
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) UpdateFeedUrl(ctx context.Context, arg UpdateFeedUrlParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedUrl, arg.Url, arg.UpdatedAt, arg.ID)
+	return err
+}
@@ -11,6 +11,44 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApiToken struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UserID     uuid.UUID
+	Name       string
+	TokenHash  string
+	Scope      string
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+}
+
+type BootstrapCode struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	CodeHash  string
+}
+
+type Digest struct {
+	ID        uuid.UUID
+	SentAt    time.Time
+	UserID    uuid.UUID
+	Email     string
+	PostCount int32
+}
+
+type DigestPost struct {
+	DigestID uuid.UUID
+	PostID   uuid.UUID
+}
+
+type DigestSubscription struct {
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Email     string
+}
+
 type Feed struct {
 	ID            uuid.UUID
 	CreatedAt     time.Time
@@ -19,14 +57,40 @@ type Feed struct {
 	Url           string
 	UserID        uuid.NullUUID
 	LastFetchedAt sql.NullTime
+	AuthSettings  []byte
+	RobotsBlocked bool
+	IgnoreRobots  bool
 }
 
 type FeedFollow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	UserID      uuid.UUID
+	FeedID      uuid.UUID
+	FolderID    uuid.NullUUID
+	DisplayName sql.NullString
+	Priority    int32
+	Notify      bool
+	MatchFilter sql.NullString
+}
+
+type Folder struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	ParentID  uuid.NullUUID
+	Name      string
+}
+
+type MuteFilter struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	UserID    uuid.UUID
-	FeedID    uuid.UUID
+	Kind      string
+	Pattern   string
 }
 
 type Post struct {
@@ -38,11 +102,76 @@ type Post struct {
 	Description string
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	Author      string
+	Categories  []string
+	Content     string
+}
+
+type PostRead struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type SavedPost struct {
+	UserID  uuid.UUID
+	PostID  uuid.UUID
+	Kind    string
+	SavedAt time.Time
+}
+
+type SavedSearch struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.UUID
+	Name         string
+	Query        string
+	NotifyTarget string
+}
+
+type SavedSearchMatch struct {
+	SavedSearchID uuid.UUID
+	PostID        uuid.UUID
+	MatchedAt     time.Time
+}
+
+type Session struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+	UserID     uuid.UUID
+	TokenHash  string
+}
+
+type Share struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	PostID     uuid.UUID
+	FromUserID uuid.UUID
+	ToUserID   uuid.UUID
+	Note       sql.NullString
 }
 
 type User struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Name          string
+	PasswordHash  sql.NullString
+	Role          string
+	FollowsPublic bool
+}
+
+type WebsubSubscription struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	FeedID         uuid.UUID
+	HubUrl         string
+	TopicUrl       string
+	Secret         string
+	Status         string
+	LeaseExpiresAt sql.NullTime
 }
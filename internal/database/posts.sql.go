@@ -7,13 +7,27 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countPostsForFeed = `-- name: CountPostsForFeed :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1
+`
+
+func (q *Queries) CountPostsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForFeed, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, author, categories, content)
 VALUES(
     $1,
     $2,
@@ -22,9 +36,12 @@ VALUES(
     $5,
     $6,
     $7,
-    $8
+    $8,
+    $9,
+    $10,
+    $11
 )
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, author, categories, content
 `
 
 type CreatePostParams struct {
@@ -36,6 +53,9 @@ type CreatePostParams struct {
 	Description string
 	PublishedAt time.Time
 	FeedID      uuid.UUID
+	Author      string
+	Categories  []string
+	Content     string
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -48,6 +68,9 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Author,
+		pq.Array(arg.Categories),
+		arg.Content,
 	)
 	var i Post
 	err := row.Scan(
@@ -59,33 +82,277 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Author,
+		pq.Array(&i.Categories),
+		&i.Content,
 	)
 	return i, err
 }
 
+const getPostByUrl = `-- name: GetPostByUrl :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author, categories, content FROM posts
+WHERE url = $1
+`
+
+func (q *Queries) GetPostByUrl(ctx context.Context, url string) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByUrl, url)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Author,
+		pq.Array(&i.Categories),
+		&i.Content,
+	)
+	return i, err
+}
+
+const getPostsByIdPrefix = `-- name: GetPostsByIdPrefix :many
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author, categories, content FROM posts
+WHERE id::text LIKE $1::text || '%'
+LIMIT 2
+`
+
+func (q *Queries) GetPostsByIdPrefix(ctx context.Context, idPrefix string) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsByIdPrefix, idPrefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.Content,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForQuery = `-- name: GetPostsForQuery :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.author, posts.categories, posts.content
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1
+    AND post_search_vector(posts.title, posts.description, posts.content) @@ websearch_to_tsquery('english', $2)
+    AND (
+        feed_follows.match_filter IS NULL
+        OR posts.title ~* feed_follows.match_filter
+        OR posts.description ~* feed_follows.match_filter
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mute_filters
+        WHERE mute_filters.user_id = feed_follows.user_id
+            AND mute_filter_matches(
+                mute_filters.kind,
+                mute_filters.pattern,
+                posts.title,
+                posts.description,
+                posts.author,
+                posts.url,
+                posts.categories
+            )
+    )
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT $3
+`
+
+type GetPostsForQueryParams struct {
+	UserID    uuid.UUID
+	QueryText string
+	PostLimit int32
+}
+
+func (q *Queries) GetPostsForQuery(ctx context.Context, arg GetPostsForQueryParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForQuery, arg.UserID, arg.QueryText, arg.PostLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.Content,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id 
+SELECT
+    posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.author, posts.categories, posts.content,
+    COALESCE(feed_follows.display_name, feeds.name) AS feed_name,
+    feed_follows.priority AS feed_priority,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+    ) AS is_read
 FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
 INNER JOIN feeds ON feeds.id = posts.feed_id
-WHERE feeds.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2
+WHERE feed_follows.user_id = $1
+    AND (
+        NOT $2::boolean
+        OR NOT EXISTS (
+            SELECT 1 FROM post_reads
+            WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+        )
+    )
+    AND (NOT $3::boolean OR posts.published_at >= feed_follows.created_at)
+    AND ($4::uuid IS NULL OR posts.feed_id = $4)
+    AND (
+        $5::uuid IS NULL
+        OR feed_follows.folder_id = $5
+        OR feed_follows.folder_id IN (SELECT id FROM folders WHERE parent_id = $5)
+    )
+    AND ($6::timestamp IS NULL OR posts.published_at >= $6)
+    AND ($7::timestamp IS NULL OR posts.published_at < $7)
+    AND ($8::text IS NULL OR posts.author ILIKE '%' || $8 || '%')
+    AND (
+        $9::text IS NULL
+        OR EXISTS (
+            SELECT 1 FROM unnest(posts.categories) AS category
+            WHERE lower(category) = lower($9)
+        )
+    )
+    AND (
+        feed_follows.match_filter IS NULL
+        OR posts.title ~* feed_follows.match_filter
+        OR posts.description ~* feed_follows.match_filter
+    )
+    AND (
+        $10::timestamp IS NULL
+        OR (
+            CASE WHEN $11::boolean THEN feed_follows.priority ELSE 0 END,
+            CASE WHEN $12::boolean THEN posts.created_at ELSE posts.published_at END,
+            posts.id
+        ) < ($13::integer, $10, $14::uuid)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mute_filters
+        WHERE mute_filters.user_id = feed_follows.user_id
+            AND mute_filter_matches(
+                mute_filters.kind,
+                mute_filters.pattern,
+                posts.title,
+                posts.description,
+                posts.author,
+                posts.url,
+                posts.categories
+            )
+    )
+ORDER BY
+    CASE WHEN $11::boolean THEN feed_follows.priority ELSE 0 END DESC,
+    CASE WHEN $12::boolean THEN posts.created_at ELSE posts.published_at END DESC,
+    posts.id DESC
+LIMIT $15
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.NullUUID
-	Limit  int32
+	UserID         uuid.UUID
+	UnreadOnly     bool
+	SinceFollow    bool
+	FeedID         uuid.NullUUID
+	FolderID       uuid.NullUUID
+	Since          sql.NullTime
+	Until          sql.NullTime
+	Author         sql.NullString
+	Category       sql.NullString
+	AfterTime      sql.NullTime
+	SortByPriority bool
+	SortByFetched  bool
+	AfterPriority  int32
+	AfterID        uuid.NullUUID
+	PostLimit      int32
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+type GetPostsForUserRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  string
+	PublishedAt  time.Time
+	FeedID       uuid.UUID
+	Author       string
+	Categories   []string
+	Content      string
+	FeedName     string
+	FeedPriority int32
+	IsRead       bool
+}
+
+func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUser,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.SinceFollow,
+		arg.FeedID,
+		arg.FolderID,
+		arg.Since,
+		arg.Until,
+		arg.Author,
+		arg.Category,
+		arg.AfterTime,
+		arg.SortByPriority,
+		arg.SortByFetched,
+		arg.AfterPriority,
+		arg.AfterID,
+		arg.PostLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []GetPostsForUserRow
 	for rows.Next() {
-		var i Post
+		var i GetPostsForUserRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
@@ -95,6 +362,110 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Author,
+			pq.Array(&i.Categories),
+			&i.Content,
+			&i.FeedName,
+			&i.FeedPriority,
+			&i.IsRead,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchPosts = `-- name: SearchPosts :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(post_search_vector(posts.title, posts.description, posts.content), search_query) AS rank,
+    ts_headline(
+        'english',
+        posts.title || ' ' || posts.description || ' ' || posts.content,
+        search_query,
+        'StartSel=**, StopSel=**, MaxWords=35, MinWords=15, MaxFragments=2'
+    ) AS snippet
+FROM posts
+INNER JOIN feeds ON feeds.id = posts.feed_id
+CROSS JOIN websearch_to_tsquery('english', $1) AS search_query
+WHERE post_search_vector(posts.title, posts.description, posts.content) @@ search_query
+    AND (
+        NOT $2::boolean
+        OR EXISTS (
+            SELECT 1 FROM feed_follows
+            WHERE feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = $3
+        )
+    )
+    AND ($4::uuid IS NULL OR posts.feed_id = $4)
+    AND NOT EXISTS (
+        SELECT 1 FROM mute_filters
+        WHERE mute_filters.user_id = $3
+            AND mute_filter_matches(
+                mute_filters.kind,
+                mute_filters.pattern,
+                posts.title,
+                posts.description,
+                posts.author,
+                posts.url,
+                posts.categories
+            )
+    )
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT $5
+`
+
+type SearchPostsParams struct {
+	QueryText    string
+	FollowedOnly bool
+	UserID       uuid.UUID
+	FeedID       uuid.NullUUID
+	ResultLimit  int32
+}
+
+type SearchPostsRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	PublishedAt time.Time
+	FeedName    string
+	Rank        float32
+	Snippet     string
+}
+
+func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPosts,
+		arg.QueryText,
+		arg.FollowedOnly,
+		arg.UserID,
+		arg.FeedID,
+		arg.ResultLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsRow
+	for rows.Next() {
+		var i SearchPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.FeedName,
+			&i.Rank,
+			&i.Snippet,
 		); err != nil {
 			return nil, err
 		}
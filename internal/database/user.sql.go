@@ -7,27 +7,57 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const countAdmins = `-- name: CountAdmins :one
+SELECT COUNT(*) FROM users
+WHERE role = 'admin'
+`
+
+func (q *Queries) CountAdmins(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAdmins)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countAdminsWithPassword = `-- name: CountAdminsWithPassword :one
+SELECT COUNT(*) FROM users
+WHERE role = 'admin' AND password_hash IS NOT NULL
+`
+
+func (q *Queries) CountAdminsWithPassword(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAdminsWithPassword)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, password_hash, role)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5,
+    $6
 )
-RETURNING id, created_at, updated_at, name
+RETURNING id, created_at, updated_at, name, password_hash, role, follows_public
 `
 
 type CreateUserParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Name          string
+	PasswordHash  sql.NullString
+	Role          string
+	FollowsPublic bool
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -36,6 +66,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.Name,
+		arg.PasswordHash,
+		arg.Role,
+		arg.FollowsPublic,
 	)
 	var i User
 	err := row.Scan(
@@ -43,12 +76,25 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.PasswordHash,
+		&i.Role,
+		&i.FollowsPublic,
 	)
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, id)
+	return err
+}
+
 const getUser = `-- name: GetUser :one
-SELECT id, created_at, updated_at, name FROM users 
+SELECT id, created_at, updated_at, name, password_hash, role, follows_public FROM users 
 WHERE name = $1
 `
 
@@ -60,12 +106,55 @@ func (q *Queries) GetUser(ctx context.Context, name string) (User, error) {
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
+		&i.PasswordHash,
+		&i.Role,
+		&i.FollowsPublic,
 	)
 	return i, err
 }
 
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, created_at, updated_at, name, password_hash, role, follows_public FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.PasswordHash,
+		&i.Role,
+		&i.FollowsPublic,
+	)
+	return i, err
+}
+
+const getUserLastActivity = `-- name: GetUserLastActivity :one
+SELECT GREATEST(
+    users.created_at,
+    (SELECT MAX(read_at) FROM post_reads WHERE post_reads.user_id = users.id),
+    (SELECT MAX(saved_at) FROM saved_posts WHERE saved_posts.user_id = users.id),
+    (SELECT MAX(feed_follows.updated_at) FROM feed_follows WHERE feed_follows.user_id = users.id),
+    (SELECT MAX(sessions.last_used_at) FROM sessions WHERE sessions.user_id = users.id),
+    (SELECT MAX(api_tokens.last_used_at) FROM api_tokens WHERE api_tokens.user_id = users.id)
+)::timestamp AS last_activity
+FROM users
+WHERE users.id = $1
+`
+
+func (q *Queries) GetUserLastActivity(ctx context.Context, id uuid.UUID) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getUserLastActivity, id)
+	var last_activity time.Time
+	err := row.Scan(&last_activity)
+	return last_activity, err
+}
+
 const getUsers = `-- name: GetUsers :many
-SELECT id, created_at, updated_at, name FROM users
+SELECT id, created_at, updated_at, name, password_hash, role, follows_public FROM users
 `
 
 func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
@@ -82,6 +171,9 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Name,
+			&i.PasswordHash,
+			&i.Role,
+			&i.FollowsPublic,
 		); err != nil {
 			return nil, err
 		}
@@ -96,11 +188,70 @@ func (q *Queries) GetUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
-const resetUsers = `-- name: ResetUsers :exec
-DELETE FROM users
+const renameUser = `-- name: RenameUser :exec
+UPDATE users
+SET name = $1, updated_at = $2
+WHERE id = $3
+`
+
+type RenameUserParams struct {
+	Name      string
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) RenameUser(ctx context.Context, arg RenameUserParams) error {
+	_, err := q.db.ExecContext(ctx, renameUser, arg.Name, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setUserFollowsPublic = `-- name: SetUserFollowsPublic :exec
+UPDATE users
+SET follows_public = $1, updated_at = $2
+WHERE id = $3
 `
 
-func (q *Queries) ResetUsers(ctx context.Context) error {
-	_, err := q.db.ExecContext(ctx, resetUsers)
+type SetUserFollowsPublicParams struct {
+	FollowsPublic bool
+	UpdatedAt     time.Time
+	ID            uuid.UUID
+}
+
+func (q *Queries) SetUserFollowsPublic(ctx context.Context, arg SetUserFollowsPublicParams) error {
+	_, err := q.db.ExecContext(ctx, setUserFollowsPublic, arg.FollowsPublic, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setUserPassword = `-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $1, updated_at = $2
+WHERE id = $3
+`
+
+type SetUserPasswordParams struct {
+	PasswordHash sql.NullString
+	UpdatedAt    time.Time
+	ID           uuid.UUID
+}
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg SetUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPassword, arg.PasswordHash, arg.UpdatedAt, arg.ID)
+	return err
+}
+
+const setUserRole = `-- name: SetUserRole :exec
+UPDATE users
+SET role = $1, updated_at = $2
+WHERE id = $3
+`
+
+type SetUserRoleParams struct {
+	Role      string
+	UpdatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, setUserRole, arg.Role, arg.UpdatedAt, arg.ID)
 	return err
 }
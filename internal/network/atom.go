@@ -0,0 +1,77 @@
+package network
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+// Whether the root element of the document is an atom <feed>
+func isAtomDocument(body []byte) bool {
+	decoder := newLenientDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "feed" && start.Name.Space == ATOM_NAMESPACE
+		}
+	}
+}
+
+// Parse an atom document into the rss structure used for storing posts
+func parseAtom(body []byte) (*RSSFeed, error) {
+	var doc atomDocument
+	if err := newStrictDecoder(bytes.NewReader(body)).Decode(&doc); err != nil {
+		return &RSSFeed{}, err
+	}
+
+	var feed RSSFeed
+	feed.Channel.Title = doc.Title
+	feed.Channel.Description = doc.Subtitle
+	feed.Channel.Link = alternateLink(doc.Links)
+	for _, link := range doc.Links {
+		if link.Rel != "" && link.Rel != "alternate" {
+			feed.Channel.AtomLinks = append(feed.Channel.AtomLinks, AtomLink{Rel: link.Rel, Href: link.Href})
+		}
+	}
+
+	for _, entry := range doc.Entries {
+		item := RSSItem{
+			Title:   entry.Title,
+			Link:    alternateLink(entry.Links),
+			PubDate: entry.Published,
+		}
+		if item.PubDate == "" {
+			item.PubDate = entry.Updated
+		}
+		if entry.Content != nil {
+			item.Content = entry.Content.Value
+			item.Description = entry.Content.Value
+		}
+		if entry.Summary != nil {
+			item.Description = entry.Summary.Value
+		}
+		if entry.Author != nil {
+			item.Author = entry.Author.Name
+			if item.Author == "" {
+				item.Author = entry.Author.Email
+			}
+		}
+		for _, category := range entry.Categories {
+			item.Categories = append(item.Categories, category.Term)
+		}
+		feed.Channel.Item = append(feed.Channel.Item, item)
+	}
+	return &feed, nil
+}
+
+// Href of the alternate link, the page the feed or entry describes
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+	return ""
+}
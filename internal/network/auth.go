@@ -0,0 +1,161 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Placeholder shown instead of a secret
+const REDACTED = "[REDACTED]"
+
+// Credentials applied to the requests of a single feed
+type FeedAuth struct {
+	Username    string            `json:"username,omitempty"`
+	Password    string            `json:"password,omitempty"`
+	BearerToken string            `json:"bearer_token,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Query       map[string]string `json:"query,omitempty"`
+}
+
+// Whether no credentials are set
+func (a *FeedAuth) IsEmpty() bool {
+	return a == nil || (a.Username == "" && a.Password == "" && a.BearerToken == "" &&
+		len(a.Headers) == 0 && len(a.Query) == 0)
+}
+
+// Apply the credentials to the request
+func (a *FeedAuth) Apply(req *http.Request) {
+	if a.IsEmpty() {
+		return
+	}
+
+	if a.Username != "" || a.Password != "" {
+		req.SetBasicAuth(a.Username, a.Password)
+	}
+	if a.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+a.BearerToken)
+	}
+	for name, value := range a.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if len(a.Query) > 0 {
+		query := req.URL.Query()
+		for name, value := range a.Query {
+			query.Set(name, value)
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+}
+
+// Context key of the credentials applied to a request
+type feedAuthKey struct{}
+
+// Request carrying the credentials, so redirects can tell which headers are secret
+func (a *FeedAuth) WithContext(req *http.Request) *http.Request {
+	if a.IsEmpty() {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), feedAuthKey{}, a))
+}
+
+// http.Client.CheckRedirect for feed requests. Go only drops Authorization
+// and Cookie when a redirect leaves the domain, so the custom headers of
+// the feed are removed here whenever a redirect changes the host.
+func checkFeedRedirect(req *http.Request, via []*http.Request) error {
+	// Same limit as the default policy
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+
+	auth, ok := req.Context().Value(feedAuthKey{}).(*FeedAuth)
+	if !ok || req.URL.Host == via[0].URL.Host {
+		return nil
+	}
+	for name := range auth.Headers {
+		req.Header.Del(name)
+	}
+	return nil
+}
+
+// Describe the credentials without revealing any secret
+func (a *FeedAuth) String() string {
+	if a.IsEmpty() {
+		return "none"
+	}
+
+	var parts []string
+	if a.Username != "" || a.Password != "" {
+		parts = append(parts, fmt.Sprintf("basic %v:%v", a.Username, REDACTED))
+	}
+	if a.BearerToken != "" {
+		parts = append(parts, "bearer "+REDACTED)
+	}
+	for _, name := range sortedKeys(a.Headers) {
+		parts = append(parts, fmt.Sprintf("header %v: %v", name, REDACTED))
+	}
+	for _, name := range sortedKeys(a.Query) {
+		parts = append(parts, fmt.Sprintf("query %v=%v", name, REDACTED))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Replace every secret value in the text
+func (a *FeedAuth) Redact(text string) string {
+	if a.IsEmpty() {
+		return text
+	}
+
+	secrets := []string{a.Password, a.BearerToken}
+	for _, value := range a.Headers {
+		secrets = append(secrets, value)
+	}
+	for _, value := range a.Query {
+		// Query values show up url encoded in urls and errors
+		secrets = append(secrets, value, url.QueryEscape(value))
+	}
+
+	for _, secret := range secrets {
+		if secret != "" {
+			text = strings.ReplaceAll(text, secret, REDACTED)
+		}
+	}
+	return text
+}
+
+// Keys of the map in a stable order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Error with secrets removed from its message which still unwraps to the original
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// Remove the secrets from the error message
+func (a *FeedAuth) RedactError(err error) error {
+	if err == nil || a.IsEmpty() {
+		return err
+	}
+	return &redactedError{message: a.Redact(err.Error()), err: err}
+}
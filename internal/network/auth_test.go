@@ -0,0 +1,53 @@
+package network
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testFeed = `<rss><channel><title>Blog</title></channel></rss>`
+
+func TestFeedHeadersAreDroppedOnCrossHostRedirects(t *testing.T) {
+	// Header the final server received, per request path
+	received := map[string]string{}
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received[r.URL.Path] = r.Header.Get("X-Api-Key")
+		w.Write([]byte(testFeed))
+	}))
+	defer target.Close()
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, target.URL+"/elsewhere", http.StatusFound)
+		case "/local":
+			http.Redirect(w, r, "/feed", http.StatusFound)
+		default:
+			received[r.URL.Path] = r.Header.Get("X-Api-Key")
+			w.Write([]byte(testFeed))
+		}
+	}))
+	defer origin.Close()
+
+	client, err := NewClient(ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := &FeedAuth{Headers: map[string]string{"X-Api-Key": "secret"}}
+
+	if _, err := client.FetchFeed(context.Background(), origin.URL+"/local", auth); err != nil {
+		t.Fatalf("FetchFeed returned %v", err)
+	}
+	if got := received["/feed"]; got != "secret" {
+		t.Errorf("same host redirect sent X-Api-Key %q, want it kept", got)
+	}
+
+	if _, err := client.FetchFeed(context.Background(), origin.URL+"/moved", auth); err != nil {
+		t.Fatalf("FetchFeed returned %v", err)
+	}
+	if got := received["/elsewhere"]; got != "" {
+		t.Errorf("cross host redirect sent X-Api-Key %q, want it dropped", got)
+	}
+}
@@ -0,0 +1,91 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoding declared in the xml prolog, e.g. <?xml version="1.0" encoding="ISO-8859-1"?>
+var xmlEncodingPattern = regexp.MustCompile(`^\s*<\?xml[^>]*\sencoding\s*=\s*["']([A-Za-z0-9._:-]+)["']`)
+
+// Characters 0x80 to 0x9F of windows-1252, the rest matches iso-8859-1.
+// Undefined positions map to the c1 control character of the same value.
+var windows1252 = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// Encoding declared by the document, empty when there is no declaration
+func declaredEncoding(body []byte) string {
+	match := xmlEncodingPattern.FindSubmatch(body)
+	if match == nil {
+		return ""
+	}
+	return strings.ToLower(string(match[1]))
+}
+
+// Whether the encoding is utf-8, which is also the default without a declaration
+func isUTF8Encoding(encoding string) bool {
+	return encoding == "" || encoding == "utf-8" || encoding == "utf8"
+}
+
+// Decoder.CharsetReader for the single byte encodings feeds declare besides utf-8
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(label) {
+	case "iso-8859-1", "iso8859-1", "iso_8859-1", "latin1", "latin-1", "l1":
+		return newSingleByteReader(input, nil), nil
+	case "windows-1252", "cp1252", "x-cp1252":
+		return newSingleByteReader(input, &windows1252), nil
+	case "us-ascii", "ascii":
+		// Ascii is a subset of utf-8
+		return input, nil
+	default:
+		return nil, fmt.Errorf("unsupported charset %v", label)
+	}
+}
+
+// Reader converting a single byte encoding to utf-8. high maps bytes 0x80
+// to 0x9F and is nil for iso-8859-1, where every byte is its own code point.
+type singleByteReader struct {
+	input   *bufio.Reader
+	high    *[32]rune
+	pending []byte
+}
+
+func newSingleByteReader(input io.Reader, high *[32]rune) *singleByteReader {
+	return &singleByteReader{input: bufio.NewReader(input), high: high}
+}
+
+func (r *singleByteReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		// Flush what is left of the previous character first
+		if len(r.pending) > 0 {
+			copied := copy(p[n:], r.pending)
+			r.pending = r.pending[copied:]
+			n += copied
+			continue
+		}
+
+		b, err := r.input.ReadByte()
+		if err != nil {
+			if n > 0 && err == io.EOF {
+				return n, nil
+			}
+			return n, err
+		}
+
+		char := rune(b)
+		if r.high != nil && b >= 0x80 && b <= 0x9F {
+			char = r.high[b-0x80]
+		}
+		r.pending = utf8.AppendRune(r.pending[:0], char)
+	}
+	return n, nil
+}
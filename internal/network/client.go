@@ -0,0 +1,213 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Default user agent sent with every request
+const DEFAULT_USER_AGENT = "gator"
+
+// Default timeout for a whole request when none is configured
+const DEFAULT_TOTAL_TIMEOUT = 5 * time.Second
+
+// Default timeout for connecting, including the TLS handshake, when none is configured
+const DEFAULT_CONNECT_TIMEOUT = 5 * time.Second
+
+// Options used to build the shared http client
+type ClientOptions struct {
+	// Proxy used for every request. Falls back to the environment when empty.
+	ProxyURL string
+	// Comma separated hosts or domains which bypass the proxy
+	NoProxy string
+	// PEM files with additional root certificates
+	RootCAFiles []string
+	// Client certificate and key used for mutual TLS
+	ClientCertFile string
+	ClientKeyFile  string
+	// Time allowed to establish a connection, including the TLS handshake
+	ConnectTimeout time.Duration
+	// Time allowed to wait for the response headers
+	ReadTimeout time.Duration
+	// Time allowed for the whole request including reading the body
+	TotalTimeout time.Duration
+	// User agent and an optional contact url appended to it
+	UserAgent  string
+	ContactURL string
+	// Maximum idle connections kept per host
+	MaxIdleConnsPerHost int
+	// Time robots.txt rules are cached for
+	RobotsTTL time.Duration
+}
+
+// Long lived http client shared by every fetch
+type Client struct {
+	httpClient *http.Client
+	// Client for webhooks, which only connects to public addresses
+	webhookClient *http.Client
+	userAgent     string
+	// robots.txt rules of the hosts fetched from
+	Robots *RobotsCache
+}
+
+// Create the shared client from the given options
+func NewClient(opts ClientOptions) (*Client, error) {
+	// Proxy
+	proxy, err := proxyFunc(opts.ProxyURL, opts.NoProxy)
+	if err != nil {
+		return nil, err
+	}
+
+	// TLS
+	tlsConfig, err := tlsConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a timeout dialing and the handshake could hang forever
+	connectTimeout := opts.ConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = DEFAULT_CONNECT_TIMEOUT
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   connectTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   connectTimeout,
+		ResponseHeaderTimeout: opts.ReadTimeout,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		ForceAttemptHTTP2:     true,
+	}
+
+	totalTimeout := opts.TotalTimeout
+	if totalTimeout == 0 {
+		totalTimeout = DEFAULT_TOTAL_TIMEOUT
+	}
+
+	client := &Client{
+		httpClient: &http.Client{
+			Transport:     transport,
+			Timeout:       totalTimeout,
+			CheckRedirect: checkFeedRedirect,
+		},
+		webhookClient: newWebhookClient(connectTimeout, tlsConfig, totalTimeout),
+		userAgent:     userAgent(opts.UserAgent, opts.ContactURL),
+	}
+	client.Robots = newRobotsCache(client, opts.RobotsTTL)
+
+	return client, nil
+}
+
+// Build the user agent string, e.g. "gator (+https://example.com/contact)"
+func userAgent(agent string, contactURL string) string {
+	if agent == "" {
+		agent = DEFAULT_USER_AGENT
+	}
+	if contactURL != "" {
+		agent = fmt.Sprintf("%v (+%v)", agent, contactURL)
+	}
+	return agent
+}
+
+// Build the tls config with extra root CAs and the client certificate
+func tlsConfig(opts ClientOptions) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if len(opts.RootCAFiles) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, file := range opts.RootCAFiles {
+			pem, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("error reading root ca %v: %w", file, err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in root ca %v", file)
+			}
+		}
+		config.RootCAs = pool
+	}
+
+	if opts.ClientCertFile != "" || opts.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertFile, opts.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// Proxy function for the transport. Without a configured proxy the
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+func proxyFunc(proxyURL string, noProxy string) (func(*http.Request) (*url.URL, error), error) {
+	if proxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	parsedProxy, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing proxy url: %w", err)
+	}
+
+	if noProxy == "" {
+		noProxy = os.Getenv("NO_PROXY")
+	}
+	bypass := parseNoProxy(noProxy)
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), bypass) {
+			return nil, nil
+		}
+		return parsedProxy, nil
+	}, nil
+}
+
+// Split a NO_PROXY value into lower cased entries
+func parseNoProxy(noProxy string) []string {
+	var entries []string
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Check whether the host matches one of the NO_PROXY entries.
+// "*" matches every host and "example.com" or ".example.com" also match subdomains.
+func bypassProxy(host string, entries []string) bool {
+	host = strings.ToLower(host)
+	for _, entry := range entries {
+		if entry == "*" {
+			return true
+		}
+		// Entries may contain a port which is not part of the hostname
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		domain := strings.TrimPrefix(entry, ".")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package network
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBypassProxy(t *testing.T) {
+	tests := []struct {
+		host    string
+		noProxy string
+		want    bool
+	}{
+		{"example.com", "example.com", true},
+		{"blog.example.com", "example.com", true},
+		{"blog.example.com", ".example.com", true},
+		{"example.com", ".example.com", true},
+		{"notexample.com", "example.com", false},
+		{"example.com.evil.net", "example.com", false},
+		{"Blog.Example.COM", "EXAMPLE.com", true},
+		{"internal.local", "example.com, internal.local", true},
+		{"example.com", "example.com:8080", true},
+		{"anything.net", "*", true},
+		{"example.com", "", false},
+		{"example.com", " , ", false},
+	}
+	for _, test := range tests {
+		if got := bypassProxy(test.host, parseNoProxy(test.noProxy)); got != test.want {
+			t.Errorf("bypassProxy(%q, %q) = %v, want %v", test.host, test.noProxy, got, test.want)
+		}
+	}
+}
+
+func TestProxyFunc(t *testing.T) {
+	// Without a configured proxy the environment decides
+	proxy, err := proxyFunc("", "")
+	if err != nil {
+		t.Fatalf("proxyFunc without a proxy: %v", err)
+	}
+	if reflect.ValueOf(proxy).Pointer() != reflect.ValueOf(http.ProxyFromEnvironment).Pointer() {
+		t.Error("proxyFunc without a proxy doesn't use the environment")
+	}
+
+	if _, err := proxyFunc("http://[::1", ""); err == nil {
+		t.Error("proxyFunc accepted an invalid proxy url")
+	}
+
+	// An explicit proxy still honours NO_PROXY when no_proxy isn't configured
+	t.Setenv("NO_PROXY", "intranet.example.com")
+	tests := []struct {
+		noProxy string
+		target  string
+		want    string
+	}{
+		{"", "https://blog.example.com/feed", "http://proxy.example.com:3128"},
+		{"", "https://intranet.example.com/feed", ""},
+		{"blog.example.com", "https://blog.example.com/feed", ""},
+		{"blog.example.com", "https://intranet.example.com/feed", "http://proxy.example.com:3128"},
+	}
+	for _, test := range tests {
+		proxy, err := proxyFunc("http://proxy.example.com:3128", test.noProxy)
+		if err != nil {
+			t.Fatalf("proxyFunc: %v", err)
+		}
+		got, err := proxy(httptest.NewRequest("GET", test.target, nil))
+		if err != nil {
+			t.Fatalf("proxy for %v: %v", test.target, err)
+		}
+		gotURL := ""
+		if got != nil {
+			gotURL = got.String()
+		}
+		if gotURL != test.want {
+			t.Errorf("proxy for %v with no_proxy %q = %q, want %q", test.target, test.noProxy, gotURL, test.want)
+		}
+	}
+}
+
+// Write a self-signed certificate and its key as PEM files
+func writeTestCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gator test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir)
+	badPEM := filepath.Join(dir, "bad.pem")
+	os.WriteFile(badPEM, []byte("not a certificate"), 0600)
+	missing := filepath.Join(dir, "missing.pem")
+
+	tests := []struct {
+		name    string
+		opts    ClientOptions
+		wantErr bool
+	}{
+		{"defaults", ClientOptions{}, false},
+		{"root ca", ClientOptions{RootCAFiles: []string{certFile}}, false},
+		{"bad root ca", ClientOptions{RootCAFiles: []string{badPEM}}, true},
+		{"missing root ca", ClientOptions{RootCAFiles: []string{missing}}, true},
+		{"client certificate", ClientOptions{ClientCertFile: certFile, ClientKeyFile: keyFile}, false},
+		{"client certificate without key", ClientOptions{ClientCertFile: certFile}, true},
+		{"bad client certificate", ClientOptions{ClientCertFile: badPEM, ClientKeyFile: keyFile}, true},
+		{"mismatched key", ClientOptions{ClientCertFile: certFile, ClientKeyFile: certFile}, true},
+	}
+	for _, test := range tests {
+		config, err := tlsConfig(test.opts)
+		if (err != nil) != test.wantErr {
+			t.Errorf("%v: tlsConfig error = %v, want error %v", test.name, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if config.MinVersion != tls.VersionTLS12 {
+			t.Errorf("%v: minimum version %x, want TLS 1.2", test.name, config.MinVersion)
+		}
+		if len(test.opts.RootCAFiles) > 0 && config.RootCAs == nil {
+			t.Errorf("%v: root CAs not set", test.name)
+		}
+		if test.opts.ClientCertFile != "" && len(config.Certificates) != 1 {
+			t.Errorf("%v: client certificate not loaded", test.name)
+		}
+	}
+}
+
+func TestNewClientDefaultsTheConnectTimeout(t *testing.T) {
+	client, err := NewClient(ClientOptions{})
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	transport := client.httpClient.Transport.(*http.Transport)
+	if transport.TLSHandshakeTimeout != DEFAULT_CONNECT_TIMEOUT {
+		t.Errorf("TLS handshake timeout %v, want %v", transport.TLSHandshakeTimeout, DEFAULT_CONNECT_TIMEOUT)
+	}
+	if client.httpClient.Timeout != DEFAULT_TOTAL_TIMEOUT {
+		t.Errorf("total timeout %v, want %v", client.httpClient.Timeout, DEFAULT_TOTAL_TIMEOUT)
+	}
+}
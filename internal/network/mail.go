@@ -0,0 +1,172 @@
+package network
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How connections to the smtp server are encrypted
+const (
+	// Upgrade the connection with STARTTLS, fail if the server can't
+	SMTP_TLS_STARTTLS = "starttls"
+	// Connect with TLS from the start, usually on port 465
+	SMTP_TLS_IMPLICIT = "tls"
+	// Send in plain text, only meant for local mail sinks
+	SMTP_TLS_NONE = "none"
+)
+
+const SMTP_TIMEOUT = 30 * time.Second
+
+// Smtp server settings
+type SMTPOptions struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	TLS      string
+}
+
+// Email with a plain text and an html version of the same content
+type Mail struct {
+	From    string
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Encode the mail as a multipart/alternative message. Clients show the
+// last part they can display, so the html part comes last.
+func (m Mail) Bytes() ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("error creating mail part: %w", err)
+		}
+		encoder := quotedprintable.NewWriter(partWriter)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("error encoding mail part: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("error encoding mail part: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error finishing mail: %w", err)
+	}
+
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %v: %w", m.From, err)
+	}
+	domain := from.Address[strings.LastIndex(from.Address, "@")+1:]
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("error generating message id: %w", err)
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %v\r\n", from.String())
+	fmt.Fprintf(&message, "To: %v\r\n", m.To)
+	fmt.Fprintf(&message, "Subject: %v\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&message, "Date: %v\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "Message-ID: <%v@%v>\r\n", hex.EncodeToString(id), domain)
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%v\r\n", writer.Boundary())
+	fmt.Fprintf(&message, "\r\n")
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+// Send the mail through the smtp server
+func SendMail(opts SMTPOptions, m Mail) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address %v: %w", m.From, err)
+	}
+	to, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address %v: %w", m.To, err)
+	}
+	message, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(opts.Host, strconv.Itoa(opts.Port))
+	dialer := &net.Dialer{Timeout: SMTP_TIMEOUT}
+	var conn net.Conn
+	if opts.TLS == SMTP_TLS_IMPLICIT {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: opts.Host})
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("error connecting to smtp server %v: %w", addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(SMTP_TIMEOUT))
+
+	client, err := smtp.NewClient(conn, opts.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("error talking to smtp server %v: %w", addr, err)
+	}
+	defer client.Close()
+
+	if opts.TLS == SMTP_TLS_STARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server %v doesn't support STARTTLS, set tls to none for a local server", addr)
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: opts.Host}); err != nil {
+			return fmt.Errorf("error starting tls: %w", err)
+		}
+	}
+
+	if opts.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", opts.Username, opts.Password, opts.Host)); err != nil {
+			return fmt.Errorf("error authenticating with smtp server: %w", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("error setting sender: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("error setting recipient %v: %w", to.Address, err)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("error starting mail data: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		return fmt.Errorf("error writing mail: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error sending mail: %w", err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,94 @@
+package network
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestMailBytes(t *testing.T) {
+	long := strings.Repeat("gopher ", 40)
+	m := Mail{
+		From:    "Gator Digest <digest@gator.example.com>",
+		To:      "alice@example.com",
+		Subject: "3 new posts — Café & Go",
+		Text:    "Unread posts:\n* Café crème = tasty  \n" + long,
+		HTML:    `<p style="color:red">Café crème</p><p>` + long + "</p>",
+	}
+	raw, err := m.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+
+	message, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("the mail doesn't parse: %v\n%s", err, raw)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || subject != m.Subject {
+		t.Errorf("subject decodes to %q, %v, want %q", subject, err, m.Subject)
+	}
+	if from, err := message.Header.AddressList("From"); err != nil || len(from) != 1 || from[0].Address != "digest@gator.example.com" {
+		t.Errorf("from is %v, %v, want digest@gator.example.com", from, err)
+	}
+	if id := message.Header.Get("Message-ID"); !strings.HasSuffix(id, "@gator.example.com>") {
+		t.Errorf("message id %q isn't on the sender's domain", id)
+	}
+	if _, err := message.Header.Date(); err != nil {
+		t.Errorf("invalid date header: %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type is %q, %v, want multipart/alternative", mediaType, err)
+	}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for _, want := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		part, err := reader.NextRawPart()
+		if err != nil {
+			t.Fatalf("missing the %v part: %v", want.contentType, err)
+		}
+		if part.Header.Get("Content-Type") != want.contentType || part.Header.Get("Content-Transfer-Encoding") != "quoted-printable" {
+			t.Errorf("part headers are %v, want %v in quoted-printable", part.Header, want.contentType)
+		}
+		encoded, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("error reading the %v part: %v", want.contentType, err)
+		}
+		// Smtp servers may reject lines over 1000 characters, quoted-printable keeps them at 76
+		for _, line := range strings.Split(string(encoded), "\r\n") {
+			if len(line) > 76 {
+				t.Errorf("the %v part has a %v character line: %q", want.contentType, len(line), line)
+			}
+		}
+		decoded, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(encoded)))
+		if err != nil {
+			t.Fatalf("error decoding the %v part: %v", want.contentType, err)
+		}
+		if got := strings.ReplaceAll(string(decoded), "\r\n", "\n"); got != want.content {
+			t.Errorf("the %v part decodes to %q, want %q", want.contentType, got, want.content)
+		}
+	}
+	if _, err := reader.NextRawPart(); err != io.EOF {
+		t.Errorf("the mail has more than two parts: %v", err)
+	}
+}
+
+func TestMailBytesRejectsBadSenders(t *testing.T) {
+	for _, from := range []string{"", "gator", "gator@", "Gator <digest@example.com"} {
+		if _, err := (Mail{From: from, To: "alice@example.com"}).Bytes(); err == nil {
+			t.Errorf("sender %q was accepted", from)
+		}
+	}
+}
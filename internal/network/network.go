@@ -2,25 +2,38 @@ package network
 
 import (
 	"context"
-	"encoding/xml"
 	"fmt"
 	"html"
 	"io"
 	"net/http"
-	"time"
 )
 
 // RSS Feed Url
 const RSS_FEED_URL = "https://www.wagslane.dev/index.xml"
 
+// Atom namespace, used for atom:link elements inside rss channels
+const ATOM_NAMESPACE = "http://www.w3.org/2005/Atom"
+
 // Rss Feed object
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Title string `xml:"title"`
+		// Must come before Link, which would otherwise also match atom:link
+		AtomLinks   []AtomLink `xml:"http://www.w3.org/2005/Atom link"`
+		Link        string     `xml:"link"`
+		Description string     `xml:"description"`
+		Item        []RSSItem  `xml:"item"`
 	} `xml:"channel"`
+
+	// Parse status and the problems found while parsing
+	Status   ParseStatus `xml:"-"`
+	Warnings []string    `xml:"-"`
+}
+
+// Atom link inside an rss channel, e.g. <atom:link rel="hub" href="..."/>
+type AtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
 }
 
 // Rss Channel
@@ -29,13 +42,16 @@ type RSSItem struct {
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
 	PubDate     string `xml:"pubDate"`
+	Author      string `xml:"author"`
+	// Dublin Core creator, used by feeds which name authors without an email
+	Creator    string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Categories []string `xml:"category"`
+	// Full content of the post, <content:encoded>
+	Content string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
 }
 
-// Fetch RSS Feeds
-func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-
-	// Create client
-	client := &http.Client{Timeout: 5 * time.Second}
+// Fetch RSS Feeds. auth may be nil for public feeds.
+func (c *Client) FetchFeed(ctx context.Context, feedURL string, auth *FeedAuth) (*RSSFeed, error) {
 
 	// Create new request
 	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
@@ -44,31 +60,65 @@ func FetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 	}
 
 	// Set header
-	req.Header.Set("User-Agent", "gator")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	// Apply the feed credentials
+	auth.Apply(req)
+	req = auth.WithContext(req)
 
 	// Make request
-	res, err := client.Do(req)
+	res, err := c.httpClient.Do(req)
 	if err != nil {
-		return &RSSFeed{}, fmt.Errorf("error making the request %w", err)
+		return &RSSFeed{}, auth.RedactError(fmt.Errorf("error making the request %w", err))
 	}
 	defer res.Body.Close()
 
+	// Report failed requests, e.g. rejected credentials
+	if res.StatusCode >= http.StatusBadRequest {
+		return &RSSFeed{}, fmt.Errorf("unexpected status code %v", res.StatusCode)
+	}
+
 	// Read the response
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return &RSSFeed{}, fmt.Errorf("error reading the response %w", err)
+		return &RSSFeed{}, auth.RedactError(fmt.Errorf("error reading the response %w", err))
 	}
 
-	// Parse xml
-	var result RSSFeed
-	if err := xml.Unmarshal(body, &result); err != nil {
-		return &RSSFeed{}, fmt.Errorf("error parsing the response %w", err)
+	return ParseFeed(body)
+}
+
+// Parse a feed document, e.g. a fetched feed or content pushed by a hub
+func ParseFeed(body []byte) (*RSSFeed, error) {
+	// Parse xml, recovering from malformed documents where possible
+	result, err := parseFeed(body)
+	if err != nil {
+		return &RSSFeed{}, err
 	}
 
 	// Unescape html and mutate the resulting feed
-	unEscapeHtml(&result)
+	unEscapeHtml(result)
 
-	return &result, nil
+	return result, nil
+}
+
+// Url of the WebSub hub advertised by the feed, empty when there is none
+func (f *RSSFeed) HubURL() string {
+	return f.atomLink("hub")
+}
+
+// Canonical url of the feed as advertised by the feed itself
+func (f *RSSFeed) SelfURL() string {
+	return f.atomLink("self")
+}
+
+// Href of the first atom link with the given rel
+func (f *RSSFeed) atomLink(rel string) string {
+	for _, link := range f.Channel.AtomLinks {
+		if link.Rel == rel {
+			return link.Href
+		}
+	}
+	return ""
 }
 
 // Unescape html for title and description
@@ -0,0 +1,192 @@
+package network
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// Parse status of a fetched feed
+type ParseStatus int
+
+const (
+	// The document was well-formed and parsed as is
+	ParseStatusOK ParseStatus = iota
+	// The document was malformed and had to be repaired or partially salvaged
+	ParseStatusWarnings
+)
+
+// Human readable parse status
+func (p ParseStatus) String() string {
+	switch p {
+	case ParseStatusOK:
+		return "ok"
+	case ParseStatusWarnings:
+		return "parsed with warnings"
+	default:
+		return "unknown"
+	}
+}
+
+// Parse the feed body. Strict parsing is attempted first and the lenient
+// recovery layer is only used when the document is not well-formed.
+func parseFeed(body []byte) (*RSSFeed, error) {
+	var warnings []string
+
+	// Strip characters which are never allowed in an XML document
+	cleaned, stripped := stripInvalidXMLChars(body)
+	if stripped > 0 {
+		warnings = append(warnings, fmt.Sprintf("stripped %v invalid xml characters", stripped))
+	}
+
+	// Atom documents, e.g. content pushed by a hub, are parsed strictly
+	if isAtomDocument(cleaned) {
+		result, err := parseAtom(cleaned)
+		if err != nil {
+			return &RSSFeed{}, fmt.Errorf("error parsing the atom response %w", err)
+		}
+		result.Warnings = warnings
+		if len(warnings) > 0 {
+			result.Status = ParseStatusWarnings
+		}
+		return result, nil
+	}
+
+	// Strict parsing
+	var result RSSFeed
+	strictErr := newStrictDecoder(bytes.NewReader(cleaned)).Decode(&result)
+	if strictErr == nil {
+		result.Warnings = warnings
+		if len(warnings) > 0 {
+			result.Status = ParseStatusWarnings
+		}
+		return &result, nil
+	}
+	warnings = append(warnings, fmt.Sprintf("document is not well-formed: %v", strictErr))
+
+	// Lenient parsing, keeping whatever was parsed before an error
+	salvaged, err := salvageFeed(cleaned)
+	if err != nil {
+		warnings = append(warnings, err.Error())
+	}
+
+	// Nothing could be recovered, report the original error
+	if salvaged.Channel.Title == "" && len(salvaged.Channel.Item) == 0 {
+		return &RSSFeed{}, fmt.Errorf("error parsing the response %w", strictErr)
+	}
+
+	salvaged.Status = ParseStatusWarnings
+	salvaged.Warnings = warnings
+	return salvaged, nil
+}
+
+// Create a decoder which decodes the declared charset and nothing else
+func newStrictDecoder(r io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charsetReader
+	return decoder
+}
+
+// Create a decoder which accepts typical real world html-ish xml.
+// AutoClose is left unset since html treats <link> as a void element.
+func newLenientDecoder(r io.Reader) *xml.Decoder {
+	decoder := newStrictDecoder(r)
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	return decoder
+}
+
+// Walk the document token by token and decode the channel fields and items.
+// Items decoded before an error are kept and the error is returned alongside.
+func salvageFeed(body []byte) (*RSSFeed, error) {
+	decoder := newLenientDecoder(bytes.NewReader(body))
+
+	var feed RSSFeed
+	// Names of the currently open elements
+	var parents []string
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return &feed, nil
+		}
+		if err != nil {
+			return &feed, fmt.Errorf("stopped parsing after %v items: %w", len(feed.Channel.Item), err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			inChannel := len(parents) > 0 && parents[len(parents)-1] == "channel"
+
+			switch {
+			case t.Name.Local == "item":
+				var item RSSItem
+				if err := decoder.DecodeElement(&item, &t); err != nil {
+					return &feed, fmt.Errorf("stopped parsing after %v items: %w", len(feed.Channel.Item), err)
+				}
+				feed.Channel.Item = append(feed.Channel.Item, item)
+			case inChannel && t.Name.Local == "title":
+				if err := decoder.DecodeElement(&feed.Channel.Title, &t); err != nil {
+					return &feed, fmt.Errorf("error parsing channel title: %w", err)
+				}
+			case inChannel && t.Name.Local == "link" && t.Name.Space == ATOM_NAMESPACE:
+				var link AtomLink
+				if err := decoder.DecodeElement(&link, &t); err != nil {
+					return &feed, fmt.Errorf("error parsing channel atom link: %w", err)
+				}
+				feed.Channel.AtomLinks = append(feed.Channel.AtomLinks, link)
+			case inChannel && t.Name.Local == "link" && t.Name.Space == "":
+				if err := decoder.DecodeElement(&feed.Channel.Link, &t); err != nil {
+					return &feed, fmt.Errorf("error parsing channel link: %w", err)
+				}
+			case inChannel && t.Name.Local == "description":
+				if err := decoder.DecodeElement(&feed.Channel.Description, &t); err != nil {
+					return &feed, fmt.Errorf("error parsing channel description: %w", err)
+				}
+			default:
+				parents = append(parents, t.Name.Local)
+			}
+		case xml.EndElement:
+			if len(parents) > 0 {
+				parents = parents[:len(parents)-1]
+			}
+		}
+	}
+}
+
+// Remove characters outside the XML 1.0 character range.
+// Returns the cleaned document and the number of characters removed.
+// Documents in other charsets are left for the decoder to convert, only
+// their control bytes are removed.
+func stripInvalidXMLChars(body []byte) ([]byte, int) {
+	stripped := 0
+	if !isUTF8Encoding(declaredEncoding(body)) {
+		cleaned := make([]byte, 0, len(body))
+		for _, b := range body {
+			if b < 0x20 && !isValidXMLChar(rune(b)) {
+				stripped++
+				continue
+			}
+			cleaned = append(cleaned, b)
+		}
+		return cleaned, stripped
+	}
+
+	cleaned := bytes.Map(func(r rune) rune {
+		if isValidXMLChar(r) {
+			return r
+		}
+		stripped++
+		return -1
+	}, body)
+	return cleaned, stripped
+}
+
+// Char ::= #x9 | #xA | #xD | [#x20-#xD7FF] | [#xE000-#xFFFD] | [#x10000-#x10FFFF]
+func isValidXMLChar(r rune) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D ||
+		(r >= 0x20 && r <= 0xD7FF) ||
+		(r >= 0xE000 && r <= 0xFFFD) ||
+		(r >= 0x10000 && r <= 0x10FFFF)
+}
@@ -0,0 +1,111 @@
+package network
+
+import (
+	"testing"
+)
+
+func TestParseFeedRecoversMalformedDocuments(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus ParseStatus
+		wantTitle  string
+		wantItems  []string
+	}{
+		{
+			name:       "well-formed",
+			body:       `<rss><channel><title>Blog</title><item><title>One</title></item></channel></rss>`,
+			wantStatus: ParseStatusOK,
+			wantTitle:  "Blog",
+			wantItems:  []string{"One"},
+		},
+		{
+			name: "truncated",
+			body: `<rss><channel><title>Blog</title>` +
+				`<item><title>One</title></item>` +
+				`<item><title>Two</title></item>` +
+				`<item><title>Thr`,
+			wantStatus: ParseStatusWarnings,
+			wantTitle:  "Blog",
+			wantItems:  []string{"One", "Two"},
+		},
+		{
+			name:       "undeclared html entities",
+			body:       `<rss><channel><title>Tom&nbsp;&amp;&nbsp;Jerry</title><item><title>Caf&eacute;</title></item></channel></rss>`,
+			wantStatus: ParseStatusWarnings,
+			wantTitle:  "Tom\u00a0&\u00a0Jerry",
+			wantItems:  []string{"Café"},
+		},
+		{
+			name:       "control characters",
+			body:       "<rss><channel><title>Bl\x01og</title><item><title>O\x0bne\x1f</title></item></channel></rss>",
+			wantStatus: ParseStatusWarnings,
+			wantTitle:  "Blog",
+			wantItems:  []string{"One"},
+		},
+		{
+			name:       "latin-1",
+			body:       "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><rss><channel><title>Caf\xe9</title><item><title>Na\xefve \xa3</title></item></channel></rss>",
+			wantStatus: ParseStatusOK,
+			wantTitle:  "Café",
+			wantItems:  []string{"Naïve £"},
+		},
+		{
+			name:       "windows-1252",
+			body:       "<?xml version='1.0' encoding='windows-1252'?><rss><channel><title>\x93Quoted\x94</title><item><title>\x80 5</title></item></channel></rss>",
+			wantStatus: ParseStatusOK,
+			wantTitle:  "“Quoted”",
+			wantItems:  []string{"€ 5"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			feed, err := ParseFeed([]byte(test.body))
+			if err != nil {
+				t.Fatalf("ParseFeed returned %v", err)
+			}
+			if feed.Status != test.wantStatus {
+				t.Errorf("status = %v, want %v (warnings %v)", feed.Status, test.wantStatus, feed.Warnings)
+			}
+			if feed.Channel.Title != test.wantTitle {
+				t.Errorf("title = %q, want %q", feed.Channel.Title, test.wantTitle)
+			}
+			if len(feed.Channel.Item) != len(test.wantItems) {
+				t.Fatalf("got %v items, want %v", len(feed.Channel.Item), len(test.wantItems))
+			}
+			for i, want := range test.wantItems {
+				if got := feed.Channel.Item[i].Title; got != want {
+					t.Errorf("item %v title = %q, want %q", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFeedRejectsUnrecoverableDocuments(t *testing.T) {
+	for _, body := range []string{"", "not xml at all", "<rss><channel><tit"} {
+		if _, err := ParseFeed([]byte(body)); err == nil {
+			t.Errorf("ParseFeed(%q) returned no error", body)
+		}
+	}
+}
+
+func TestStripInvalidXMLChars(t *testing.T) {
+	tests := []struct {
+		body         string
+		want         string
+		wantStripped int
+	}{
+		{"a\tb\nc\rd", "a\tb\nc\rd", 0},
+		{"a\x00b\x08c", "abc", 2},
+		{"￾a￿", "a", 2},
+		// Bytes of other charsets are kept for the decoder
+		{"<?xml version=\"1.0\" encoding=\"latin1\"?>\xe9\x01", "<?xml version=\"1.0\" encoding=\"latin1\"?>\xe9", 1},
+	}
+	for _, test := range tests {
+		got, stripped := stripInvalidXMLChars([]byte(test.body))
+		if string(got) != test.want || stripped != test.wantStripped {
+			t.Errorf("stripInvalidXMLChars(%q) = %q, %v, want %q, %v", test.body, got, stripped, test.want, test.wantStripped)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package network
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Generator named in published feeds
+const PUBLISH_GENERATOR = "gator"
+
+// Content types of published feeds
+const RSS_CONTENT_TYPE = "application/rss+xml; charset=utf-8"
+const ATOM_CONTENT_TYPE = "application/atom+xml; charset=utf-8"
+
+// A feed built from stored posts, rendered as rss or atom
+type PublishedFeed struct {
+	// Stable identifier, an IRI such as urn:uuid:...
+	ID      string
+	Title   string
+	Author  string
+	SelfURL string
+	Updated time.Time
+	Items   []PublishedItem
+}
+
+// A post of a published feed
+type PublishedItem struct {
+	// Stable identifier, an IRI such as urn:uuid:...
+	ID          string
+	Title       string
+	Link        string
+	Description string
+	Content     string
+	Author      string
+	Categories  []string
+	Published   time.Time
+}
+
+// Rss 2.0 document. Prefixed names are declared on the root element.
+type rssDocument struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	Description   string       `xml:"description"`
+	LastBuildDate string       `xml:"lastBuildDate"`
+	Generator     string       `xml:"generator"`
+	SelfLink      rssAtomLink  `xml:"atom:link"`
+	Items         []rssDocItem `xml:"item"`
+}
+
+type rssAtomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssDocItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link,omitempty"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"content:encoded,omitempty"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+	GUID        rssGUID  `xml:"guid"`
+}
+
+type rssGUID struct {
+	IsPermaLink string `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// Atom 1.0 document
+type atomDocument struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title     string      `xml:"title"`
+	Subtitle  string      `xml:"subtitle,omitempty"`
+	ID        string      `xml:"id"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link"`
+	Author    atomPerson  `xml:"author"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Links      []atomLink     `xml:"link"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published"`
+	Author     *atomPerson    `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary"`
+	Content    *atomText      `xml:"content"`
+}
+
+// Write the feed as an rss 2.0 document
+func RenderRSS(w io.Writer, feed PublishedFeed) error {
+	doc := rssDocument{
+		Version:   "2.0",
+		AtomNS:    ATOM_NAMESPACE,
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.SelfURL,
+			Description:   feed.Title,
+			LastBuildDate: feed.Updated.UTC().Format(time.RFC1123Z),
+			Generator:     PUBLISH_GENERATOR,
+			SelfLink:      rssAtomLink{Rel: "self", Href: feed.SelfURL, Type: "application/rss+xml"},
+		},
+	}
+
+	for _, item := range feed.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssDocItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			Content:     item.Content,
+			Creator:     item.Author,
+			Categories:  item.Categories,
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+			// The id is not a url, readers must not open it
+			GUID: rssGUID{IsPermaLink: "false", Value: item.ID},
+		})
+	}
+
+	return writeXML(w, doc)
+}
+
+// Write the feed as an atom 1.0 document
+func RenderAtom(w io.Writer, feed PublishedFeed) error {
+	doc := atomDocument{
+		Title:     feed.Title,
+		ID:        feed.ID,
+		Updated:   feed.Updated.UTC().Format(time.RFC3339),
+		Links:     []atomLink{{Rel: "self", Href: feed.SelfURL, Type: "application/atom+xml"}},
+		Author:    atomPerson{Name: feed.Author},
+		Generator: PUBLISH_GENERATOR,
+	}
+
+	for _, item := range feed.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.ID,
+			Updated:   item.Published.UTC().Format(time.RFC3339),
+			Published: item.Published.UTC().Format(time.RFC3339),
+		}
+		if item.Link != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "alternate", Href: item.Link})
+		}
+		if item.Author != "" {
+			entry.Author = &atomPerson{Name: item.Author}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		if item.Description != "" {
+			entry.Summary = &atomText{Type: "html", Value: item.Description}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: item.Content}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return writeXML(w, doc)
+}
+
+// Write an xml document with its declaration. Text is escaped by the encoder,
+// which also replaces characters xml can't represent.
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("error writing feed: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("error writing feed: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("error writing feed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package network
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Text that has to be escaped or is easy to mangle
+const PUBLISH_TEST_TITLE = "Tom & Jerry <3 ]]> café 日本語"
+const PUBLISH_TEST_HTML = `<p>Fish &amp; chips</p><![CDATA[x]]> — “quoted”`
+
+func testPublishedFeed() PublishedFeed {
+	published := time.Date(2024, 1, 31, 8, 30, 0, 0, time.FixedZone("CET", 3600))
+	return PublishedFeed{
+		ID:      "urn:uuid:6f1c1e52-7f36-4cf4-9d64-0b6d3e0c4b8a",
+		Title:   "gator: " + PUBLISH_TEST_TITLE,
+		Author:  "Zoë",
+		SelfURL: "https://example.com/rss?starred=true&limit=5",
+		Updated: published,
+		Items: []PublishedItem{
+			{
+				ID:          "urn:uuid:0c7b6a4e-1c1a-4a49-8f57-6f3f3f0f2a11",
+				Title:       PUBLISH_TEST_TITLE,
+				Link:        "https://blog.example.com/posts?id=1&lang=en",
+				Description: PUBLISH_TEST_HTML,
+				Content:     PUBLISH_TEST_HTML,
+				Author:      "Zoë",
+				Categories:  []string{"Go & Rust", "日本"},
+				Published:   published,
+			},
+			{
+				ID:        "urn:uuid:9a3e2d1c-2b4b-4c5d-8e6f-7a8b9c0d1e2f",
+				Title:     "Bare post",
+				Published: published.Add(-time.Hour),
+			},
+		},
+	}
+}
+
+// Rss as a reader sees it, with the prefixed elements resolved to their namespaces
+type testRSS struct {
+	XMLName xml.Name
+	Version string `xml:"version,attr"`
+	Channel struct {
+		Title    string `xml:"title"`
+		SelfLink struct {
+			XMLName xml.Name
+			Rel     string `xml:"rel,attr"`
+			Href    string `xml:"href,attr"`
+		} `xml:"http://www.w3.org/2005/Atom link"`
+		Items []struct {
+			Title       string   `xml:"title"`
+			Link        string   `xml:"link"`
+			Description string   `xml:"description"`
+			Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+			Categories  []string `xml:"category"`
+			PubDate     string   `xml:"pubDate"`
+			GUID        struct {
+				IsPermaLink string `xml:"isPermaLink,attr"`
+				Value       string `xml:",chardata"`
+			} `xml:"guid"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+func TestRenderRSS(t *testing.T) {
+	feed := testPublishedFeed()
+	var buffer bytes.Buffer
+	if err := RenderRSS(&buffer, feed); err != nil {
+		t.Fatalf("RenderRSS: %v", err)
+	}
+	output := buffer.String()
+	if !strings.HasPrefix(output, xml.Header) {
+		t.Errorf("the rss doesn't start with the xml declaration:\n%v", output)
+	}
+	if strings.Contains(output, "]]>") {
+		t.Errorf("the rss holds an unescaped ]]>:\n%v", output)
+	}
+
+	var doc testRSS
+	if err := xml.Unmarshal(buffer.Bytes(), &doc); err != nil {
+		t.Fatalf("the rss doesn't parse: %v\n%v", err, output)
+	}
+	if doc.XMLName.Local != "rss" || doc.XMLName.Space != "" || doc.Version != "2.0" {
+		t.Errorf("root is %+v version %q, want rss 2.0", doc.XMLName, doc.Version)
+	}
+	if doc.Channel.SelfLink.XMLName.Space != ATOM_NAMESPACE || doc.Channel.SelfLink.Rel != "self" || doc.Channel.SelfLink.Href != feed.SelfURL {
+		t.Errorf("self link is %+v, want an atom link to %v", doc.Channel.SelfLink, feed.SelfURL)
+	}
+	if doc.Channel.Title != feed.Title {
+		t.Errorf("channel title = %q, want %q", doc.Channel.Title, feed.Title)
+	}
+	if len(doc.Channel.Items) != 2 {
+		t.Fatalf("rss has %v items, want 2", len(doc.Channel.Items))
+	}
+
+	item, want := doc.Channel.Items[0], feed.Items[0]
+	for _, field := range []struct{ name, got, want string }{
+		{"title", item.Title, want.Title},
+		{"link", item.Link, want.Link},
+		{"description", item.Description, want.Description},
+		{"content:encoded", item.Content, want.Content},
+		{"dc:creator", item.Creator, want.Author},
+		{"pubDate", item.PubDate, "Wed, 31 Jan 2024 07:30:00 +0000"},
+		{"guid", item.GUID.Value, want.ID},
+		{"guid isPermaLink", item.GUID.IsPermaLink, "false"},
+	} {
+		if field.got != field.want {
+			t.Errorf("item %v = %q, want %q", field.name, field.got, field.want)
+		}
+	}
+	if !slices.Equal(item.Categories, want.Categories) {
+		t.Errorf("item categories = %q, want %q", item.Categories, want.Categories)
+	}
+
+	// Optional elements are left out rather than written empty
+	bare := doc.Channel.Items[1]
+	if bare.Link != "" || bare.Content != "" || bare.Creator != "" || len(bare.Categories) != 0 {
+		t.Errorf("bare item has %+v, want no link, content, creator or categories", bare)
+	}
+}
+
+func TestRenderAtom(t *testing.T) {
+	feed := testPublishedFeed()
+	var buffer bytes.Buffer
+	if err := RenderAtom(&buffer, feed); err != nil {
+		t.Fatalf("RenderAtom: %v", err)
+	}
+	output := buffer.String()
+	if strings.Contains(output, "]]>") {
+		t.Errorf("the atom feed holds an unescaped ]]>:\n%v", output)
+	}
+
+	var doc atomDocument
+	if err := xml.Unmarshal(buffer.Bytes(), &doc); err != nil {
+		t.Fatalf("the atom feed doesn't parse: %v\n%v", err, output)
+	}
+	if doc.XMLName.Local != "feed" || doc.XMLName.Space != ATOM_NAMESPACE {
+		t.Errorf("root is %+v, want feed in %v", doc.XMLName, ATOM_NAMESPACE)
+	}
+	if doc.ID != feed.ID || doc.Title != feed.Title || doc.Author.Name != feed.Author || doc.Updated != "2024-01-31T07:30:00Z" {
+		t.Errorf("feed is %q %q by %q updated %q, want %q %q by %q updated 2024-01-31T07:30:00Z",
+			doc.ID, doc.Title, doc.Author.Name, doc.Updated, feed.ID, feed.Title, feed.Author)
+	}
+	if len(doc.Links) != 1 || doc.Links[0].Rel != "self" || doc.Links[0].Href != feed.SelfURL {
+		t.Errorf("feed links are %+v, want a self link to %v", doc.Links, feed.SelfURL)
+	}
+	if len(doc.Entries) != 2 {
+		t.Fatalf("atom feed has %v entries, want 2", len(doc.Entries))
+	}
+
+	entry, want := doc.Entries[0], feed.Items[0]
+	if entry.ID != want.ID || entry.Title != want.Title || entry.Published != "2024-01-31T07:30:00Z" {
+		t.Errorf("entry is %q %q published %q, want %q %q published 2024-01-31T07:30:00Z", entry.ID, entry.Title, entry.Published, want.ID, want.Title)
+	}
+	if len(entry.Links) != 1 || entry.Links[0].Rel != "alternate" || entry.Links[0].Href != want.Link {
+		t.Errorf("entry links are %+v, want an alternate link to %v", entry.Links, want.Link)
+	}
+	if entry.Author == nil || entry.Author.Name != want.Author {
+		t.Errorf("entry author is %+v, want %v", entry.Author, want.Author)
+	}
+	if entry.Summary == nil || entry.Summary.Type != "html" || entry.Summary.Value != want.Description {
+		t.Errorf("entry summary is %+v, want html %q", entry.Summary, want.Description)
+	}
+	if entry.Content == nil || entry.Content.Type != "html" || entry.Content.Value != want.Content {
+		t.Errorf("entry content is %+v, want html %q", entry.Content, want.Content)
+	}
+	var categories []string
+	for _, category := range entry.Categories {
+		categories = append(categories, category.Term)
+	}
+	if !slices.Equal(categories, want.Categories) {
+		t.Errorf("entry categories = %q, want %q", categories, want.Categories)
+	}
+
+	bare := doc.Entries[1]
+	if len(bare.Links) != 0 || bare.Author != nil || bare.Summary != nil || bare.Content != nil {
+		t.Errorf("bare entry has %+v, want no link, author, summary or content", bare)
+	}
+}
+
+// Readers dedupe on the guid and id, so rendering the same feed again must not change them
+func TestRenderKeepsIdsStable(t *testing.T) {
+	for name, render := range map[string]func(io.Writer, PublishedFeed) error{
+		"rss":  RenderRSS,
+		"atom": RenderAtom,
+	} {
+		var first, second bytes.Buffer
+		if err := render(&first, testPublishedFeed()); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if err := render(&second, testPublishedFeed()); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if first.String() != second.String() {
+			t.Errorf("%v renders of the same feed differ:\n%v\n%v", name, first.String(), second.String())
+		}
+		for _, item := range testPublishedFeed().Items {
+			if !strings.Contains(first.String(), ">"+item.ID+"<") {
+				t.Errorf("%v doesn't hold the id %v:\n%v", name, item.ID, first.String())
+			}
+		}
+	}
+}
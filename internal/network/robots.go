@@ -0,0 +1,304 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default time robots.txt rules are cached for
+const DEFAULT_ROBOTS_TTL = 24 * time.Hour
+
+// Time after which a robots.txt that could not be fetched is retried
+const ROBOTS_ERROR_TTL = 10 * time.Minute
+
+// Maximum robots.txt size read, larger files are truncated
+const MAX_ROBOTS_SIZE = 500 * 1024
+
+// Longest crawl delay honoured. Agg waits inline before each fetch, so a
+// larger delay from one host would hold up every other feed.
+const MAX_CRAWL_DELAY = 30 * time.Second
+
+// Cache of the robots.txt rules of every host fetched from
+type RobotsCache struct {
+	client *Client
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+// Cached rules of a single host
+type robotsEntry struct {
+	rules      *robotsRules
+	expiresAt  time.Time
+	lastAccess time.Time
+	// Error fetching robots.txt, everything is disallowed until retried
+	err error
+}
+
+// Rules of the group matching our user agent
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+	// Disallow everything, used when robots.txt is unreachable
+	disallowAll bool
+}
+
+// A single Allow or Disallow line
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// Create a robots cache using the client for fetching robots.txt
+func newRobotsCache(client *Client, ttl time.Duration) *RobotsCache {
+	if ttl == 0 {
+		ttl = DEFAULT_ROBOTS_TTL
+	}
+	return &RobotsCache{
+		client:  client,
+		ttl:     ttl,
+		entries: make(map[string]*robotsEntry),
+	}
+}
+
+// Check whether robots.txt allows fetching the url.
+// An error is returned when robots.txt could not be fetched.
+func (r *RobotsCache) Allowed(ctx context.Context, rawURL string) (bool, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false, fmt.Errorf("error parsing url: %w", err)
+	}
+
+	entry := r.entry(ctx, u)
+	if entry.err != nil {
+		return false, fmt.Errorf("error fetching robots.txt: %w", entry.err)
+	}
+	return entry.rules.allowed(requestPath(u)), nil
+}
+
+// Block until the crawl delay of the url's host has passed since the last access
+func (r *RobotsCache) Wait(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("error parsing url: %w", err)
+	}
+
+	entry := r.entry(ctx, u)
+
+	r.mu.Lock()
+	wait := time.Until(entry.lastAccess.Add(entry.rules.crawlDelay))
+	entry.lastAccess = time.Now().Add(max(wait, 0))
+	r.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Get the cached entry of the host, fetching robots.txt when missing or expired
+func (r *RobotsCache) entry(ctx context.Context, u *url.URL) *robotsEntry {
+	host := strings.ToLower(u.Scheme + "://" + u.Host)
+
+	r.mu.Lock()
+	entry, ok := r.entries[host]
+	r.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry
+	}
+
+	rules, err := r.fetch(ctx, host+"/robots.txt")
+	fresh := &robotsEntry{rules: rules, expiresAt: time.Now().Add(r.ttl), err: err}
+	if err != nil {
+		fresh.expiresAt = time.Now().Add(min(r.ttl, ROBOTS_ERROR_TTL))
+	}
+	if ok {
+		fresh.lastAccess = entry.lastAccess
+	}
+
+	r.mu.Lock()
+	r.entries[host] = fresh
+	r.mu.Unlock()
+
+	return fresh
+}
+
+// Fetch and parse robots.txt. Missing files allow everything while
+// server and network errors disallow everything until retried.
+func (r *RobotsCache) fetch(ctx context.Context, robotsURL string) (*robotsRules, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", robotsURL, nil)
+	if err != nil {
+		return &robotsRules{disallowAll: true}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", r.client.userAgent)
+
+	res, err := r.client.httpClient.Do(req)
+	if err != nil {
+		return &robotsRules{disallowAll: true}, fmt.Errorf("error making the request %w", err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode >= http.StatusInternalServerError:
+		return &robotsRules{disallowAll: true}, fmt.Errorf("unexpected status code %v", res.StatusCode)
+	case res.StatusCode >= http.StatusBadRequest:
+		return &robotsRules{}, nil
+	}
+
+	return parseRobots(io.LimitReader(res.Body, MAX_ROBOTS_SIZE), productToken(r.client.userAgent)), nil
+}
+
+// Parse robots.txt keeping the groups which apply to the user agent.
+// Groups naming the agent take precedence over the "*" group.
+func parseRobots(body io.Reader, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+
+	var matched, wildcard robotsRules
+	foundMatched := false
+
+	// Agents of the group being read and whether a rule was seen since
+	var groupAgents []string
+	inRules := false
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			// A user-agent after rules starts a new group
+			if inRules {
+				groupAgents = nil
+				inRules = false
+			}
+			groupAgents = append(groupAgents, strings.ToLower(value))
+			continue
+		}
+
+		// Only group members end a run of user-agent lines, other records
+		// such as sitemap may appear anywhere
+		switch key {
+		case "allow", "disallow", "crawl-delay":
+			inRules = true
+		default:
+			continue
+		}
+
+		// Apply the line to the matching groups
+		var targets []*robotsRules
+		for _, groupAgent := range groupAgents {
+			if groupAgent == agent {
+				targets = append(targets, &matched)
+				foundMatched = true
+			} else if groupAgent == "*" {
+				targets = append(targets, &wildcard)
+			}
+		}
+
+		for _, target := range targets {
+			switch key {
+			case "allow", "disallow":
+				if value == "" {
+					continue
+				}
+				target.rules = append(target.rules, robotsRule{
+					allow:   key == "allow",
+					length:  len(value),
+					pattern: robotsPattern(value),
+				})
+			case "crawl-delay":
+				if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+					target.crawlDelay = time.Duration(min(seconds, MAX_CRAWL_DELAY.Seconds()) * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	if foundMatched {
+		return &matched
+	}
+	return &wildcard
+}
+
+// Compile a robots path pattern supporting "*" and a trailing "$"
+func robotsPattern(path string) *regexp.Regexp {
+	anchored := strings.HasSuffix(path, "$")
+	path = strings.TrimSuffix(path, "$")
+
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(path), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// Check the path against the rules. The longest matching rule wins and
+// allow wins when an allow and a disallow rule are equally long.
+func (r *robotsRules) allowed(path string) bool {
+	if path == "/robots.txt" {
+		return true
+	}
+	if r.disallowAll {
+		return false
+	}
+
+	allowed := true
+	longest := -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > longest || (rule.length == longest && rule.allow) {
+			allowed = rule.allow
+			longest = rule.length
+		}
+	}
+	return allowed
+}
+
+// Path and query of the url as matched against robots rules
+func requestPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path
+}
+
+// Product token of the user agent, e.g. "gator" for "gator/1.0 (+https://example.com)"
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(userAgent, " ")
+	token, _, _ = strings.Cut(token, "/")
+	return token
+}
@@ -0,0 +1,105 @@
+package network
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRobotsGroups(t *testing.T) {
+	body := `# comment
+User-agent: other
+User-agent: gator
+Sitemap: https://example.com/sitemap.xml
+User-agent: gator-news
+Disallow: /private
+Crawl-delay: 2
+
+User-agent: *
+Disallow: /
+`
+	tests := []struct {
+		agent     string
+		path      string
+		want      bool
+		wantDelay time.Duration
+	}{
+		// The sitemap line doesn't end the group, every agent shares its rules
+		{"other", "/private/a", false, 2 * time.Second},
+		{"gator", "/private/a", false, 2 * time.Second},
+		{"Gator-News", "/private/a", false, 2 * time.Second},
+		{"gator", "/public", true, 2 * time.Second},
+		// Agents without a group of their own fall back to "*"
+		{"unknown", "/public", false, 0},
+	}
+	for _, test := range tests {
+		rules := parseRobots(strings.NewReader(body), test.agent)
+		if got := rules.allowed(test.path); got != test.want {
+			t.Errorf("agent %v allowed(%v) = %v, want %v", test.agent, test.path, got, test.want)
+		}
+		if rules.crawlDelay != test.wantDelay {
+			t.Errorf("agent %v crawl delay = %v, want %v", test.agent, rules.crawlDelay, test.wantDelay)
+		}
+	}
+}
+
+func TestParseRobotsWithoutMatchingGroup(t *testing.T) {
+	rules := parseRobots(strings.NewReader("User-agent: other\nDisallow: /\n"), "gator")
+	if !rules.allowed("/feed.xml") {
+		t.Error("rules of another agent applied without a * group")
+	}
+}
+
+func TestRobotsLongestMatchWins(t *testing.T) {
+	body := `User-agent: *
+Disallow: /blog
+Allow: /blog/feed
+Disallow: /blog/feed/private
+Allow: /page
+Disallow: /page
+Disallow: /*.php$
+Disallow:
+`
+	rules := parseRobots(strings.NewReader(body), "gator")
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/blog", false},
+		{"/blog/post", false},
+		{"/blog/feed", true},
+		{"/blog/feed.xml", true},
+		{"/blog/feed/private/1", false},
+		// Allow wins a tie
+		{"/page", true},
+		{"/index.php", false},
+		{"/index.php?x=1", true},
+		{"/robots.txt", true},
+	}
+	for _, test := range tests {
+		if got := rules.allowed(test.path); got != test.want {
+			t.Errorf("allowed(%v) = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestParseRobotsLimitsTheCrawlDelay(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"1.5", 1500 * time.Millisecond},
+		{"86400", MAX_CRAWL_DELAY},
+		{"inf", MAX_CRAWL_DELAY},
+		{"-5", 0},
+		{"NaN", 0},
+		{"soon", 0},
+	}
+	for _, test := range tests {
+		rules := parseRobots(strings.NewReader("User-agent: *\nCrawl-delay: "+test.value+"\n"), "gator")
+		if rules.crawlDelay != test.want {
+			t.Errorf("Crawl-delay: %v parsed as %v, want %v", test.value, rules.crawlDelay, test.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Webhooks pointing into the local network, which would let users make the
+// aggregator send requests to internal services
+var ErrPrivateAddress = errors.New("webhooks can't be sent to loopback, private or link-local addresses")
+
+// Post the payload as json, e.g. to a chat webhook
+func (c *Client) PostJSON(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshalling payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making the request %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("unexpected status code %v: %v", res.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// Check that every address the host resolves to is public
+func CheckPublicHost(ctx context.Context, host string) error {
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("error resolving %v: %w", host, err)
+	}
+	for _, ip := range ips {
+		if !publicIP(ip.IP) {
+			return fmt.Errorf("%v resolves to %v: %w", host, ip.IP, ErrPrivateAddress)
+		}
+	}
+	return nil
+}
+
+// Ranges not covered by the net.IP checks: "this network" and carrier-grade NAT
+var nonPublicNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// Whether the address is reachable on the internet rather than local
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// Client that refuses to connect to local addresses. The check runs on the
+// resolved address of every connection, redirects included, so hosts can't
+// get around it by resolving to a public address first. Proxies are not used,
+// since the check would only see the proxy's address.
+func newWebhookClient(connectTimeout time.Duration, tlsConfig *tls.Config, totalTimeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   connectTimeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network string, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("%v: %w", host, ErrPrivateAddress)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSClientConfig:     tlsConfig,
+			TLSHandshakeTimeout: connectTimeout,
+			IdleConnTimeout:     90 * time.Second,
+			ForceAttemptHTTP2:   true,
+		},
+		Timeout: totalTimeout,
+	}
+}
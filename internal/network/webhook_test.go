@@ -0,0 +1,60 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, test := range tests {
+		if got := publicIP(net.ParseIP(test.ip)); got != test.want {
+			t.Errorf("publicIP(%v) = %v, want %v", test.ip, got, test.want)
+		}
+	}
+}
+
+func TestPostJSONRefusesLocalAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.PostJSON(context.Background(), server.URL, map[string]string{"text": "hello"})
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("PostJSON to %v returned %v, want ErrPrivateAddress", server.URL, err)
+	}
+	if called {
+		t.Error("the local server received the webhook")
+	}
+
+	if err := CheckPublicHost(context.Background(), "127.0.0.1"); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("CheckPublicHost(127.0.0.1) returned %v, want ErrPrivateAddress", err)
+	}
+}
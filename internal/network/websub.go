@@ -0,0 +1,235 @@
+package network
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maximum size of content pushed by a hub
+const MAX_WEBSUB_CONTENT_SIZE = 10 * 1024 * 1024
+
+// Path prefix of the callback urls, followed by the subscription id
+const WEBSUB_CALLBACK_PATH = "/websub/"
+
+// A subscription request sent to a hub
+type WebSubRequest struct {
+	HubURL      string
+	TopicURL    string
+	CallbackURL string
+	Secret      string
+	Lease       time.Duration
+}
+
+// Ask the hub to subscribe the callback to the topic.
+// The hub confirms asynchronously by verifying the intent on the callback.
+func (c *Client) WebSubSubscribe(ctx context.Context, req WebSubRequest) error {
+	return c.webSubRequest(ctx, "subscribe", req)
+}
+
+// Ask the hub to stop sending updates for the topic
+func (c *Client) WebSubUnsubscribe(ctx context.Context, req WebSubRequest) error {
+	return c.webSubRequest(ctx, "unsubscribe", req)
+}
+
+// Send a subscribe or unsubscribe request to the hub
+func (c *Client) webSubRequest(ctx context.Context, mode string, req WebSubRequest) error {
+	form := url.Values{}
+	form.Set("hub.mode", mode)
+	form.Set("hub.topic", req.TopicURL)
+	form.Set("hub.callback", req.CallbackURL)
+	if req.Secret != "" {
+		form.Set("hub.secret", req.Secret)
+	}
+	if req.Lease > 0 {
+		form.Set("hub.lease_seconds", strconv.Itoa(int(req.Lease.Seconds())))
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", req.HubURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	httpReq.Header.Set("User-Agent", c.userAgent)
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("error making the request %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("hub rejected %v request with status %v: %v", mode, res.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// Subscription known to the callback server
+type WebSubSubscription struct {
+	TopicURL string
+	Secret   string
+}
+
+// Callback endpoint for WebSub hubs. Subscriptions are identified by the
+// last path segment of the callback url, e.g. /websub/<id>.
+type WebSubCallback struct {
+	// Find the subscription for the id
+	Lookup func(id string) (WebSubSubscription, bool)
+	// Called when the hub verified a subscribe or unsubscribe intent
+	OnVerified func(id string, mode string, lease time.Duration)
+	// Called when the hub denied the subscription
+	OnDenied func(id string, reason string)
+	// Called with content pushed by the hub after its signature was validated
+	OnContent func(id string, feed *RSSFeed)
+	// Called when signed content could not be parsed
+	OnInvalidContent func(id string, err error)
+}
+
+// Handle intent verification and content distribution requests
+func (c *WebSubCallback) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, WEBSUB_CALLBACK_PATH)
+	subscription, ok := c.Lookup(id)
+	if !ok {
+		// Subscriptions are forgotten as soon as gator asks the hub to
+		// unsubscribe, so confirm unsubscribing from ones we don't know
+		if r.Method == http.MethodGet && r.URL.Query().Get("hub.mode") == "unsubscribe" {
+			c.confirmUnsubscribe(w, r)
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		c.verify(w, r, id, subscription)
+	case http.MethodPost:
+		c.receive(w, r, id, subscription)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// Answer the hub's intent verification by echoing the challenge
+func (c *WebSubCallback) verify(w http.ResponseWriter, r *http.Request, id string, subscription WebSubSubscription) {
+	query := r.URL.Query()
+	mode := query.Get("hub.mode")
+
+	if mode == "denied" {
+		c.OnDenied(id, query.Get("hub.reason"))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// Only confirm requests we made for this topic
+	if (mode != "subscribe" && mode != "unsubscribe") || query.Get("hub.topic") != subscription.TopicURL {
+		http.NotFound(w, r)
+		return
+	}
+
+	challenge := query.Get("hub.challenge")
+	if challenge == "" {
+		http.Error(w, "missing hub.challenge", http.StatusBadRequest)
+		return
+	}
+
+	var lease time.Duration
+	if seconds, err := strconv.Atoi(query.Get("hub.lease_seconds")); err == nil {
+		lease = time.Duration(seconds) * time.Second
+	}
+	c.OnVerified(id, mode, lease)
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(challenge))
+}
+
+// Echo the challenge of an unsubscribe verification
+func (c *WebSubCallback) confirmUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	challenge := r.URL.Query().Get("hub.challenge")
+	if challenge == "" {
+		http.Error(w, "missing hub.challenge", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(challenge))
+}
+
+// Receive pushed content, ignoring it when the signature is not valid
+func (c *WebSubCallback) receive(w http.ResponseWriter, r *http.Request, id string, subscription WebSubSubscription) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, MAX_WEBSUB_CONTENT_SIZE))
+	if err != nil {
+		http.Error(w, "error reading body", http.StatusBadRequest)
+		return
+	}
+
+	// The hub must be acknowledged even when the content is discarded
+	w.WriteHeader(http.StatusAccepted)
+
+	if subscription.Secret != "" && !VerifyWebSubSignature(subscription.Secret, r.Header.Get("X-Hub-Signature"), body) {
+		return
+	}
+
+	feed, err := ParseFeed(body)
+	if err != nil {
+		c.OnInvalidContent(id, err)
+		return
+	}
+	c.OnContent(id, feed)
+}
+
+// Validate an X-Hub-Signature header of the form "sha256=<hex hmac>"
+func VerifyWebSubSignature(secret string, header string, body []byte) bool {
+	method, signature, ok := strings.Cut(header, "=")
+	if !ok {
+		return false
+	}
+
+	var newHash func() hash.Hash
+	switch method {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return false
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// Generate a random secret for signing pushed content
+func NewWebSubSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("error generating secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
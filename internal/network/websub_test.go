@@ -0,0 +1,246 @@
+package network
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testWebSubFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>Pushed</title><link>https://example.com</link>
+<item><title>Pushed post</title><link>https://example.com/pushed</link></item>
+</channel></rss>`
+
+const testWebSubAtomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Pushed</title>
+<link rel="hub" href="https://hub.example.com/"/>
+<entry><title>Pushed atom post</title><link href="https://example.com/atom"/>
+<updated>2026-10-19T08:00:00Z</updated><author><name>Ann</name></author>
+<summary>Summary</summary></entry>
+</feed>`
+
+// Hub that records subscription requests and can verify intents and push content
+type testHub struct {
+	t        *testing.T
+	requests chan url.Values
+}
+
+func (h *testHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.t.Errorf("hub: error parsing form: %v", err)
+	}
+	h.requests <- r.PostForm
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Verify the intent like a hub does, returning the echoed challenge
+func (h *testHub) verify(callbackURL string, mode string, topic string) (int, string) {
+	query := url.Values{}
+	query.Set("hub.mode", mode)
+	query.Set("hub.topic", topic)
+	query.Set("hub.challenge", "challenge-"+mode)
+	query.Set("hub.lease_seconds", "3600")
+	res, err := http.Get(callbackURL + "?" + query.Encode())
+	if err != nil {
+		h.t.Fatalf("hub: error verifying intent: %v", err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	return res.StatusCode, string(body)
+}
+
+// Push the content signed with the secret
+func (h *testHub) push(callbackURL string, secret string, body string) {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	req, _ := http.NewRequest("POST", callbackURL, strings.NewReader(body))
+	req.Header.Set("X-Hub-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		h.t.Fatalf("hub: error pushing content: %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		h.t.Fatalf("hub: push answered %v, want %v", res.StatusCode, http.StatusAccepted)
+	}
+}
+
+func TestWebSubSubscribeVerifyAndDeliver(t *testing.T) {
+	hub := &testHub{t: t, requests: make(chan url.Values, 2)}
+	hubServer := httptest.NewServer(hub)
+	defer hubServer.Close()
+
+	const topic = "https://example.com/feed.xml"
+	const secret = "shared-secret"
+	subscriptions := map[string]WebSubSubscription{"sub1": {TopicURL: topic, Secret: secret}}
+
+	var verified []string
+	var lease time.Duration
+	pushed := make(chan *RSSFeed, 2)
+	invalid := make(chan error, 1)
+	callback := &WebSubCallback{
+		Lookup: func(id string) (WebSubSubscription, bool) {
+			subscription, ok := subscriptions[id]
+			return subscription, ok
+		},
+		OnVerified: func(id string, mode string, l time.Duration) {
+			verified = append(verified, id+":"+mode)
+			lease = l
+		},
+		OnDenied: func(id string, reason string) {
+			t.Errorf("unexpected denial of %v: %v", id, reason)
+		},
+		OnContent: func(id string, feed *RSSFeed) {
+			pushed <- feed
+		},
+		OnInvalidContent: func(id string, err error) {
+			invalid <- err
+		},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(WEBSUB_CALLBACK_PATH, callback)
+	callbackServer := httptest.NewServer(mux)
+	defer callbackServer.Close()
+	callbackURL := callbackServer.URL + WEBSUB_CALLBACK_PATH + "sub1"
+
+	client, err := NewClient(ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Subscribe
+	err = client.WebSubSubscribe(context.Background(), WebSubRequest{
+		HubURL:      hubServer.URL,
+		TopicURL:    topic,
+		CallbackURL: callbackURL,
+		Secret:      secret,
+		Lease:       time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	form := <-hub.requests
+	for key, want := range map[string]string{
+		"hub.mode":          "subscribe",
+		"hub.topic":         topic,
+		"hub.callback":      callbackURL,
+		"hub.secret":        secret,
+		"hub.lease_seconds": "3600",
+	} {
+		if got := form.Get(key); got != want {
+			t.Errorf("subscribe request %v = %q, want %q", key, got, want)
+		}
+	}
+
+	// Verify
+	status, body := hub.verify(callbackURL, "subscribe", topic)
+	if status != http.StatusOK || body != "challenge-subscribe" {
+		t.Fatalf("verification answered %v %q, want 200 with the challenge", status, body)
+	}
+	if len(verified) != 1 || verified[0] != "sub1:subscribe" || lease != time.Hour {
+		t.Fatalf("verified %v with lease %v, want sub1:subscribe for 1h", verified, lease)
+	}
+	if status, _ := hub.verify(callbackURL, "subscribe", "https://other.example.com/feed.xml"); status != http.StatusNotFound {
+		t.Errorf("verification of another topic answered %v, want 404", status)
+	}
+
+	// Signed delivery
+	hub.push(callbackURL, secret, testWebSubFeed)
+	select {
+	case feed := <-pushed:
+		if len(feed.Channel.Item) != 1 || feed.Channel.Item[0].Title != "Pushed post" {
+			t.Errorf("pushed feed has items %+v", feed.Channel.Item)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("signed content was not delivered")
+	}
+
+	// Hubs commonly push atom
+	hub.push(callbackURL, secret, testWebSubAtomFeed)
+	select {
+	case feed := <-pushed:
+		if len(feed.Channel.Item) != 1 {
+			t.Fatalf("pushed atom feed has items %+v", feed.Channel.Item)
+		}
+		item := feed.Channel.Item[0]
+		if item.Title != "Pushed atom post" || item.Link != "https://example.com/atom" ||
+			item.PubDate != "2026-10-19T08:00:00Z" || item.Author != "Ann" || item.Description != "Summary" {
+			t.Errorf("pushed atom item is %+v", item)
+		}
+		if feed.HubURL() != "https://hub.example.com/" {
+			t.Errorf("pushed atom hub is %q", feed.HubURL())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("signed atom content was not delivered")
+	}
+
+	// Content that can't be parsed is reported so the feed is polled instead
+	hub.push(callbackURL, secret, "not a feed")
+	select {
+	case <-invalid:
+	case <-pushed:
+		t.Fatal("unparsable content was delivered")
+	case <-time.After(time.Second):
+		t.Fatal("unparsable content was not reported")
+	}
+
+	// Content with a wrong signature is acknowledged but dropped
+	hub.push(callbackURL, "wrong-secret", testWebSubFeed)
+	select {
+	case <-pushed:
+		t.Fatal("content with a wrong signature was delivered")
+	default:
+	}
+
+	// Unsubscribe, after which gator has already forgotten the subscription
+	err = client.WebSubUnsubscribe(context.Background(), WebSubRequest{
+		HubURL:      hubServer.URL,
+		TopicURL:    topic,
+		CallbackURL: callbackURL,
+	})
+	if err != nil {
+		t.Fatalf("unsubscribe: %v", err)
+	}
+	if form := <-hub.requests; form.Get("hub.mode") != "unsubscribe" || form.Get("hub.topic") != topic {
+		t.Errorf("unsubscribe request was %v", form)
+	}
+	delete(subscriptions, "sub1")
+	status, body = hub.verify(callbackURL, "unsubscribe", topic)
+	if status != http.StatusOK || body != "challenge-unsubscribe" {
+		t.Errorf("unsubscribe verification answered %v %q, want 200 with the challenge", status, body)
+	}
+	if status, _ := hub.verify(callbackURL, "subscribe", topic); status != http.StatusNotFound {
+		t.Errorf("subscribe verification of an unknown subscription answered %v, want 404", status)
+	}
+}
+
+func TestVerifyWebSubSignature(t *testing.T) {
+	body := []byte("content")
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"sha256=" + signature, true},
+		{"sha256=" + strings.Repeat("0", len(signature)), false},
+		{"md5=" + signature, false},
+		{signature, false},
+		{"", false},
+	}
+	for _, test := range tests {
+		if got := VerifyWebSubSignature("secret", test.header, body); got != test.want {
+			t.Errorf("VerifyWebSubSignature(%q) = %v, want %v", test.header, got, test.want)
+		}
+	}
+}
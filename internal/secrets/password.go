@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id parameters for new password hashes, the OWASP recommended minimum
+const ARGON2_TIME = 3
+const ARGON2_MEMORY = 64 * 1024
+const ARGON2_THREADS = 2
+const ARGON2_KEY_LENGTH = 32
+const ARGON2_SALT_LENGTH = 16
+
+// Size of session and api tokens in bytes
+const TOKEN_SIZE = 32
+
+// Hash a password with argon2id. The result is in the PHC string format,
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>, so the parameters can change later.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, ARGON2_SALT_LENGTH)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generating salt: %w", err)
+	}
+
+	hash := argon2.IDKey([]byte(password), salt, ARGON2_TIME, ARGON2_MEMORY, ARGON2_THREADS, ARGON2_KEY_LENGTH)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, ARGON2_MEMORY, ARGON2_TIME, ARGON2_THREADS,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(hash),
+	), nil
+}
+
+// Check a password against a hash made by HashPassword
+func CheckPassword(password string, encoded string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, fmt.Errorf("unsupported password hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, fmt.Errorf("unsupported argon2 version %v", parts[2])
+	}
+
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false, fmt.Errorf("error parsing argon2 parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("error decoding salt: %w", err)
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, fmt.Errorf("error decoding hash: %w", err)
+	}
+
+	hash := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(expected)))
+	return subtle.ConstantTimeCompare(hash, expected) == 1, nil
+}
+
+// Generate a random token for a session or an api token
+func NewToken() (string, error) {
+	token := make([]byte, TOKEN_SIZE)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("error generating token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// Hash a token for storage. Tokens are random, so a plain sha256 is enough
+// and lets them be looked up by hash.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
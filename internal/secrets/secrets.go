@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// Size of the AES-256 key in bytes
+const KEY_SIZE = 32
+
+// Decode a base64 encoded AES-256 key
+func ParseKey(encoded string) ([]byte, error) {
+	if encoded == "" {
+		return nil, fmt.Errorf("encryption key is not set")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding encryption key: %w", err)
+	}
+	if len(key) != KEY_SIZE {
+		return nil, fmt.Errorf("encryption key must be %v bytes, got %v", KEY_SIZE, len(key))
+	}
+
+	return key, nil
+}
+
+// Encrypt with AES-GCM. The random nonce is prepended to the ciphertext.
+func Encrypt(key []byte, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt data produced by Encrypt
+func Decrypt(key []byte, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting data: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// Create the AES-GCM cipher for the key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creating gcm: %w", err)
+	}
+
+	return gcm, nil
+}
@@ -27,10 +27,18 @@ func main() {
 
 	dbQueries := database.New(db)
 
+	// Shared http client for every fetch
+	httpClient, err := currentConfig.NewHTTPClient()
+	if err != nil {
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
 	// Initialize State
 	currentState := config.State{
 		Config: &currentConfig,
 		Db:     dbQueries,
+		Client: httpClient,
 	}
 
 	// Commands struct
@@ -41,15 +49,39 @@ func main() {
 	// Register Login Command
 	commands.Register("login", config.LoginHandler)
 	commands.Register("register", config.RegisterHandler)
-	commands.Register("reset", config.ResetHandler)
+	commands.Register("logout", config.LogoutHandler)
+	commands.Register("reset", config.MiddlewareLoggedIn(config.ResetHandler))
 	commands.Register("users", config.UsersHandler)
+	commands.Register("user", config.AllowPasswordBootstrap(config.MiddlewareLoggedIn(config.UserHandler)))
+	commands.Register("whoami", config.MiddlewareLoggedIn(config.WhoamiHandler))
 	commands.Register("agg", config.AggHandler)
 	commands.Register("addfeed", config.MiddlewareLoggedIn(config.AddFeedHandler))
 	commands.Register("feeds", config.FeedsHandler)
+	commands.Register("feed", config.MiddlewareLoggedIn(config.FeedHandler))
 	commands.Register("follow", config.MiddlewareLoggedIn(config.FollowHandler))
 	commands.Register("following", config.MiddlewareLoggedIn(config.FollowingHandler))
 	commands.Register("unfollow", config.MiddlewareLoggedIn(config.UnfollowHandler))
 	commands.Register("browse", config.MiddlewareLoggedIn(config.BrowseHandler))
+	commands.Register("feedauth", config.MiddlewareLoggedIn(config.FeedAuthHandler))
+	commands.Register("robots", config.MiddlewareLoggedIn(config.RobotsHandler))
+	commands.Register("read", config.MiddlewareLoggedIn(config.ReadHandler))
+	commands.Register("unread", config.MiddlewareLoggedIn(config.UnreadHandler))
+	commands.Register("mark-read", config.MiddlewareLoggedIn(config.MarkReadHandler))
+	commands.Register("mark-all-read", config.MiddlewareLoggedIn(config.MarkAllReadHandler))
+	commands.Register("star", config.MiddlewareLoggedIn(config.StarHandler))
+	commands.Register("unstar", config.MiddlewareLoggedIn(config.UnstarHandler))
+	commands.Register("saved", config.MiddlewareLoggedIn(config.SavedHandler))
+	commands.Register("later", config.MiddlewareLoggedIn(config.LaterHandler))
+	commands.Register("search", config.MiddlewareLoggedIn(config.SearchHandler))
+	commands.Register("searches", config.MiddlewareLoggedIn(config.SavedSearchesHandler))
+	commands.Register("filter", config.MiddlewareLoggedIn(config.FilterHandler))
+	commands.Register("folder", config.MiddlewareLoggedIn(config.FolderHandler))
+	commands.Register("publish", config.MiddlewareLoggedIn(config.PublishHandler))
+	commands.Register("share", config.MiddlewareLoggedIn(config.ShareHandler))
+	commands.Register("inbox", config.MiddlewareLoggedIn(config.InboxHandler))
+	commands.Register("digest", config.MiddlewareLoggedIn(config.DigestHandler))
+	commands.Register("token", config.MiddlewareLoggedIn(config.TokenHandler))
+	commands.Register("serve", config.ServeHandler)
 
 	cmdArguments := os.Args
 	if len(cmdArguments) < 2 {
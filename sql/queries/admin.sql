@@ -0,0 +1,59 @@
+-- name: GetTableCounts :one
+SELECT
+    (SELECT COUNT(*) FROM users) AS users,
+    (SELECT COUNT(*) FROM sessions) AS sessions,
+    (SELECT COUNT(*) FROM api_tokens) AS api_tokens,
+    (SELECT COUNT(*) FROM feeds) AS feeds,
+    (SELECT COUNT(*) FROM feed_follows) AS feed_follows,
+    (SELECT COUNT(*) FROM folders) AS folders,
+    (SELECT COUNT(*) FROM posts) AS posts,
+    (SELECT COUNT(*) FROM post_reads) AS post_reads,
+    (SELECT COUNT(*) FROM saved_posts) AS saved_posts,
+    (SELECT COUNT(*) FROM saved_searches) AS saved_searches,
+    (SELECT COUNT(*) FROM saved_search_matches) AS saved_search_matches,
+    (SELECT COUNT(*) FROM mute_filters) AS mute_filters,
+    (SELECT COUNT(*) FROM websub_subscriptions) AS websub_subscriptions,
+    (SELECT COUNT(*) FROM shares) AS shares,
+    (SELECT COUNT(*) FROM digest_subscriptions) AS digest_subscriptions,
+    (SELECT COUNT(*) FROM digests) AS digests,
+    (SELECT COUNT(*) FROM digest_posts) AS digest_posts;
+
+-- name: GetUserRowCounts :one
+SELECT
+    (SELECT COUNT(*) FROM sessions WHERE sessions.user_id = $1) AS sessions,
+    (SELECT COUNT(*) FROM api_tokens WHERE api_tokens.user_id = $1) AS api_tokens,
+    (SELECT COUNT(*) FROM feeds WHERE feeds.user_id = $1) AS feeds,
+    (SELECT COUNT(*) FROM feed_follows WHERE feed_follows.user_id = $1) AS feed_follows,
+    (SELECT COUNT(*) FROM folders WHERE folders.user_id = $1) AS folders,
+    (SELECT COUNT(*) FROM post_reads WHERE post_reads.user_id = $1) AS post_reads,
+    (SELECT COUNT(*) FROM saved_posts WHERE saved_posts.user_id = $1) AS saved_posts,
+    (SELECT COUNT(*) FROM saved_searches WHERE saved_searches.user_id = $1) AS saved_searches,
+    (SELECT COUNT(*) FROM mute_filters WHERE mute_filters.user_id = $1) AS mute_filters,
+    (SELECT COUNT(*) FROM shares WHERE shares.from_user_id = $1 OR shares.to_user_id = $1) AS shares,
+    (SELECT COUNT(*) FROM digest_subscriptions WHERE digest_subscriptions.user_id = $1) AS digest_subscriptions,
+    (SELECT COUNT(*) FROM digests WHERE digests.user_id = $1) AS digests;
+
+-- name: GetDatabaseSnapshot :one
+SELECT json_build_object(
+    'users', (SELECT COALESCE(json_agg(t), '[]') FROM users t),
+    'sessions', (SELECT COALESCE(json_agg(t), '[]') FROM sessions t),
+    'api_tokens', (SELECT COALESCE(json_agg(t), '[]') FROM api_tokens t),
+    'bootstrap_codes', (SELECT COALESCE(json_agg(t), '[]') FROM bootstrap_codes t),
+    'feeds', (SELECT COALESCE(json_agg(t), '[]') FROM feeds t),
+    'feed_follows', (SELECT COALESCE(json_agg(t), '[]') FROM feed_follows t),
+    'folders', (SELECT COALESCE(json_agg(t), '[]') FROM folders t),
+    'posts', (SELECT COALESCE(json_agg(t), '[]') FROM posts t),
+    'post_reads', (SELECT COALESCE(json_agg(t), '[]') FROM post_reads t),
+    'saved_posts', (SELECT COALESCE(json_agg(t), '[]') FROM saved_posts t),
+    'saved_searches', (SELECT COALESCE(json_agg(t), '[]') FROM saved_searches t),
+    'saved_search_matches', (SELECT COALESCE(json_agg(t), '[]') FROM saved_search_matches t),
+    'mute_filters', (SELECT COALESCE(json_agg(t), '[]') FROM mute_filters t),
+    'websub_subscriptions', (SELECT COALESCE(json_agg(t), '[]') FROM websub_subscriptions t),
+    'shares', (SELECT COALESCE(json_agg(t), '[]') FROM shares t),
+    'digest_subscriptions', (SELECT COALESCE(json_agg(t), '[]') FROM digest_subscriptions t),
+    'digests', (SELECT COALESCE(json_agg(t), '[]') FROM digests t),
+    'digest_posts', (SELECT COALESCE(json_agg(t), '[]') FROM digest_posts t)
+)::text AS snapshot;
+
+-- name: ResetAll :exec
+TRUNCATE users, feeds CASCADE;
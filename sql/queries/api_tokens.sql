@@ -0,0 +1,30 @@
+-- name: CreateApiToken :one
+INSERT INTO api_tokens (id, created_at, user_id, name, token_hash, scope, expires_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+RETURNING *;
+
+-- name: GetApiTokensForUser :many
+SELECT * FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetApiTokenByHash :one
+SELECT * FROM api_tokens
+WHERE token_hash = $1;
+
+-- name: TouchApiToken :exec
+UPDATE api_tokens
+SET last_used_at = $1
+WHERE id = $2;
+
+-- name: DeleteApiToken :execrows
+DELETE FROM api_tokens
+WHERE user_id = $1 AND name = $2;
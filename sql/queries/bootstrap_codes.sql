@@ -0,0 +1,15 @@
+-- name: CreateBootstrapCode :exec
+INSERT INTO bootstrap_codes (id, created_at, expires_at, code_hash)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4
+);
+
+-- name: DeleteBootstrapCodes :exec
+DELETE FROM bootstrap_codes;
+
+-- name: UseBootstrapCode :execrows
+DELETE FROM bootstrap_codes
+WHERE code_hash = $1 AND expires_at > $2;
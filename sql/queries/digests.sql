@@ -0,0 +1,80 @@
+-- name: UpsertDigestSubscription :exec
+INSERT INTO digest_subscriptions (user_id, created_at, updated_at, email)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (user_id) DO UPDATE
+SET email = EXCLUDED.email, updated_at = EXCLUDED.updated_at;
+
+-- name: DeleteDigestSubscription :execrows
+DELETE FROM digest_subscriptions
+WHERE user_id = $1;
+
+-- name: GetDigestSubscriptions :many
+SELECT digest_subscriptions.user_id, users.name, digest_subscriptions.email, digest_subscriptions.created_at
+FROM digest_subscriptions
+INNER JOIN users ON users.id = digest_subscriptions.user_id
+ORDER BY users.name;
+
+-- name: GetDigestPostsForUser :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.author, posts.published_at,
+    feeds.id AS feed_id,
+    COALESCE(feed_follows.display_name, feeds.name) AS feed_name
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND posts.created_at > sqlc.arg(subscribed_at)
+    AND NOT EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM digest_posts
+        INNER JOIN digests ON digests.id = digest_posts.digest_id
+        WHERE digest_posts.post_id = posts.id AND digests.user_id = feed_follows.user_id
+    )
+    AND (
+        feed_follows.match_filter IS NULL
+        OR posts.title ~* feed_follows.match_filter
+        OR posts.description ~* feed_follows.match_filter
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mute_filters
+        WHERE mute_filters.user_id = feed_follows.user_id
+            AND mute_filter_matches(
+                mute_filters.kind,
+                mute_filters.pattern,
+                posts.title,
+                posts.description,
+                posts.author,
+                posts.url,
+                posts.categories
+            )
+    )
+ORDER BY feed_follows.priority DESC, feed_name, feeds.id, posts.published_at DESC
+LIMIT sqlc.arg(post_limit);
+
+-- name: CreateDigest :one
+INSERT INTO digests (id, sent_at, user_id, email, post_count)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: CreateDigestPosts :exec
+INSERT INTO digest_posts (digest_id, post_id)
+SELECT sqlc.arg(digest_id), unnest(sqlc.arg(post_ids)::uuid[]);
+
+-- name: GetDigestsForUser :many
+SELECT * FROM digests
+WHERE user_id = $1
+ORDER BY sent_at DESC
+LIMIT $2;
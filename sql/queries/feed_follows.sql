@@ -22,13 +22,44 @@ INNER JOIN feeds ON inserted_feed_follow.feed_id = feeds.id;
 SELECT 
     feed_follows.*,
     users.name as user_name,
-    feeds.name as feed_name
+    COALESCE(feed_follows.display_name, feeds.name) as feed_name,
+    feeds.url as feed_url,
+    (
+        SELECT COUNT(*) FROM posts
+        WHERE posts.feed_id = feed_follows.feed_id
+            AND NOT EXISTS (
+                SELECT 1 FROM post_reads
+                WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+            )
+            AND (
+                feed_follows.match_filter IS NULL
+                OR posts.title ~* feed_follows.match_filter
+                OR posts.description ~* feed_follows.match_filter
+            )
+    ) AS unread_count
 FROM feed_follows
 INNER JOIN users ON feed_follows.user_id = users.id
 INNER JOIN feeds ON feed_follows.feed_id = feeds.id
-WHERE feed_follows.user_id = $1; 
+WHERE feed_follows.user_id = $1
+ORDER BY feed_follows.priority DESC, feed_name;
 
 -- name: DeleteFeedFollow :execresult
 DELETE FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2;
 
+
+-- name: SetFeedFollowFolder :execresult
+UPDATE feed_follows
+SET folder_id = $1, updated_at = $2
+WHERE user_id = $3 AND feed_id = $4;
+
+-- name: UpdateFeedFollowSettings :execresult
+UPDATE feed_follows
+SET display_name = $1, priority = $2, notify = $3, match_filter = $4, updated_at = $5
+WHERE user_id = $6 AND feed_id = $7;
+
+-- name: GetFollowerNamesForFeed :many
+SELECT users.name FROM feed_follows
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.feed_id = $1
+ORDER BY users.name;
@@ -12,7 +12,7 @@ RETURNING *;
 
 -- name: GetFeedsWithUsername :many
 SELECT feeds.*, users.name as username 
-FROM feeds INNER JOIN users
+FROM feeds LEFT JOIN users
 ON feeds.user_id = users.id;
 
 -- name: GetFeedByUrl :one
@@ -25,7 +25,45 @@ SET last_fetched_at = $1, updated_at = $2
 WHERE id = $3;
 
 -- name: GetNextFeedToFetch :one
-SELECT * FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
+SELECT feeds.* FROM feeds
+LEFT JOIN websub_subscriptions ON websub_subscriptions.feed_id = feeds.id
+WHERE websub_subscriptions.id IS NULL
+    OR websub_subscriptions.status != 'active'
+    OR websub_subscriptions.lease_expires_at < NOW()
+ORDER BY feeds.last_fetched_at NULLS FIRST
 LIMIT 1;
 
+
+-- name: UpdateFeedAuthSettings :exec
+UPDATE feeds
+SET auth_settings = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: MarkFeedRobotsBlocked :exec
+UPDATE feeds
+SET robots_blocked = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: UpdateFeedIgnoreRobots :exec
+UPDATE feeds
+SET ignore_robots = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: RenameFeed :exec
+UPDATE feeds
+SET name = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: UpdateFeedUrl :exec
+UPDATE feeds
+SET url = $1, last_fetched_at = NULL, robots_blocked = false, updated_at = $2
+WHERE id = $3;
+
+-- name: SetFeedOwner :exec
+UPDATE feeds
+SET user_id = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: DeleteFeed :exec
+DELETE FROM feeds
+WHERE id = $1;
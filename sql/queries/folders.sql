@@ -0,0 +1,29 @@
+-- name: CreateFolder :one
+INSERT INTO folders (id, created_at, updated_at, user_id, parent_id, name)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetFoldersForUser :many
+SELECT * FROM folders
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: GetFolderByName :one
+SELECT * FROM folders
+WHERE user_id = $1 AND name = $2;
+
+-- name: RenameFolder :exec
+UPDATE folders
+SET name = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: DeleteFolder :exec
+DELETE FROM folders
+WHERE id = $1;
@@ -0,0 +1,41 @@
+-- name: CreateMuteFilter :one
+INSERT INTO mute_filters (id, created_at, updated_at, user_id, kind, pattern)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetMuteFiltersForUser :many
+SELECT * FROM mute_filters
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: DeleteMuteFilter :execresult
+DELETE FROM mute_filters
+WHERE user_id = $1 AND id = $2;
+
+-- name: ValidateRegex :one
+SELECT ''::text ~* sqlc.arg(pattern)::text AS valid;
+
+-- name: GetPostsMatchingMuteFilter :many
+SELECT posts.*, COALESCE(feed_follows.display_name, feeds.name) AS feed_name
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND mute_filter_matches(
+        sqlc.arg(kind),
+        sqlc.arg(pattern),
+        posts.title,
+        posts.description,
+        posts.author,
+        posts.url,
+        posts.categories
+    )
+ORDER BY posts.published_at DESC
+LIMIT sqlc.arg(post_limit);
@@ -0,0 +1,33 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_reads (user_id, post_id, read_at)
+VALUES(
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkPostUnread :execresult
+DELETE FROM post_reads
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: MarkFeedPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT $1, posts.id, $2
+FROM posts
+WHERE posts.feed_id = $3 AND posts.published_at < $4
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkAllPostsRead :execrows
+INSERT INTO post_reads (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, $2
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = $1
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: IsPostRead :one
+SELECT EXISTS (
+    SELECT 1 FROM post_reads
+    WHERE user_id = $1 AND post_id = $2
+);
@@ -1,5 +1,5 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, author, categories, content)
 VALUES(
     $1,
     $2,
@@ -8,14 +8,160 @@ VALUES(
     $5,
     $6,
     $7,
-    $8
+    $8,
+    $9,
+    $10,
+    $11
 )
 RETURNING *;
 
 -- name: GetPostsForUser :many
-SELECT posts.* 
+SELECT
+    posts.*,
+    COALESCE(feed_follows.display_name, feeds.name) AS feed_name,
+    feed_follows.priority AS feed_priority,
+    EXISTS (
+        SELECT 1 FROM post_reads
+        WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+    ) AS is_read
 FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
 INNER JOIN feeds ON feeds.id = posts.feed_id
-WHERE feeds.user_id = $1
-ORDER BY posts.published_at DESC
-LIMIT $2;
\ No newline at end of file
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND (
+        NOT sqlc.arg(unread_only)::boolean
+        OR NOT EXISTS (
+            SELECT 1 FROM post_reads
+            WHERE post_reads.post_id = posts.id AND post_reads.user_id = feed_follows.user_id
+        )
+    )
+    AND (NOT sqlc.arg(since_follow)::boolean OR posts.published_at >= feed_follows.created_at)
+    AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+    AND (
+        sqlc.narg(folder_id)::uuid IS NULL
+        OR feed_follows.folder_id = sqlc.narg(folder_id)
+        OR feed_follows.folder_id IN (SELECT id FROM folders WHERE parent_id = sqlc.narg(folder_id))
+    )
+    AND (sqlc.narg(since)::timestamp IS NULL OR posts.published_at >= sqlc.narg(since))
+    AND (sqlc.narg(until)::timestamp IS NULL OR posts.published_at < sqlc.narg(until))
+    AND (sqlc.narg(author)::text IS NULL OR posts.author ILIKE '%' || sqlc.narg(author) || '%')
+    AND (
+        sqlc.narg(category)::text IS NULL
+        OR EXISTS (
+            SELECT 1 FROM unnest(posts.categories) AS category
+            WHERE lower(category) = lower(sqlc.narg(category))
+        )
+    )
+    AND (
+        feed_follows.match_filter IS NULL
+        OR posts.title ~* feed_follows.match_filter
+        OR posts.description ~* feed_follows.match_filter
+    )
+    AND (
+        sqlc.narg(after_time)::timestamp IS NULL
+        OR (
+            CASE WHEN sqlc.arg(sort_by_priority)::boolean THEN feed_follows.priority ELSE 0 END,
+            CASE WHEN sqlc.arg(sort_by_fetched)::boolean THEN posts.created_at ELSE posts.published_at END,
+            posts.id
+        ) < (sqlc.arg(after_priority)::integer, sqlc.narg(after_time), sqlc.narg(after_id)::uuid)
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mute_filters
+        WHERE mute_filters.user_id = feed_follows.user_id
+            AND mute_filter_matches(
+                mute_filters.kind,
+                mute_filters.pattern,
+                posts.title,
+                posts.description,
+                posts.author,
+                posts.url,
+                posts.categories
+            )
+    )
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by_priority)::boolean THEN feed_follows.priority ELSE 0 END DESC,
+    CASE WHEN sqlc.arg(sort_by_fetched)::boolean THEN posts.created_at ELSE posts.published_at END DESC,
+    posts.id DESC
+LIMIT sqlc.arg(post_limit);
+
+-- name: GetPostByUrl :one
+SELECT * FROM posts
+WHERE url = $1;
+
+-- name: GetPostsByIdPrefix :many
+SELECT * FROM posts
+WHERE id::text LIKE sqlc.arg(id_prefix)::text || '%'
+LIMIT 2;
+
+-- name: SearchPosts :many
+SELECT
+    posts.id,
+    posts.title,
+    posts.url,
+    posts.published_at,
+    feeds.name AS feed_name,
+    ts_rank(post_search_vector(posts.title, posts.description, posts.content), search_query) AS rank,
+    ts_headline(
+        'english',
+        posts.title || ' ' || posts.description || ' ' || posts.content,
+        search_query,
+        'StartSel=**, StopSel=**, MaxWords=35, MinWords=15, MaxFragments=2'
+    ) AS snippet
+FROM posts
+INNER JOIN feeds ON feeds.id = posts.feed_id
+CROSS JOIN websearch_to_tsquery('english', sqlc.arg(query_text)) AS search_query
+WHERE post_search_vector(posts.title, posts.description, posts.content) @@ search_query
+    AND (
+        NOT sqlc.arg(followed_only)::boolean
+        OR EXISTS (
+            SELECT 1 FROM feed_follows
+            WHERE feed_follows.feed_id = posts.feed_id AND feed_follows.user_id = sqlc.arg(user_id)
+        )
+    )
+    AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id))
+    AND NOT EXISTS (
+        SELECT 1 FROM mute_filters
+        WHERE mute_filters.user_id = sqlc.arg(user_id)
+            AND mute_filter_matches(
+                mute_filters.kind,
+                mute_filters.pattern,
+                posts.title,
+                posts.description,
+                posts.author,
+                posts.url,
+                posts.categories
+            )
+    )
+ORDER BY rank DESC, posts.published_at DESC
+LIMIT sqlc.arg(result_limit);
+
+-- name: GetPostsForQuery :many
+SELECT posts.*
+FROM posts
+INNER JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND post_search_vector(posts.title, posts.description, posts.content) @@ websearch_to_tsquery('english', sqlc.arg(query_text))
+    AND (
+        feed_follows.match_filter IS NULL
+        OR posts.title ~* feed_follows.match_filter
+        OR posts.description ~* feed_follows.match_filter
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mute_filters
+        WHERE mute_filters.user_id = feed_follows.user_id
+            AND mute_filter_matches(
+                mute_filters.kind,
+                mute_filters.pattern,
+                posts.title,
+                posts.description,
+                posts.author,
+                posts.url,
+                posts.categories
+            )
+    )
+ORDER BY posts.published_at DESC, posts.id DESC
+LIMIT sqlc.arg(post_limit);
+
+-- name: CountPostsForFeed :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1;
@@ -0,0 +1,44 @@
+-- name: SavePost :exec
+INSERT INTO saved_posts (user_id, post_id, kind, saved_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (user_id, post_id, kind) DO NOTHING;
+
+-- name: UnsavePost :execresult
+DELETE FROM saved_posts
+WHERE user_id = $1 AND post_id = $2 AND kind = $3;
+
+-- name: GetStarredPostsForUser :many
+SELECT posts.*, saved_posts.saved_at
+FROM saved_posts
+INNER JOIN posts ON posts.id = saved_posts.post_id
+WHERE saved_posts.user_id = $1 AND saved_posts.kind = 'star'
+ORDER BY saved_posts.saved_at DESC;
+
+-- name: GetReadLaterPostsForUser :many
+SELECT posts.*, saved_posts.saved_at
+FROM saved_posts
+INNER JOIN posts ON posts.id = saved_posts.post_id
+WHERE saved_posts.user_id = $1 AND saved_posts.kind = 'later'
+ORDER BY saved_posts.saved_at ASC;
+
+-- name: CountSavedPostsForFeed :one
+SELECT COUNT(*) FROM saved_posts
+INNER JOIN posts ON posts.id = saved_posts.post_id
+WHERE posts.feed_id = $1;
+
+-- name: GetSaverNamesForFeed :many
+SELECT DISTINCT users.name FROM saved_posts
+INNER JOIN posts ON posts.id = saved_posts.post_id
+INNER JOIN users ON users.id = saved_posts.user_id
+WHERE posts.feed_id = $1
+ORDER BY users.name;
+
+-- name: DeleteSavedPostsForFeed :exec
+DELETE FROM saved_posts
+USING posts
+WHERE posts.id = saved_posts.post_id AND posts.feed_id = $1;
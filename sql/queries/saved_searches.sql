@@ -0,0 +1,72 @@
+-- name: CreateSavedSearch :one
+INSERT INTO saved_searches (id, created_at, updated_at, user_id, name, query, notify_target)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+RETURNING *;
+
+-- name: GetSavedSearchesForUser :many
+SELECT * FROM saved_searches
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: GetSavedSearchByName :one
+SELECT * FROM saved_searches
+WHERE user_id = $1 AND name = $2;
+
+-- name: DeleteSavedSearch :execresult
+DELETE FROM saved_searches
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetSavedSearchMatches :many
+SELECT
+    saved_searches.id AS saved_search_id,
+    saved_searches.name AS saved_search_name,
+    saved_searches.notify_target,
+    users.name AS user_name,
+    users.role AS user_role,
+    posts.id AS post_id,
+    posts.title AS post_title,
+    posts.url AS post_url,
+    COALESCE(feed_follows.display_name, feeds.name) AS feed_name
+FROM saved_searches
+INNER JOIN users ON users.id = saved_searches.user_id
+INNER JOIN feed_follows ON feed_follows.user_id = saved_searches.user_id
+INNER JOIN posts ON posts.feed_id = feed_follows.feed_id
+INNER JOIN feeds ON feeds.id = posts.feed_id
+WHERE posts.id = ANY(sqlc.arg(post_ids)::uuid[])
+    AND post_search_vector(posts.title, posts.description, posts.content) @@ websearch_to_tsquery('english', saved_searches.query)
+    AND feed_follows.notify
+    AND (
+        feed_follows.match_filter IS NULL
+        OR posts.title ~* feed_follows.match_filter
+        OR posts.description ~* feed_follows.match_filter
+    )
+    AND NOT EXISTS (
+        SELECT 1 FROM mute_filters
+        WHERE mute_filters.user_id = saved_searches.user_id
+            AND mute_filter_matches(
+                mute_filters.kind,
+                mute_filters.pattern,
+                posts.title,
+                posts.description,
+                posts.author,
+                posts.url,
+                posts.categories
+            )
+    );
+
+-- name: RecordSavedSearchMatch :execrows
+INSERT INTO saved_search_matches (saved_search_id, post_id, matched_at)
+VALUES(
+    $1,
+    $2,
+    $3
+)
+ON CONFLICT (saved_search_id, post_id) DO NOTHING;
@@ -0,0 +1,33 @@
+-- name: CreateSession :one
+INSERT INTO sessions (id, created_at, expires_at, last_used_at, user_id, token_hash)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetUserForSession :one
+SELECT users.* FROM sessions
+INNER JOIN users ON users.id = sessions.user_id
+WHERE sessions.token_hash = $1 AND sessions.expires_at > $2;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_used_at = $1
+WHERE token_hash = $2;
+
+-- name: DeleteSession :execrows
+DELETE FROM sessions
+WHERE token_hash = $1;
+
+-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions
+WHERE user_id = $1 AND expires_at <= $2;
+
+-- name: DeleteSessionsForUser :execrows
+DELETE FROM sessions
+WHERE user_id = $1;
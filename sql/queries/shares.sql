@@ -0,0 +1,25 @@
+-- name: CreateShare :one
+INSERT INTO shares (id, created_at, post_id, from_user_id, to_user_id, note)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
+
+-- name: GetSharesForUser :many
+SELECT shares.id, shares.created_at, shares.note, users.name AS from_name,
+    posts.id AS post_id, posts.title, posts.url,
+    EXISTS(
+        SELECT 1 FROM post_reads
+        WHERE post_reads.user_id = shares.to_user_id AND post_reads.post_id = posts.id
+    ) AS is_read
+FROM shares
+INNER JOIN users ON users.id = shares.from_user_id
+INNER JOIN posts ON posts.id = shares.post_id
+WHERE shares.to_user_id = $1
+ORDER BY shares.created_at DESC
+LIMIT $2;
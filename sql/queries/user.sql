@@ -1,10 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, password_hash, role)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5,
+    $6
 )
 RETURNING *;
 
@@ -12,8 +14,53 @@ RETURNING *;
 SELECT * FROM users 
 WHERE name = $1;
 
--- name: ResetUsers :exec
-DELETE FROM users;
-
 -- name: GetUsers :many
 SELECT * FROM users;
+
+-- name: SetUserPassword :exec
+UPDATE users
+SET password_hash = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: SetUserRole :exec
+UPDATE users
+SET role = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: CountAdmins :one
+SELECT COUNT(*) FROM users
+WHERE role = 'admin';
+
+-- name: CountAdminsWithPassword :one
+SELECT COUNT(*) FROM users
+WHERE role = 'admin' AND password_hash IS NOT NULL;
+
+-- name: DeleteUser :exec
+DELETE FROM users
+WHERE id = $1;
+
+-- name: RenameUser :exec
+UPDATE users
+SET name = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: GetUserLastActivity :one
+SELECT GREATEST(
+    users.created_at,
+    (SELECT MAX(read_at) FROM post_reads WHERE post_reads.user_id = users.id),
+    (SELECT MAX(saved_at) FROM saved_posts WHERE saved_posts.user_id = users.id),
+    (SELECT MAX(feed_follows.updated_at) FROM feed_follows WHERE feed_follows.user_id = users.id),
+    (SELECT MAX(sessions.last_used_at) FROM sessions WHERE sessions.user_id = users.id),
+    (SELECT MAX(api_tokens.last_used_at) FROM api_tokens WHERE api_tokens.user_id = users.id)
+)::timestamp AS last_activity
+FROM users
+WHERE users.id = $1;
+
+-- name: SetUserFollowsPublic :exec
+UPDATE users
+SET follows_public = $1, updated_at = $2
+WHERE id = $3;
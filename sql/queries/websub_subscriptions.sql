@@ -0,0 +1,44 @@
+-- name: UpsertWebsubSubscription :one
+INSERT INTO websub_subscriptions (id, created_at, updated_at, feed_id, hub_url, topic_url, secret, status)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+ON CONFLICT (feed_id) DO UPDATE
+SET updated_at = EXCLUDED.updated_at,
+    hub_url = EXCLUDED.hub_url,
+    topic_url = EXCLUDED.topic_url,
+    status = EXCLUDED.status
+RETURNING *;
+
+-- name: GetWebsubSubscription :one
+SELECT * FROM websub_subscriptions
+WHERE id = $1;
+
+-- name: GetWebsubSubscriptionByFeed :one
+SELECT * FROM websub_subscriptions
+WHERE feed_id = $1;
+
+-- name: ActivateWebsubSubscription :exec
+UPDATE websub_subscriptions
+SET status = 'active', lease_expires_at = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: UpdateWebsubSubscriptionStatus :exec
+UPDATE websub_subscriptions
+SET status = $1, updated_at = $2
+WHERE id = $3;
+
+-- name: GetWebsubSubscriptionsToRenew :many
+SELECT * FROM websub_subscriptions
+WHERE status = 'active' AND lease_expires_at < $1;
+
+-- name: DeleteWebsubSubscription :exec
+DELETE FROM websub_subscriptions
+WHERE id = $1;
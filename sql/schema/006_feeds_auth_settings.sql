@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN auth_settings BYTEA;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN auth_settings;
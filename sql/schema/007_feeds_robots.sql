@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN robots_blocked BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN ignore_robots BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN robots_blocked,
+DROP COLUMN ignore_robots;
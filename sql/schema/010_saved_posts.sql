@@ -0,0 +1,13 @@
+-- +goose Up
+-- post_id deliberately does not cascade: deleting a saved post, e.g. when
+-- pruning old posts, fails until the user removes it from their saved posts.
+CREATE TABLE saved_posts(
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id),
+    kind TEXT NOT NULL,
+    saved_at TIMESTAMP NOT NULL,
+    PRIMARY KEY(user_id, post_id, kind)
+);
+
+-- +goose Down
+DROP TABLE saved_posts;
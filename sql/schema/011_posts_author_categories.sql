@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN author TEXT NOT NULL DEFAULT '',
+ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX posts_feed_id_published_at_idx ON posts(feed_id, published_at);
+CREATE INDEX posts_published_at_id_idx ON posts(published_at, id);
+CREATE INDEX posts_created_at_id_idx ON posts(created_at, id);
+
+-- +goose Down
+DROP INDEX posts_created_at_id_idx;
+DROP INDEX posts_published_at_id_idx;
+DROP INDEX posts_feed_id_published_at_idx;
+
+ALTER TABLE posts
+DROP COLUMN author,
+DROP COLUMN categories;
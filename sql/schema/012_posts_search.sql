@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN content TEXT NOT NULL DEFAULT '';
+
+-- Search posts through an expression index instead of a stored column, so
+-- that queries selecting posts.* don't fetch the tsvector
+-- +goose StatementBegin
+CREATE FUNCTION post_search_vector(
+    title TEXT,
+    description TEXT,
+    content TEXT
+) RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('english', title), 'A') ||
+        setweight(to_tsvector('english', description), 'B') ||
+        setweight(to_tsvector('english', content), 'C')
+$$ LANGUAGE SQL IMMUTABLE;
+-- +goose StatementEnd
+
+CREATE INDEX posts_search_vector_idx ON posts USING GIN (post_search_vector(title, description, content));
+
+-- +goose Down
+DROP INDEX posts_search_vector_idx;
+DROP FUNCTION post_search_vector;
+
+ALTER TABLE posts
+DROP COLUMN content;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE saved_searches(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    query TEXT NOT NULL,
+    notify_target TEXT NOT NULL,
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE saved_search_matches(
+    saved_search_id UUID NOT NULL REFERENCES saved_searches(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    matched_at TIMESTAMP NOT NULL,
+    PRIMARY KEY(saved_search_id, post_id)
+);
+
+-- +goose Down
+DROP TABLE saved_search_matches;
+DROP TABLE saved_searches;
@@ -0,0 +1,42 @@
+-- +goose Up
+CREATE TABLE mute_filters(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    pattern TEXT NOT NULL
+);
+
+-- Whether a mute filter hides a post. Shared by every query listing posts.
+-- +goose StatementBegin
+CREATE FUNCTION mute_filter_matches(
+    kind TEXT,
+    pattern TEXT,
+    title TEXT,
+    description TEXT,
+    author TEXT,
+    url TEXT,
+    categories TEXT[]
+) RETURNS BOOLEAN AS $$
+    SELECT CASE kind
+        WHEN 'keyword' THEN
+            position(lower(pattern) IN lower(title)) > 0
+            OR position(lower(pattern) IN lower(description)) > 0
+        WHEN 'regex' THEN
+            title ~* pattern OR description ~* pattern
+        WHEN 'author' THEN
+            lower(author) = lower(pattern)
+        WHEN 'domain' THEN
+            lower(substring(url FROM '^[A-Za-z][A-Za-z0-9+.-]*://([^/:?#]+)')) = lower(pattern)
+            OR lower(substring(url FROM '^[A-Za-z][A-Za-z0-9+.-]*://([^/:?#]+)')) LIKE '%.' || lower(pattern)
+        WHEN 'category' THEN
+            EXISTS (SELECT 1 FROM unnest(categories) AS category WHERE lower(category) = lower(pattern))
+        ELSE FALSE
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION mute_filter_matches;
+DROP TABLE mute_filters;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE feed_follows ADD COLUMN display_name TEXT;
+ALTER TABLE feed_follows ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE feed_follows ADD COLUMN notify BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE feed_follows ADD COLUMN match_filter TEXT;
+
+-- +goose Down
+ALTER TABLE feed_follows DROP COLUMN match_filter;
+ALTER TABLE feed_follows DROP COLUMN notify;
+ALTER TABLE feed_follows DROP COLUMN priority;
+ALTER TABLE feed_follows DROP COLUMN display_name;
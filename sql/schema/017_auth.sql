@@ -0,0 +1,17 @@
+-- +goose Up
+-- Users created before passwords existed set one on their next login
+ALTER TABLE users ADD COLUMN password_hash TEXT;
+
+CREATE TABLE sessions(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL,
+    UNIQUE(token_hash)
+);
+
+-- +goose Down
+DROP TABLE sessions;
+ALTER TABLE users DROP COLUMN password_hash;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE api_tokens(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    UNIQUE(token_hash),
+    UNIQUE(user_id, name)
+);
+
+-- +goose Down
+DROP TABLE api_tokens;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
+
+-- The first user becomes the admin of an existing database
+UPDATE users SET role = 'admin'
+WHERE id = (SELECT id FROM users ORDER BY created_at LIMIT 1);
+
+-- Posts go with their feed
+ALTER TABLE posts DROP CONSTRAINT fk_feed_id;
+ALTER TABLE posts ADD CONSTRAINT fk_feed_id FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE;
+
+-- +goose Down
+ALTER TABLE posts DROP CONSTRAINT fk_feed_id;
+ALTER TABLE posts ADD CONSTRAINT fk_feed_id FOREIGN KEY (feed_id) REFERENCES feeds(id);
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Others may follow a feed, so it stays when the user who added it is deleted
+ALTER TABLE feeds DROP CONSTRAINT fk_user_id;
+ALTER TABLE feeds ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE feeds DROP CONSTRAINT fk_user_id;
+ALTER TABLE feeds ADD CONSTRAINT fk_user_id FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE shares(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    from_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    to_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    note TEXT,
+    UNIQUE(post_id, from_user_id, to_user_id)
+);
+
+-- Whether others may copy the user's follow list
+ALTER TABLE users ADD COLUMN follows_public BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN follows_public;
+DROP TABLE shares;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Users who get their unread posts by email
+CREATE TABLE digest_subscriptions(
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    email TEXT NOT NULL
+);
+
+-- Digests that were sent and the posts they contained, so posts are only mailed once
+CREATE TABLE digests(
+    id UUID PRIMARY KEY,
+    sent_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    post_count INTEGER NOT NULL
+);
+
+CREATE TABLE digest_posts(
+    digest_id UUID NOT NULL REFERENCES digests(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    PRIMARY KEY(digest_id, post_id)
+);
+
+-- +goose Down
+DROP TABLE digest_posts;
+DROP TABLE digests;
+DROP TABLE digest_subscriptions;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- One-time codes gator agg prints while no admin has a password. Setting an
+-- admin's password without logging in needs one, proving access to the
+-- operator's console rather than just to the database.
+CREATE TABLE bootstrap_codes(
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    code_hash TEXT NOT NULL,
+    UNIQUE(code_hash)
+);
+
+-- +goose Down
+DROP TABLE bootstrap_codes;